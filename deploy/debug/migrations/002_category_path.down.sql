@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_categories_root;
+DROP INDEX IF EXISTS idx_categories_path;
+ALTER TABLE categories DROP COLUMN IF EXISTS root_category_id;
+ALTER TABLE categories DROP COLUMN IF EXISTS path;
@@ -0,0 +1,21 @@
+-- Materialized category path: ids from the root down to the node joined with '/'
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS path TEXT NOT NULL DEFAULT '';
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS root_category_id UUID REFERENCES categories(id) ON DELETE SET NULL;
+
+WITH RECURSIVE cat_path AS (
+    SELECT id, id AS root_id, 0 AS lvl, id::text AS p
+    FROM categories
+    WHERE parent_id IS NULL
+    UNION ALL
+    SELECT c.id, cp.root_id, cp.lvl + 1, cp.p || '/' || c.id::text
+    FROM categories c
+    JOIN cat_path cp ON c.parent_id = cp.id
+)
+UPDATE categories c
+SET level = cp.lvl, path = cp.p, root_category_id = cp.root_id
+FROM cat_path cp
+WHERE cp.id = c.id;
+
+CREATE INDEX IF NOT EXISTS idx_categories_path ON categories(path text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_categories_root ON categories(root_category_id);
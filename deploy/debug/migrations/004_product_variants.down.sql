@@ -0,0 +1,14 @@
+-- Order lines of variants are order history: refuse to roll back rather than delete them.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM order_items WHERE variant_id IS NOT NULL) THEN
+        RAISE EXCEPTION 'order_items has variant lines; remove or migrate them before rolling back product variants';
+    END IF;
+END
+$$;
+
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS order_items_order_product_variant_key;
+ALTER TABLE order_items ADD CONSTRAINT order_items_order_id_product_id_key UNIQUE (order_id, product_id);
+ALTER TABLE order_items DROP COLUMN IF EXISTS variant_id;
+DROP TABLE IF EXISTS product_variants;
+DROP TABLE IF EXISTS product_options;
@@ -0,0 +1,21 @@
+-- Typed product attributes: definitions, attachment to categories and per-product values in JSONB
+
+CREATE TABLE IF NOT EXISTS attributes (
+    id UUID PRIMARY KEY,
+    code TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('string', 'number', 'boolean', 'enum')),
+    options TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS category_attributes (
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    attribute_id UUID NOT NULL REFERENCES attributes(id) ON DELETE CASCADE,
+    position INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (category_id, attribute_id)
+);
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS idx_products_attributes ON products USING GIN (attributes jsonb_path_ops);
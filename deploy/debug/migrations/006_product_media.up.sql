@@ -0,0 +1,19 @@
+-- Product media gallery: files live in the configured storage, rows keep keys, order and the primary flag
+
+CREATE TABLE IF NOT EXISTS product_media (
+    id UUID PRIMARY KEY,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    storage_key TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    thumbnails JSONB NOT NULL DEFAULT '[]',
+    position INT NOT NULL DEFAULT 0,
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_media_product ON product_media(product_id, position);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_product_media_primary ON product_media(product_id) WHERE is_primary;
@@ -0,0 +1,19 @@
+-- Price history: applied rows form non-overlapping [effective_from, effective_to) intervals,
+-- rows with applied_at IS NULL are scheduled changes waiting for the price scheduler
+
+CREATE TABLE IF NOT EXISTS product_prices (
+    id UUID PRIMARY KEY,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    price NUMERIC(14,2) NOT NULL CHECK (price >= 0),
+    effective_from TIMESTAMPTZ NOT NULL,
+    effective_to TIMESTAMPTZ,
+    applied_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_prices_product ON product_prices(product_id, effective_from DESC);
+CREATE INDEX IF NOT EXISTS idx_product_prices_due ON product_prices(effective_from) WHERE applied_at IS NULL;
+
+INSERT INTO product_prices (id, product_id, price, effective_from, effective_to, applied_at, created_at)
+SELECT gen_random_uuid(), id, price, created_at, NULL, created_at, NOW()
+FROM products;
@@ -0,0 +1,18 @@
+-- Lines of one product bought at different prices are order history: refuse to roll back rather
+-- than merge them into one line.
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM order_items
+        GROUP BY order_id, product_id, variant_id
+        HAVING COUNT(*) > 1
+    ) THEN
+        RAISE EXCEPTION 'order_items has several price lines per product; remove or migrate them before rolling back order item prices';
+    END IF;
+END
+$$;
+
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS order_items_order_product_variant_price_key;
+ALTER TABLE order_items ADD CONSTRAINT order_items_order_product_variant_key UNIQUE NULLS NOT DISTINCT (order_id, product_id, variant_id);
+ALTER TABLE order_items DROP COLUMN IF EXISTS product_name;
+ALTER TABLE order_items DROP COLUMN IF EXISTS unit_price;
@@ -0,0 +1,18 @@
+-- Snapshot of the charged unit price and product name on order items.
+-- One line per (product, variant, unit price): units added at another price get their own line.
+
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS unit_price NUMERIC(14,2);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS product_name TEXT;
+
+UPDATE order_items oi
+SET unit_price = CASE WHEN oi.quantity > 0 THEN ROUND(oi.sub_total / oi.quantity, 2) ELSE 0 END,
+    product_name = p.name
+FROM products p
+WHERE p.id = oi.product_id;
+
+ALTER TABLE order_items ALTER COLUMN unit_price SET NOT NULL;
+ALTER TABLE order_items ALTER COLUMN product_name SET NOT NULL;
+
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS order_items_order_product_variant_key;
+ALTER TABLE order_items ADD CONSTRAINT order_items_order_product_variant_price_key
+    UNIQUE NULLS NOT DISTINCT (order_id, product_id, variant_id, unit_price);
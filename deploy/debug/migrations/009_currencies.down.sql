@@ -0,0 +1,5 @@
+DROP FUNCTION IF EXISTS exchange_rate(TEXT, TEXT, TIMESTAMPTZ);
+DROP TABLE IF EXISTS exchange_rates;
+ALTER TABLE orders DROP COLUMN IF EXISTS currency;
+ALTER TABLE product_prices DROP COLUMN IF EXISTS currency;
+ALTER TABLE products DROP COLUMN IF EXISTS currency;
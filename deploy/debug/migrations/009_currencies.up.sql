@@ -0,0 +1,42 @@
+-- Currency codes on prices and orders, exchange rates and a lookup function used for conversions
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'RUB'
+    CONSTRAINT products_currency_check CHECK (currency IN ('RUB', 'KZT', 'BYN'));
+ALTER TABLE product_prices ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'RUB'
+    CONSTRAINT product_prices_currency_check CHECK (currency IN ('RUB', 'KZT', 'BYN'));
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'RUB'
+    CONSTRAINT orders_currency_check CHECK (currency IN ('RUB', 'KZT', 'BYN'));
+
+-- 1 base_currency = rate quote_currency starting at effective_at
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id UUID PRIMARY KEY,
+    base_currency TEXT NOT NULL CHECK (base_currency IN ('RUB', 'KZT', 'BYN')),
+    quote_currency TEXT NOT NULL CHECK (quote_currency IN ('RUB', 'KZT', 'BYN')),
+    rate NUMERIC(20,10) NOT NULL CHECK (rate > 0),
+    effective_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    CHECK (base_currency <> quote_currency),
+    UNIQUE (base_currency, quote_currency, effective_at)
+);
+
+-- exchange_rate returns how many to_currency units one from_currency unit is worth at the given time.
+-- A pair is looked up in both directions; the latest rate effective at that time wins, and when the
+-- time predates all rates the earliest one is used. NULL means the pair has no rates at all.
+CREATE OR REPLACE FUNCTION exchange_rate(from_currency TEXT, to_currency TEXT, at TIMESTAMPTZ)
+RETURNS NUMERIC
+LANGUAGE sql STABLE AS $$
+    SELECT CASE WHEN from_currency = to_currency THEN 1 ELSE (
+        SELECT r.rate
+        FROM (
+            SELECT rate, effective_at FROM exchange_rates
+            WHERE base_currency = from_currency AND quote_currency = to_currency
+            UNION ALL
+            SELECT 1 / rate, effective_at FROM exchange_rates
+            WHERE base_currency = to_currency AND quote_currency = from_currency
+        ) r
+        ORDER BY (r.effective_at <= at) DESC,
+                 CASE WHEN r.effective_at <= at THEN r.effective_at END DESC,
+                 r.effective_at ASC
+        LIMIT 1
+    ) END
+$$;
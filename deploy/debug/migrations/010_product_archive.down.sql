@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_products_archived;
+ALTER TABLE products DROP COLUMN IF EXISTS archived_at;
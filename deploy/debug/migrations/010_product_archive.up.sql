@@ -0,0 +1,5 @@
+-- Archived products are hidden from listings and cannot be ordered, but stay referenced by order history
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_products_archived ON products(archived_at) WHERE archived_at IS NOT NULL;
@@ -0,0 +1,28 @@
+-- Product keys used to match imported rows, and import jobs with their per-row reports
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sku TEXT CONSTRAINT products_sku_key UNIQUE;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS external_id TEXT CONSTRAINT products_external_id_key UNIQUE;
+
+-- payload keeps the uploaded file until the job finishes; updated_at is bumped on progress
+-- so that a running job abandoned by a stopped instance can be picked up again
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id UUID PRIMARY KEY,
+    filename TEXT NOT NULL,
+    format TEXT NOT NULL CHECK (format IN ('csv', 'xlsx')),
+    dry_run BOOLEAN NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    payload BYTEA,
+    total_rows INT NOT NULL DEFAULT 0,
+    processed_rows INT NOT NULL DEFAULT 0,
+    created_count INT NOT NULL DEFAULT 0,
+    updated_count INT NOT NULL DEFAULT 0,
+    error_count INT NOT NULL DEFAULT 0,
+    report JSONB NOT NULL DEFAULT '[]',
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_jobs_active ON import_jobs(created_at) WHERE status IN ('pending', 'running');
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS category_slug_redirects;
+DROP TABLE IF EXISTS product_slug_redirects;
+ALTER TABLE products DROP COLUMN IF EXISTS slug;
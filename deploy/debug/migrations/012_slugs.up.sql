@@ -0,0 +1,47 @@
+-- Products get a unique slug for storefront URLs; old slugs of renamed products and categories redirect to the current ones
+
+-- Same transliteration as the service uses, only needed to backfill existing products
+CREATE FUNCTION pg_temp.slugify(s TEXT) RETURNS TEXT LANGUAGE SQL IMMUTABLE AS $$
+    SELECT trim(BOTH '-' FROM left(trim(BOTH '-' FROM regexp_replace(
+        translate(
+            replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(lower(s),
+                'щ', 'shch'), 'ж', 'zh'), 'х', 'kh'), 'ц', 'ts'), 'ч', 'ch'), 'ш', 'sh'),
+                'ю', 'yu'), 'я', 'ya'), 'ъ', ''), 'ь', ''), 'ё', 'e'),
+            'абвгдезийклмнопрстуфыэәғқңөұүһіў',
+            'abvgdeziyklmnoprstufyeagknouuhiu'),
+        '[^a-z0-9]+', '-', 'g')), 80))
+$$;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS slug TEXT;
+
+-- Products with the same name are told apart by the beginning of their id
+WITH base AS (
+    SELECT id, COALESCE(NULLIF(pg_temp.slugify(name), ''), 'product') AS slug,
+           row_number() OVER (PARTITION BY COALESCE(NULLIF(pg_temp.slugify(name), ''), 'product') ORDER BY created_at, id) AS n
+    FROM products
+)
+UPDATE products p
+SET slug = CASE WHEN b.n = 1 THEN b.slug ELSE b.slug || '-' || left(p.id::text, 8) END
+FROM base b
+WHERE b.id = p.id AND p.slug IS NULL;
+
+ALTER TABLE products ALTER COLUMN slug SET NOT NULL;
+ALTER TABLE products ADD CONSTRAINT products_slug_key UNIQUE (slug);
+
+DROP FUNCTION pg_temp.slugify(TEXT);
+
+CREATE TABLE IF NOT EXISTS product_slug_redirects (
+    slug TEXT PRIMARY KEY,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_slug_redirects_product ON product_slug_redirects(product_id);
+
+CREATE TABLE IF NOT EXISTS category_slug_redirects (
+    slug TEXT PRIMARY KEY,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_slug_redirects_category ON category_slug_redirects(category_id);
@@ -0,0 +1,15 @@
+-- "Frequently bought together": product pairs from order history, recomputed by the recommendations worker.
+-- orders_together counts orders with both products; confidence = P(related | product); lift = confidence / P(related)
+
+CREATE TABLE IF NOT EXISTS product_associations (
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    related_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    orders_together INT NOT NULL,
+    support NUMERIC(10,6) NOT NULL,
+    confidence NUMERIC(10,6) NOT NULL,
+    lift NUMERIC(14,6) NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (product_id, related_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_associations_rank ON product_associations(product_id, lift DESC, confidence DESC);
@@ -0,0 +1,19 @@
+-- Stock held by orders that are not paid yet. Available stock is on-hand quantity minus active holds
+-- that have not expired; a hold becomes a real decrement (committed) when the order is paid,
+-- is released when the order is cancelled and expires after RESERVATION_TTL otherwise.
+
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id UUID REFERENCES product_variants(id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    status TEXT NOT NULL CHECK (status IN ('active', 'committed', 'released', 'expired')),
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_order ON stock_reservations(order_id);
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_active ON stock_reservations(product_id, variant_id) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_expires ON stock_reservations(expires_at) WHERE status = 'active';
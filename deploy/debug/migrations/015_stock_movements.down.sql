@@ -0,0 +1,6 @@
+UPDATE stock_reservations SET status = 'committed' WHERE status = 'returned';
+ALTER TABLE stock_reservations DROP CONSTRAINT IF EXISTS stock_reservations_status_check;
+ALTER TABLE stock_reservations ADD CONSTRAINT stock_reservations_status_check
+    CHECK (status IN ('active', 'committed', 'released', 'expired'));
+
+DROP TABLE IF EXISTS stock_movements;
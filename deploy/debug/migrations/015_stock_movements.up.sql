@@ -0,0 +1,30 @@
+-- Stock ledger: every change of products.quantity / product_variants.quantity is recorded as a movement,
+-- so the cached quantity always equals the sum of deltas and can be reconciled against it.
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id UUID PRIMARY KEY,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id UUID REFERENCES product_variants(id) ON DELETE CASCADE,
+    delta INT NOT NULL CHECK (delta <> 0),
+    reason TEXT NOT NULL CHECK (reason IN ('sale', 'return', 'adjustment', 'receipt')),
+    reference_id UUID,
+    actor TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product ON stock_movements(product_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_variant ON stock_movements(variant_id) WHERE variant_id IS NOT NULL;
+
+-- Opening balances: current stock becomes the first adjustment of each product and variant.
+INSERT INTO stock_movements (id, product_id, variant_id, delta, reason, actor, created_at)
+SELECT gen_random_uuid(), id, NULL, quantity, 'adjustment', 'migration', now()
+FROM products WHERE quantity <> 0;
+
+INSERT INTO stock_movements (id, product_id, variant_id, delta, reason, actor, created_at)
+SELECT gen_random_uuid(), product_id, id, quantity, 'adjustment', 'migration', now()
+FROM product_variants WHERE quantity <> 0;
+
+-- Stock of a paid order that is cancelled or deleted goes back to the warehouse.
+ALTER TABLE stock_reservations DROP CONSTRAINT IF EXISTS stock_reservations_status_check;
+ALTER TABLE stock_reservations ADD CONSTRAINT stock_reservations_status_check
+    CHECK (status IN ('active', 'committed', 'released', 'expired', 'returned'));
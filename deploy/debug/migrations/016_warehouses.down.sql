@@ -0,0 +1,32 @@
+-- Lines split by warehouse are order history: refuse to roll back rather than merge them.
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM order_items
+        GROUP BY order_id, product_id, variant_id, unit_price
+        HAVING COUNT(*) > 1
+    ) THEN
+        RAISE EXCEPTION 'order_items has lines split by warehouse; remove or migrate them before rolling back warehouses';
+    END IF;
+END
+$$;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_longitude;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_latitude;
+
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS order_items_order_product_variant_price_key;
+ALTER TABLE order_items DROP COLUMN IF EXISTS warehouse_id;
+ALTER TABLE order_items ADD CONSTRAINT order_items_order_product_variant_price_key
+    UNIQUE NULLS NOT DISTINCT (order_id, product_id, variant_id, unit_price);
+
+DELETE FROM stock_movements WHERE reason = 'transfer';
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_reason_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_reason_check
+    CHECK (reason IN ('sale', 'return', 'adjustment', 'receipt'));
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS warehouse_id;
+ALTER TABLE stock_reservations DROP COLUMN IF EXISTS warehouse_id;
+
+DROP TABLE IF EXISTS warehouse_transfer_lines;
+DROP TABLE IF EXISTS warehouse_transfers;
+DROP TABLE IF EXISTS warehouse_stock;
+DROP TABLE IF EXISTS warehouses;
@@ -0,0 +1,87 @@
+-- Warehouses with their own stock levels. products.quantity and product_variants.quantity stay the
+-- cached totals over all warehouses; holds, sales and ledger movements are bound to a warehouse.
+
+CREATE TABLE IF NOT EXISTS warehouses (
+    id UUID PRIMARY KEY,
+    code TEXT NOT NULL CONSTRAINT warehouses_code_key UNIQUE,
+    name TEXT NOT NULL,
+    -- allocation order of the priority strategy, lower first
+    priority INT NOT NULL DEFAULT 100,
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    -- stock changes without a warehouse (product quantity edits, import) go to the default one
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warehouses_default ON warehouses(is_default) WHERE is_default;
+
+CREATE TABLE IF NOT EXISTS warehouse_stock (
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id UUID REFERENCES product_variants(id) ON DELETE CASCADE,
+    quantity INT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT warehouse_stock_key UNIQUE NULLS NOT DISTINCT (warehouse_id, product_id, variant_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_warehouse_stock_product ON warehouse_stock(product_id, variant_id);
+
+CREATE TABLE IF NOT EXISTS warehouse_transfers (
+    id UUID PRIMARY KEY,
+    from_warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+    to_warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+    note TEXT NOT NULL DEFAULT '',
+    actor TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    CHECK (from_warehouse_id <> to_warehouse_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_warehouse_transfers_created ON warehouse_transfers(created_at DESC, id DESC);
+
+CREATE TABLE IF NOT EXISTS warehouse_transfer_lines (
+    id UUID PRIMARY KEY,
+    transfer_id UUID NOT NULL REFERENCES warehouse_transfers(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id UUID REFERENCES product_variants(id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_warehouse_transfer_lines_transfer ON warehouse_transfer_lines(transfer_id);
+
+-- Existing stock is placed in the default warehouse.
+INSERT INTO warehouses (id, code, name, priority, is_default, created_at, updated_at)
+SELECT gen_random_uuid(), 'main', 'Основной склад', 0, TRUE, now(), now()
+WHERE NOT EXISTS (SELECT 1 FROM warehouses WHERE is_default);
+
+INSERT INTO warehouse_stock (warehouse_id, product_id, variant_id, quantity, updated_at)
+SELECT w.id, p.id, NULL, p.quantity, now() FROM products p, warehouses w WHERE w.is_default AND p.quantity <> 0
+UNION ALL
+SELECT w.id, v.product_id, v.id, v.quantity, now() FROM product_variants v, warehouses w WHERE w.is_default AND v.quantity <> 0
+ON CONFLICT ON CONSTRAINT warehouse_stock_key DO NOTHING;
+
+ALTER TABLE stock_reservations ADD COLUMN IF NOT EXISTS warehouse_id UUID REFERENCES warehouses(id);
+UPDATE stock_reservations SET warehouse_id = (SELECT id FROM warehouses WHERE is_default) WHERE warehouse_id IS NULL;
+ALTER TABLE stock_reservations ALTER COLUMN warehouse_id SET NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_warehouse ON stock_reservations(warehouse_id, product_id, variant_id) WHERE status = 'active';
+
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS warehouse_id UUID REFERENCES warehouses(id);
+UPDATE stock_movements SET warehouse_id = (SELECT id FROM warehouses WHERE is_default) WHERE warehouse_id IS NULL;
+ALTER TABLE stock_movements ALTER COLUMN warehouse_id SET NOT NULL;
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_reason_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_reason_check
+    CHECK (reason IN ('sale', 'return', 'adjustment', 'receipt', 'transfer'));
+
+-- Items added before warehouses existed were taken from the single (now default) stock.
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS warehouse_id UUID REFERENCES warehouses(id);
+UPDATE order_items SET warehouse_id = (SELECT id FROM warehouses WHERE is_default) WHERE warehouse_id IS NULL;
+-- Units of one product at one price taken from different warehouses are separate lines.
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS order_items_order_product_variant_price_key;
+ALTER TABLE order_items ADD CONSTRAINT order_items_order_product_variant_price_key
+    UNIQUE NULLS NOT DISTINCT (order_id, product_id, variant_id, unit_price, warehouse_id);
+
+-- Delivery point used by the nearest allocation strategy.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_latitude DOUBLE PRECISION;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_longitude DOUBLE PRECISION;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS stock_alerts;
+ALTER TABLE categories DROP COLUMN IF EXISTS reorder_point;
+ALTER TABLE products DROP COLUMN IF EXISTS reorder_point;
@@ -0,0 +1,21 @@
+-- Reorder points: a product is low on stock when its available quantity (products and variants,
+-- minus active holds) is at or below the point. A product without its own point takes the point of
+-- its nearest category (or ancestor) that has one, the highest over all its categories.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS reorder_point INT CHECK (reorder_point >= 0);
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS reorder_point INT CHECK (reorder_point >= 0);
+
+-- Outbox of low-stock alerts: rows are written when an order takes stock below the reorder point
+-- and delivered to the notifier by a background job.
+CREATE TABLE IF NOT EXISTS stock_alerts (
+    id UUID PRIMARY KEY,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    reorder_point INT NOT NULL,
+    available INT NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL,
+    sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_alerts_pending ON stock_alerts(created_at) WHERE sent_at IS NULL;
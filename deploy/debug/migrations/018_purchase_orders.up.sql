@@ -0,0 +1,66 @@
+-- Suppliers and purchase orders. Receiving a purchase order increments stock of its warehouse
+-- through the stock ledger (reason 'receipt', reference = receipt id); quantities ordered but not
+-- yet received on open orders are shown as incoming stock.
+
+CREATE TABLE IF NOT EXISTS suppliers (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    phone TEXT NOT NULL DEFAULT '',
+    address TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS purchase_orders (
+    id UUID PRIMARY KEY,
+    supplier_id UUID NOT NULL REFERENCES suppliers(id),
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+    status TEXT NOT NULL CHECK (status IN ('open', 'partially_received', 'received', 'cancelled')),
+    currency TEXT NOT NULL CHECK (currency IN ('RUB', 'KZT', 'BYN')),
+    expected_at TIMESTAMPTZ,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_created ON purchase_orders(created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_supplier ON purchase_orders(supplier_id);
+
+CREATE TABLE IF NOT EXISTS purchase_order_lines (
+    id UUID PRIMARY KEY,
+    purchase_order_id UUID NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id),
+    variant_id UUID REFERENCES product_variants(id) ON DELETE RESTRICT,
+    quantity_ordered INT NOT NULL CHECK (quantity_ordered > 0),
+    quantity_received INT NOT NULL DEFAULT 0 CHECK (quantity_received >= 0 AND quantity_received <= quantity_ordered),
+    unit_cost NUMERIC(12, 2) NOT NULL CHECK (unit_cost >= 0),
+    position INT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_order_lines_order ON purchase_order_lines(purchase_order_id, position);
+CREATE INDEX IF NOT EXISTS idx_purchase_order_lines_product ON purchase_order_lines(product_id, variant_id);
+
+-- A receipt is one delivery against a purchase order. extra_cost (freight, duties) is spread over
+-- its lines by value into the landed unit cost.
+CREATE TABLE IF NOT EXISTS purchase_receipts (
+    id UUID PRIMARY KEY,
+    purchase_order_id UUID NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    extra_cost NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (extra_cost >= 0),
+    note TEXT NOT NULL DEFAULT '',
+    actor TEXT NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_receipts_order ON purchase_receipts(purchase_order_id, received_at);
+
+CREATE TABLE IF NOT EXISTS purchase_receipt_lines (
+    id UUID PRIMARY KEY,
+    receipt_id UUID NOT NULL REFERENCES purchase_receipts(id) ON DELETE CASCADE,
+    line_id UUID NOT NULL REFERENCES purchase_order_lines(id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    unit_cost NUMERIC(12, 2) NOT NULL,
+    landed_unit_cost NUMERIC(14, 4) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_receipt_lines_receipt ON purchase_receipt_lines(receipt_id);
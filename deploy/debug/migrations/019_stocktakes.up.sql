@@ -0,0 +1,30 @@
+-- Stocktakes: physical counts of a warehouse. Counted quantities are uploaded in batches while the
+-- warehouse keeps selling; each count remembers when it was taken, and on commit it is compared with
+-- the stock the ledger had at that moment, so movements after the count are kept.
+
+CREATE TABLE IF NOT EXISTS stocktakes (
+    id UUID PRIMARY KEY,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+    status TEXT NOT NULL CHECK (status IN ('open', 'committed', 'cancelled')),
+    note TEXT NOT NULL DEFAULT '',
+    actor TEXT NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL,
+    closed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_stocktakes_started ON stocktakes(started_at DESC, id DESC);
+-- one count at a time per warehouse
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stocktakes_open ON stocktakes(warehouse_id) WHERE status = 'open';
+
+CREATE TABLE IF NOT EXISTS stocktake_counts (
+    stocktake_id UUID NOT NULL REFERENCES stocktakes(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id UUID REFERENCES product_variants(id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity >= 0),
+    counted_at TIMESTAMPTZ NOT NULL,
+    actor TEXT NOT NULL,
+    -- filled on commit: ledger stock at counted_at and the adjustment applied
+    expected INT,
+    adjustment INT,
+    CONSTRAINT stocktake_counts_key UNIQUE NULLS NOT DISTINCT (stocktake_id, product_id, variant_id)
+);
@@ -0,0 +1 @@
+ALTER TABLE stock_alerts DROP COLUMN IF EXISTS claimed_at;
@@ -0,0 +1,3 @@
+-- Alerts are claimed by a dispatcher before delivery, so the notifier is called without holding
+-- row locks; a claim older than the dispatcher's claim timeout is taken over by the next run.
+ALTER TABLE stock_alerts ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMPTZ;
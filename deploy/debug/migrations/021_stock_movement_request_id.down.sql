@@ -0,0 +1 @@
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS request_id;
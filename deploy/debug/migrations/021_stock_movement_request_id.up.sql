@@ -0,0 +1,3 @@
+-- X-Actor is not authenticated, so movements also keep the ID of the request that made them
+-- to match them with access logs; background jobs leave it empty.
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS request_id TEXT NOT NULL DEFAULT '';
@@ -1,11 +1,11 @@
 -- Seed data for local debugging
 
 -- Categories
-INSERT INTO categories (id, name, slug, parent_id, level, is_active, sort_order, created_at, updated_at) VALUES
-    ('11111111-1111-1111-1111-111111111111', 'Electronics', 'electronics', NULL, 0, TRUE, 1, NOW(), NOW()),
-    ('11111111-1111-1111-1111-111111111112', 'Phones', 'phones', '11111111-1111-1111-1111-111111111111', 1, TRUE, 1, NOW(), NOW()),
-    ('11111111-1111-1111-1111-111111111113', 'Laptops', 'laptops', '11111111-1111-1111-1111-111111111111', 1, TRUE, 2, NOW(), NOW()),
-    ('11111111-1111-1111-1111-111111111114', 'Accessories', 'accessories', '11111111-1111-1111-1111-111111111111', 1, TRUE, 3, NOW(), NOW());
+INSERT INTO categories (id, name, slug, parent_id, level, path, root_category_id, is_active, sort_order, created_at, updated_at) VALUES
+    ('11111111-1111-1111-1111-111111111111', 'Electronics', 'electronics', NULL, 0, '11111111-1111-1111-1111-111111111111', '11111111-1111-1111-1111-111111111111', TRUE, 1, NOW(), NOW()),
+    ('11111111-1111-1111-1111-111111111112', 'Phones', 'phones', '11111111-1111-1111-1111-111111111111', 1, '11111111-1111-1111-1111-111111111111/11111111-1111-1111-1111-111111111112', '11111111-1111-1111-1111-111111111111', TRUE, 1, NOW(), NOW()),
+    ('11111111-1111-1111-1111-111111111113', 'Laptops', 'laptops', '11111111-1111-1111-1111-111111111111', 1, '11111111-1111-1111-1111-111111111111/11111111-1111-1111-1111-111111111113', '11111111-1111-1111-1111-111111111111', TRUE, 2, NOW(), NOW()),
+    ('11111111-1111-1111-1111-111111111114', 'Accessories', 'accessories', '11111111-1111-1111-1111-111111111111', 1, '11111111-1111-1111-1111-111111111111/11111111-1111-1111-1111-111111111114', '11111111-1111-1111-1111-111111111111', TRUE, 3, NOW(), NOW());
 
 -- Customers
 INSERT INTO customers (id, name, email, phone, address, created_at, updated_at) VALUES
@@ -13,11 +13,37 @@ INSERT INTO customers (id, name, email, phone, address, created_at, updated_at)
     ('22222222-2222-2222-2222-222222222222', 'Bob', 'bob@example.com', '+10000000002', '2 Side St', NOW(), NOW());
 
 -- Products
-INSERT INTO products (id, name, price, quantity, created_at, updated_at) VALUES
-    ('33333333-3333-3333-3333-333333333331', 'iPhone', 999.00, 50, NOW(), NOW()),
-    ('33333333-3333-3333-3333-333333333332', 'Android Phone', 499.00, 80, NOW(), NOW()),
-    ('33333333-3333-3333-3333-333333333333', 'Laptop Pro', 1499.00, 30, NOW(), NOW()),
-    ('33333333-3333-3333-3333-333333333334', 'USB-C Cable', 19.99, 200, NOW(), NOW());
+INSERT INTO products (id, slug, name, price, quantity, created_at, updated_at) VALUES
+    ('33333333-3333-3333-3333-333333333331', 'iphone', 'iPhone', 999.00, 50, NOW(), NOW()),
+    ('33333333-3333-3333-3333-333333333332', 'android-phone', 'Android Phone', 499.00, 80, NOW(), NOW()),
+    ('33333333-3333-3333-3333-333333333333', 'laptop-pro', 'Laptop Pro', 1499.00, 30, NOW(), NOW()),
+    ('33333333-3333-3333-3333-333333333334', 'usb-c-cable', 'USB-C Cable', 19.99, 200, NOW(), NOW());
+
+-- Price history starts with the seeded prices
+INSERT INTO product_prices (id, product_id, price, currency, effective_from, effective_to, applied_at, created_at)
+SELECT gen_random_uuid(), id, price, currency, created_at, NULL, created_at, NOW()
+FROM products;
+
+-- Warehouses: the default one is created by migrations and holds the seeded stock
+INSERT INTO warehouses (id, code, name, priority, latitude, longitude, is_default, active, created_at, updated_at) VALUES
+    ('77777777-7777-7777-7777-777777777772', 'spb', 'Склад Санкт-Петербург', 10, 59.9386, 30.3141, FALSE, TRUE, NOW(), NOW()),
+    ('77777777-7777-7777-7777-777777777773', 'ekb', 'Склад Екатеринбург', 20, 56.8389, 60.6057, FALSE, TRUE, NOW(), NOW());
+
+INSERT INTO warehouse_stock (warehouse_id, product_id, variant_id, quantity, updated_at)
+SELECT w.id, p.id, NULL, p.quantity, NOW()
+FROM products p, warehouses w
+WHERE w.is_default;
+
+-- Stock ledger starts with the seeded quantities
+INSERT INTO stock_movements (id, warehouse_id, product_id, delta, reason, actor, created_at)
+SELECT gen_random_uuid(), w.id, p.id, p.quantity, 'receipt', 'seed', p.created_at
+FROM products p, warehouses w
+WHERE w.is_default;
+
+-- Exchange rates to RUB, effective before the seeded orders
+INSERT INTO exchange_rates (id, base_currency, quote_currency, rate, effective_at, created_at) VALUES
+    ('66666666-6666-6666-6666-666666666661', 'KZT', 'RUB', 0.18, date_trunc('month', now()) - INTERVAL '2 months', NOW()),
+    ('66666666-6666-6666-6666-666666666662', 'BYN', 'RUB', 28.50, date_trunc('month', now()) - INTERVAL '2 months', NOW());
 
 -- Product categories
 INSERT INTO product_catagories (product_id, catagory_id, created_at, updated_at) VALUES
@@ -26,17 +52,34 @@ INSERT INTO product_catagories (product_id, catagory_id, created_at, updated_at)
     ('33333333-3333-3333-3333-333333333333', '11111111-1111-1111-1111-111111111113', NOW(), NOW()),
     ('33333333-3333-3333-3333-333333333334', '11111111-1111-1111-1111-111111111114', NOW(), NOW());
 
+-- Reorder points: Electronics by default, Laptop Pro already at its own point
+UPDATE categories SET reorder_point = 10 WHERE id = '11111111-1111-1111-1111-111111111111';
+UPDATE products SET reorder_point = 30 WHERE id = '33333333-3333-3333-3333-333333333333';
+
+-- Suppliers and an open purchase order for Laptop Pro, shown as incoming stock
+INSERT INTO suppliers (id, name, email, phone, address, created_at, updated_at) VALUES
+    ('88888888-8888-8888-8888-888888888881', 'ООО Дистрибуция', 'orders@distribution.example', '+7 495 000-00-00', 'Москва', NOW(), NOW());
+
+INSERT INTO purchase_orders (id, supplier_id, warehouse_id, status, currency, expected_at, note, created_at, updated_at)
+SELECT '88888888-8888-8888-8888-888888888891', '88888888-8888-8888-8888-888888888881', w.id, 'open', 'RUB',
+    NOW() + INTERVAL '7 days', '', NOW(), NOW()
+FROM warehouses w
+WHERE w.is_default;
+
+INSERT INTO purchase_order_lines (id, purchase_order_id, product_id, variant_id, quantity_ordered, quantity_received, unit_cost, position) VALUES
+    ('88888888-8888-8888-8888-8888888888a1', '88888888-8888-8888-8888-888888888891', '33333333-3333-3333-3333-333333333333', NULL, 20, 0, 1200.00, 0);
+
 -- Orders (dated in previous month to hit the top-5 query)
 INSERT INTO orders (id, customer_id, total_price, status, created_at, updated_at) VALUES
     ('44444444-4444-4444-4444-444444444441', '22222222-2222-2222-2222-222222222221', 0, 'new', date_trunc('month', now()) - INTERVAL '10 days', date_trunc('month', now()) - INTERVAL '10 days'),
     ('44444444-4444-4444-4444-444444444442', '22222222-2222-2222-2222-222222222222', 0, 'new', date_trunc('month', now()) - INTERVAL '15 days', date_trunc('month', now()) - INTERVAL '15 days');
 
 -- Order items
-INSERT INTO order_items (id, order_id, product_id, quantity, sub_total, created_at, updated_at) VALUES
-    ('55555555-5555-5555-5555-555555555551', '44444444-4444-4444-4444-444444444441', '33333333-3333-3333-3333-333333333331', 2, 1998.00, NOW(), NOW()),
-    ('55555555-5555-5555-5555-555555555552', '44444444-4444-4444-4444-444444444441', '33333333-3333-3333-3333-333333333334', 3, 59.97, NOW(), NOW()),
-    ('55555555-5555-5555-5555-555555555553', '44444444-4444-4444-4444-444444444442', '33333333-3333-3333-3333-333333333332', 4, 1996.00, NOW(), NOW()),
-    ('55555555-5555-5555-5555-555555555554', '44444444-4444-4444-4444-444444444442', '33333333-3333-3333-3333-333333333334', 5, 99.95, NOW(), NOW());
+INSERT INTO order_items (id, order_id, product_id, product_name, quantity, unit_price, sub_total, created_at, updated_at) VALUES
+    ('55555555-5555-5555-5555-555555555551', '44444444-4444-4444-4444-444444444441', '33333333-3333-3333-3333-333333333331', 'iPhone', 2, 999.00, 1998.00, NOW(), NOW()),
+    ('55555555-5555-5555-5555-555555555552', '44444444-4444-4444-4444-444444444441', '33333333-3333-3333-3333-333333333334', 'USB-C Cable', 3, 19.99, 59.97, NOW(), NOW()),
+    ('55555555-5555-5555-5555-555555555553', '44444444-4444-4444-4444-444444444442', '33333333-3333-3333-3333-333333333332', 'Android Phone', 4, 499.00, 1996.00, NOW(), NOW()),
+    ('55555555-5555-5555-5555-555555555554', '44444444-4444-4444-4444-444444444442', '33333333-3333-3333-3333-333333333334', 'USB-C Cable', 5, 19.99, 99.95, NOW(), NOW());
 
 -- Update totals based on items
 UPDATE orders o
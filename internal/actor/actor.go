@@ -0,0 +1,55 @@
+package actor
+
+import "context"
+
+type ctxKey struct{}
+
+type requestIDKey struct{}
+
+// System is reported for changes made without a known actor.
+const System = "system"
+
+// MaxLength is the longest actor name accepted from a client.
+const MaxLength = 64
+
+// Valid reports whether name is a non-empty actor name of at most MaxLength ASCII letters,
+// digits and the characters . _ - @ :.
+func Valid(name string) bool {
+	if name == "" || len(name) > MaxLength {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '.', c == '_', c == '-', c == '@', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// WithContext stores the name of whoever performs the operation in the context.
+func WithContext(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, name)
+}
+
+// FromContext returns the actor from context or System.
+func FromContext(ctx context.Context) string {
+	if name, ok := ctx.Value(ctxKey{}).(string); ok && name != "" {
+		return name
+	}
+	return System
+}
+
+// WithRequestID stores the ID of the request performing the operation, recorded next to the actor.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID from context or "" for operations outside a request.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
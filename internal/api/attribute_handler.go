@@ -0,0 +1,234 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"store-service/internal/api/dto"
+	"store-service/internal/logger"
+	"store-service/internal/repository"
+	"store-service/internal/service"
+)
+
+type attributeHandler struct {
+	svc *service.AttributeService
+}
+
+func registerAttributeRoutes(r chi.Router, svc *service.AttributeService) {
+	h := &attributeHandler{svc: svc}
+	r.Route("/attributes", func(r chi.Router) {
+		r.Get("/", h.list)
+		r.Post("/", h.create)
+		r.Get("/{id}", h.get)
+		r.Put("/{id}", h.update)
+		r.Delete("/{id}", h.delete)
+	})
+}
+
+func (h *attributeHandler) create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	var req dto.AttributeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	a := req.ToModel(uuid.Nil)
+
+	if err := h.svc.Create(ctx, &a); err != nil {
+		switch err {
+		case repository.ErrInvalidAttribute:
+			writeError(w, http.StatusBadRequest, "code must be lowercase latin, name is required, options are required for enum only")
+			return
+		case repository.ErrDuplicateAttribute:
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		log.Error("failed to create attribute", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to create attribute")
+		return
+	}
+	writeJSON(w, http.StatusCreated, dto.FromAttribute(a))
+}
+
+func (h *attributeHandler) get(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid attribute id")
+		return
+	}
+
+	a, err := h.svc.Get(ctx, id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "attribute not found")
+			return
+		}
+		log.Error("failed to get attribute", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to get attribute")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromAttribute(a))
+}
+
+func (h *attributeHandler) update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid attribute id")
+		return
+	}
+
+	var req dto.AttributeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	a := req.ToModel(id)
+
+	if err := h.svc.Update(ctx, &a); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "attribute not found")
+			return
+		case repository.ErrInvalidAttribute:
+			writeError(w, http.StatusBadRequest, "name is required, options are required for enum only")
+			return
+		}
+		log.Error("failed to update attribute", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to update attribute")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromAttribute(a))
+}
+
+func (h *attributeHandler) delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid attribute id")
+		return
+	}
+
+	if err := h.svc.Delete(ctx, id); err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "attribute not found")
+			return
+		}
+		log.Error("failed to delete attribute", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to delete attribute")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *attributeHandler) list(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	attrs, err := h.svc.List(ctx)
+	if err != nil {
+		log.Error("failed to list attributes", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to list attributes")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromAttributes(attrs))
+}
+
+func (h *attributeHandler) listForCategory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	categoryID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid category id")
+		return
+	}
+
+	attrs, err := h.svc.ListByCategory(ctx, categoryID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "category not found")
+			return
+		}
+		log.Error("failed to list category attributes", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to list category attributes")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromAttributes(attrs))
+}
+
+func (h *attributeHandler) replaceForCategory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	categoryID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid category id")
+		return
+	}
+
+	var req dto.CategoryAttributesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	attrs, err := h.svc.ReplaceForCategory(ctx, categoryID, req.AttributeIDs)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "category or attribute not found")
+			return
+		}
+		log.Error("failed to replace category attributes", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to replace category attributes")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromAttributes(attrs))
+}
+
+func (h *attributeHandler) replaceForProduct(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	var req dto.ProductAttributesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	p, err := h.svc.SetProductAttributes(ctx, productID, req.Attributes)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		case repository.ErrInvalidAttributeValue:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error("failed to set product attributes", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to set product attributes")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromProduct(p))
+}
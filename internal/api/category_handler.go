@@ -9,6 +9,7 @@ import (
 
 	"store-service/internal/api/dto"
 	"store-service/internal/logger"
+	"store-service/internal/model"
 	"store-service/internal/repository"
 	"store-service/internal/service"
 )
@@ -17,14 +18,29 @@ type categoryHandler struct {
 	svc *service.CategoryService
 }
 
-func registerCategoryRoutes(r chi.Router, svc *service.CategoryService) {
+func registerCategoryRoutes(r chi.Router, svc *service.CategoryService, links *service.ProductCategoryService, attrs *service.AttributeService, currencies *service.CurrencyService,
+	stock *service.StockService) {
 	h := &categoryHandler{svc: svc}
+	l := &productCategoryHandler{svc: links, currencies: currencies}
+	a := &attributeHandler{svc: attrs}
+	st := &stockHandler{svc: stock}
 	r.Route("/categories", func(r chi.Router) {
 		r.Get("/", h.list)
 		r.Post("/", h.create)
+		r.Get("/tree", h.tree)
+		r.Post("/reorder", h.reorderRoots)
+		r.Get("/by-slug/{slug}", h.getBySlug)
 		r.Get("/{id}", h.get)
 		r.Put("/{id}", h.update)
 		r.Delete("/{id}", h.delete)
+		r.Get("/{id}/subtree", h.subtree)
+		r.Get("/{id}/ancestors", h.ancestors)
+		r.Post("/{id}/move", h.move)
+		r.Post("/{id}/children/reorder", h.reorderChildren)
+		r.Get("/{id}/products", l.listProducts)
+		r.Get("/{id}/attributes", a.listForCategory)
+		r.Put("/{id}/attributes", a.replaceForCategory)
+		r.Put("/{id}/reorder-point", st.setCategoryReorderPoint)
 	})
 }
 
@@ -41,6 +57,14 @@ func (h *categoryHandler) create(w http.ResponseWriter, r *http.Request) {
 	c := req.ToModel(uuid.Nil)
 
 	if err := h.svc.Create(ctx, &c); err != nil {
+		switch err {
+		case repository.ErrParentNotFound:
+			writeError(w, http.StatusBadRequest, "parent category not found")
+			return
+		case repository.ErrInvalidSlug:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		log.Error("failed to create category", zapError(err))
 		writeError(w, http.StatusInternalServerError, "failed to create category")
 		return
@@ -71,6 +95,28 @@ func (h *categoryHandler) get(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, dto.FromCategory(c))
 }
 
+// getBySlug returns the category by slug; an old slug of a renamed category redirects to the current one.
+func (h *categoryHandler) getBySlug(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	c, moved, err := h.svc.GetBySlug(ctx, chi.URLParam(r, "slug"))
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "category not found")
+			return
+		}
+		log.Error("failed to get category by slug", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to get category")
+		return
+	}
+	if moved {
+		redirectToSlug(w, r, "/categories/by-slug/", c.Slug)
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromCategory(c))
+}
+
 func (h *categoryHandler) update(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	log := logger.FromContext(ctx)
@@ -90,9 +136,19 @@ func (h *categoryHandler) update(w http.ResponseWriter, r *http.Request) {
 	c := req.ToModel(id)
 
 	if err := h.svc.Update(ctx, &c); err != nil {
-		if err == repository.ErrNotFound {
+		switch err {
+		case repository.ErrNotFound:
 			writeError(w, http.StatusNotFound, "category not found")
 			return
+		case repository.ErrParentNotFound:
+			writeError(w, http.StatusBadRequest, "parent category not found")
+			return
+		case repository.ErrInvalidSlug:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		case repository.ErrCategoryCycle:
+			writeError(w, http.StatusConflict, "category cannot be placed under itself or its descendant")
+			return
 		}
 		log.Error("failed to update category", zapError(err))
 		writeError(w, http.StatusInternalServerError, "failed to update category")
@@ -111,10 +167,22 @@ func (h *categoryHandler) delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.svc.Delete(ctx, id); err != nil {
-		if err == repository.ErrNotFound {
+	strategy := model.CategoryDeleteReject
+	if v := r.URL.Query().Get("strategy"); v != "" {
+		strategy = model.CategoryDeleteStrategy(v)
+	}
+
+	if err := h.svc.Delete(ctx, id, strategy); err != nil {
+		switch err {
+		case repository.ErrNotFound:
 			writeError(w, http.StatusNotFound, "category not found")
 			return
+		case repository.ErrInvalidDeleteStrategy:
+			writeError(w, http.StatusBadRequest, "strategy must be one of reject, reparent, cascade")
+			return
+		case repository.ErrCategoryInUse:
+			writeError(w, http.StatusConflict, "category has children or products")
+			return
 		}
 		log.Error("failed to delete category", zapError(err))
 		writeError(w, http.StatusInternalServerError, "failed to delete category")
@@ -126,13 +194,171 @@ func (h *categoryHandler) delete(w http.ResponseWriter, r *http.Request) {
 func (h *categoryHandler) list(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	log := logger.FromContext(ctx)
-	limit, offset := parsePagination(r)
-
-	categories, err := h.svc.List(ctx, limit, offset)
+	categories, page, err := h.svc.List(ctx, parsePageRequest(r))
 	if err != nil {
+		if err == repository.ErrInvalidCursor {
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
 		log.Error("failed to list categories", zapError(err))
 		writeError(w, http.StatusInternalServerError, "failed to list categories")
 		return
 	}
+	writePageHeaders(w, r, page)
 	writeJSON(w, http.StatusOK, dto.FromCategories(categories))
 }
+
+func (h *categoryHandler) tree(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	roots, err := h.svc.Tree(ctx, parseBoolQuery(r, "active_only"))
+	if err != nil {
+		log.Error("failed to build category tree", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to build category tree")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromCategoryNodes(roots))
+}
+
+func (h *categoryHandler) subtree(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid category id")
+		return
+	}
+
+	node, err := h.svc.Subtree(ctx, id, parseBoolQuery(r, "active_only"))
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "category not found")
+			return
+		}
+		log.Error("failed to build category subtree", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to build category subtree")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromCategoryNode(node))
+}
+
+func (h *categoryHandler) ancestors(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid category id")
+		return
+	}
+
+	chain, err := h.svc.Ancestors(ctx, id, parseBoolQuery(r, "include_self"))
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "category not found")
+			return
+		}
+		log.Error("failed to fetch category ancestors", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to fetch category ancestors")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromCategories(chain))
+}
+
+func (h *categoryHandler) move(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid category id")
+		return
+	}
+
+	var req dto.CategoryMoveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	c, err := h.svc.Move(ctx, id, req.ParentID, req.Position)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "category not found")
+			return
+		case repository.ErrParentNotFound:
+			writeError(w, http.StatusBadRequest, "parent category not found")
+			return
+		case repository.ErrInvalidSlug, repository.ErrInvalidPosition:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		case repository.ErrCategoryCycle:
+			writeError(w, http.StatusConflict, "category cannot be placed under itself or its descendant")
+			return
+		}
+		log.Error("failed to move category", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to move category")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromCategory(c))
+}
+
+func (h *categoryHandler) reorderChildren(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid category id")
+		return
+	}
+
+	var req dto.CategoryReorderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	children, err := h.svc.ReorderChildren(ctx, &id, req.IDs)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "category not found")
+			return
+		case repository.ErrInvalidOrder:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error("failed to reorder category children", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to reorder category children")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromCategories(children))
+}
+
+// reorderRoots sets the order of the root categories.
+func (h *categoryHandler) reorderRoots(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	var req dto.CategoryReorderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	roots, err := h.svc.ReorderChildren(ctx, nil, req.IDs)
+	if err != nil {
+		if err == repository.ErrInvalidOrder {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error("failed to reorder root categories", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to reorder root categories")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromCategories(roots))
+}
@@ -126,13 +126,16 @@ func (h *customerHandler) delete(w http.ResponseWriter, r *http.Request) {
 func (h *customerHandler) list(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	log := logger.FromContext(ctx)
-	limit, offset := parsePagination(r)
-
-	customers, err := h.svc.List(ctx, limit, offset)
+	customers, page, err := h.svc.List(ctx, parsePageRequest(r))
 	if err != nil {
+		if err == repository.ErrInvalidCursor {
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
 		log.Error("failed to list customers", zapError(err))
 		writeError(w, http.StatusInternalServerError, "failed to list customers")
 		return
 	}
+	writePageHeaders(w, r, page)
 	writeJSON(w, http.StatusOK, dto.FromCustomers(customers))
 }
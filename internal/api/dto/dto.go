@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,25 +12,28 @@ import (
 )
 
 // Category DTOs
+// CategoryRequest carries client-editable fields; level, path and root are derived from the parent.
+// Slug is generated from the name when omitted on create and kept when omitted on update.
 type CategoryRequest struct {
 	Name      string     `json:"name"`
 	Slug      string     `json:"slug"`
 	ParentID  *uuid.UUID `json:"parent_id,omitempty"`
-	Level     int        `json:"level"`
 	IsActive  bool       `json:"is_active"`
 	SortOrder int        `json:"sort_order"`
 }
 
 type CategoryResponse struct {
-	ID        uuid.UUID  `json:"id"`
-	Name      string     `json:"name"`
-	Slug      string     `json:"slug"`
-	ParentID  *uuid.UUID `json:"parent_id,omitempty"`
-	Level     int        `json:"level"`
-	IsActive  bool       `json:"is_active"`
-	SortOrder int        `json:"sort_order"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
+	ID             uuid.UUID  `json:"id"`
+	Name           string     `json:"name"`
+	Slug           string     `json:"slug"`
+	ParentID       *uuid.UUID `json:"parent_id,omitempty"`
+	Level          int        `json:"level"`
+	Path           string     `json:"path"`
+	RootCategoryID *uuid.UUID `json:"root_category_id,omitempty"`
+	IsActive       bool       `json:"is_active"`
+	SortOrder      int        `json:"sort_order"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
 }
 
 func (r CategoryRequest) ToModel(id uuid.UUID) model.Category {
@@ -38,7 +42,6 @@ func (r CategoryRequest) ToModel(id uuid.UUID) model.Category {
 		Name:      r.Name,
 		Slug:      r.Slug,
 		ParentID:  r.ParentID,
-		Level:     r.Level,
 		IsActive:  r.IsActive,
 		SortOrder: r.SortOrder,
 	}
@@ -46,15 +49,17 @@ func (r CategoryRequest) ToModel(id uuid.UUID) model.Category {
 
 func FromCategory(m model.Category) CategoryResponse {
 	return CategoryResponse{
-		ID:        m.ID,
-		Name:      m.Name,
-		Slug:      m.Slug,
-		ParentID:  m.ParentID,
-		Level:     m.Level,
-		IsActive:  m.IsActive,
-		SortOrder: m.SortOrder,
-		CreatedAt: m.CreatedAt,
-		UpdatedAt: m.UpdatedAt,
+		ID:             m.ID,
+		Name:           m.Name,
+		Slug:           m.Slug,
+		ParentID:       m.ParentID,
+		Level:          m.Level,
+		Path:           m.Path,
+		RootCategoryID: m.RootCategoryID,
+		IsActive:       m.IsActive,
+		SortOrder:      m.SortOrder,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
 	}
 }
 
@@ -66,6 +71,38 @@ func FromCategories(list []model.Category) []CategoryResponse {
 	return result
 }
 
+// CategoryMoveRequest moves a category under parent_id (null for root).
+// Position is a zero-based index among the new siblings; omitted means the end.
+type CategoryMoveRequest struct {
+	ParentID *uuid.UUID `json:"parent_id"`
+	Position *int       `json:"position,omitempty"`
+}
+
+// CategoryReorderRequest lists every child of a category in the desired order.
+type CategoryReorderRequest struct {
+	IDs []uuid.UUID `json:"ids"`
+}
+
+type CategoryTreeResponse struct {
+	CategoryResponse
+	Children []CategoryTreeResponse `json:"children"`
+}
+
+func FromCategoryNode(n *model.CategoryNode) CategoryTreeResponse {
+	return CategoryTreeResponse{
+		CategoryResponse: FromCategory(n.Category),
+		Children:         FromCategoryNodes(n.Children),
+	}
+}
+
+func FromCategoryNodes(list []*model.CategoryNode) []CategoryTreeResponse {
+	result := make([]CategoryTreeResponse, 0, len(list))
+	for _, n := range list {
+		result = append(result, FromCategoryNode(n))
+	}
+	return result
+}
+
 // Customer DTOs
 type CustomerRequest struct {
 	Name    string `json:"name"`
@@ -114,46 +151,764 @@ func FromCustomers(list []model.Customer) []CustomerResponse {
 	return result
 }
 
+// Attribute DTOs
+type AttributeRequest struct {
+	Code    string              `json:"code"`
+	Name    string              `json:"name"`
+	Type    model.AttributeType `json:"type"`
+	Options []string            `json:"options"`
+}
+
+type AttributeResponse struct {
+	ID        uuid.UUID           `json:"id"`
+	Code      string              `json:"code"`
+	Name      string              `json:"name"`
+	Type      model.AttributeType `json:"type"`
+	Options   []string            `json:"options"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+// CategoryAttributesRequest replaces the attributes attached to a category, in display order.
+type CategoryAttributesRequest struct {
+	AttributeIDs []uuid.UUID `json:"attribute_ids"`
+}
+
+func (r AttributeRequest) ToModel(id uuid.UUID) model.Attribute {
+	return model.Attribute{
+		ID:      id,
+		Code:    r.Code,
+		Name:    r.Name,
+		Type:    r.Type,
+		Options: r.Options,
+	}
+}
+
+func FromAttribute(m model.Attribute) AttributeResponse {
+	options := m.Options
+	if options == nil {
+		options = []string{}
+	}
+	return AttributeResponse{
+		ID:        m.ID,
+		Code:      m.Code,
+		Name:      m.Name,
+		Type:      m.Type,
+		Options:   options,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+func FromAttributes(list []model.Attribute) []AttributeResponse {
+	result := make([]AttributeResponse, 0, len(list))
+	for _, a := range list {
+		result = append(result, FromAttribute(a))
+	}
+	return result
+}
+
+type FacetValueResponse struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type FacetResponse struct {
+	Code   string               `json:"code"`
+	Name   string               `json:"name"`
+	Type   model.AttributeType  `json:"type"`
+	Values []FacetValueResponse `json:"values,omitempty"`
+	Min    *decimal.Decimal     `json:"min,omitempty"`
+	Max    *decimal.Decimal     `json:"max,omitempty"`
+}
+
+func FromFacets(list []model.AttributeFacet) []FacetResponse {
+	result := make([]FacetResponse, 0, len(list))
+	for _, f := range list {
+		values := make([]FacetValueResponse, 0, len(f.Values))
+		for _, v := range f.Values {
+			values = append(values, FacetValueResponse{Value: v.Value, Count: v.Count})
+		}
+		result = append(result, FacetResponse{
+			Code:   f.Code,
+			Name:   f.Name,
+			Type:   f.Type,
+			Values: values,
+			Min:    f.Min,
+			Max:    f.Max,
+		})
+	}
+	return result
+}
+
 // Product DTOs
+// ProductRequest.Slug is generated from the name when omitted on create and kept when omitted on update.
 type ProductRequest struct {
-	Name     string          `json:"name"`
-	Price    decimal.Decimal `json:"price"`
-	Quantity int             `json:"quantity"`
+	Slug       string          `json:"slug"`
+	SKU        *string         `json:"sku"`
+	ExternalID *string         `json:"external_id"`
+	Name       string          `json:"name"`
+	Price      decimal.Decimal `json:"price"`
+	Currency   string          `json:"currency"`
+	Quantity   int             `json:"quantity"`
 }
 
 type ProductResponse struct {
-	ID        uuid.UUID       `json:"id"`
-	Name      string          `json:"name"`
-	Price     decimal.Decimal `json:"price"`
-	Quantity  int             `json:"quantity"`
-	CreatedAt time.Time       `json:"created_at"`
-	UpdatedAt time.Time       `json:"updated_at"`
+	ID         uuid.UUID       `json:"id"`
+	Slug       string          `json:"slug"`
+	SKU        *string         `json:"sku"`
+	ExternalID *string         `json:"external_id"`
+	Name       string          `json:"name"`
+	Price      decimal.Decimal `json:"price"`
+	Currency   model.Currency  `json:"currency"`
+	Quantity   int             `json:"quantity"`
+	Available  int             `json:"available"`
+	Incoming   int             `json:"incoming"`
+	Attributes map[string]any  `json:"attributes"`
+	Media      []MediaResponse `json:"media"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	ArchivedAt *time.Time      `json:"archived_at"`
 }
 
 func (r ProductRequest) ToModel(id uuid.UUID) model.Product {
 	return model.Product{
-		ID:       id,
-		Name:     r.Name,
-		Price:    r.Price,
-		Quantity: r.Quantity,
+		ID:         id,
+		Slug:       r.Slug,
+		SKU:        optionalString(r.SKU),
+		ExternalID: optionalString(r.ExternalID),
+		Name:       r.Name,
+		Price:      r.Price,
+		Currency:   model.Currency(strings.ToUpper(r.Currency)),
+		Quantity:   r.Quantity,
 	}
 }
 
 func FromProduct(m model.Product) ProductResponse {
+	attrs := m.Attributes
+	if attrs == nil {
+		attrs = map[string]any{}
+	}
 	return ProductResponse{
+		ID:         m.ID,
+		Slug:       m.Slug,
+		SKU:        m.SKU,
+		ExternalID: m.ExternalID,
+		Name:       m.Name,
+		Price:      m.Price,
+		Currency:   m.Currency,
+		Quantity:   m.Quantity,
+		Available:  m.Available,
+		Incoming:   m.Incoming,
+		Attributes: attrs,
+		Media:      FromMediaList(m.Media),
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+		ArchivedAt: m.ArchivedAt,
+	}
+}
+
+// optionalString treats a blank value as absent.
+func optionalString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+func FromProducts(list []model.Product) []ProductResponse {
+	result := make([]ProductResponse, 0, len(list))
+	for _, p := range list {
+		result = append(result, FromProduct(p))
+	}
+	return result
+}
+
+type RecommendationResponse struct {
+	ProductResponse
+	OrdersTogether int     `json:"orders_together"`
+	Confidence     float64 `json:"confidence"`
+	Lift           float64 `json:"lift"`
+}
+
+func FromRecommendations(list []model.ProductRecommendation) []RecommendationResponse {
+	result := make([]RecommendationResponse, 0, len(list))
+	for _, rec := range list {
+		result = append(result, RecommendationResponse{
+			ProductResponse: FromProduct(rec.Product),
+			OrdersTogether:  rec.OrdersTogether,
+			Confidence:      rec.Confidence,
+			Lift:            rec.Lift,
+		})
+	}
+	return result
+}
+
+type ProductSearchResponse struct {
+	ProductResponse
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+func FromProductSearchHits(list []model.ProductSearchHit) []ProductSearchResponse {
+	result := make([]ProductSearchResponse, 0, len(list))
+	for _, h := range list {
+		result = append(result, ProductSearchResponse{
+			ProductResponse: FromProduct(h.Product),
+			Rank:            h.Rank,
+			Snippet:         h.Snippet,
+		})
+	}
+	return result
+}
+
+type MediaThumbnailResponse struct {
+	Size int    `json:"size"`
+	URL  string `json:"url"`
+}
+
+type MediaResponse struct {
+	ID          uuid.UUID                `json:"id"`
+	URL         string                   `json:"url"`
+	ContentType string                   `json:"content_type"`
+	Size        int64                    `json:"size"`
+	Width       int                      `json:"width"`
+	Height      int                      `json:"height"`
+	Thumbnails  []MediaThumbnailResponse `json:"thumbnails"`
+	Position    int                      `json:"position"`
+	IsPrimary   bool                     `json:"is_primary"`
+	CreatedAt   time.Time                `json:"created_at"`
+}
+
+// MediaReorderRequest sets the gallery order; ids must list every media of the product.
+type MediaReorderRequest struct {
+	IDs []uuid.UUID `json:"ids"`
+}
+
+func FromMedia(m model.ProductMedia) MediaResponse {
+	thumbnails := make([]MediaThumbnailResponse, 0, len(m.Thumbnails))
+	for _, t := range m.Thumbnails {
+		thumbnails = append(thumbnails, MediaThumbnailResponse{Size: t.Size, URL: t.URL})
+	}
+	return MediaResponse{
+		ID:          m.ID,
+		URL:         m.URL,
+		ContentType: m.ContentType,
+		Size:        m.Size,
+		Width:       m.Width,
+		Height:      m.Height,
+		Thumbnails:  thumbnails,
+		Position:    m.Position,
+		IsPrimary:   m.IsPrimary,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+func FromMediaList(list []model.ProductMedia) []MediaResponse {
+	result := make([]MediaResponse, 0, len(list))
+	for _, m := range list {
+		result = append(result, FromMedia(m))
+	}
+	return result
+}
+
+// ScheduledPriceRequest plans a price change at a future moment; an empty currency keeps the product one.
+type ScheduledPriceRequest struct {
+	Price         decimal.Decimal `json:"price"`
+	Currency      string          `json:"currency"`
+	EffectiveFrom time.Time       `json:"effective_from"`
+}
+
+type PriceResponse struct {
+	ID            uuid.UUID       `json:"id"`
+	Price         decimal.Decimal `json:"price"`
+	Currency      model.Currency  `json:"currency"`
+	EffectiveFrom time.Time       `json:"effective_from"`
+	EffectiveTo   *time.Time      `json:"effective_to"`
+	AppliedAt     *time.Time      `json:"applied_at"`
+	Scheduled     bool            `json:"scheduled"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+func (r ScheduledPriceRequest) ToModel(productID uuid.UUID) model.ProductPrice {
+	return model.ProductPrice{
+		ProductID:     productID,
+		Price:         r.Price,
+		Currency:      model.Currency(strings.ToUpper(r.Currency)),
+		EffectiveFrom: r.EffectiveFrom,
+	}
+}
+
+func FromPrice(m model.ProductPrice) PriceResponse {
+	return PriceResponse{
+		ID:            m.ID,
+		Price:         m.Price,
+		Currency:      m.Currency,
+		EffectiveFrom: m.EffectiveFrom,
+		EffectiveTo:   m.EffectiveTo,
+		AppliedAt:     m.AppliedAt,
+		Scheduled:     m.Scheduled(),
+		CreatedAt:     m.CreatedAt,
+	}
+}
+
+func FromPrices(list []model.ProductPrice) []PriceResponse {
+	result := make([]PriceResponse, 0, len(list))
+	for _, p := range list {
+		result = append(result, FromPrice(p))
+	}
+	return result
+}
+
+type StockMovementResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	WarehouseID uuid.UUID  `json:"warehouse_id"`
+	ProductID   uuid.UUID  `json:"product_id"`
+	VariantID   *uuid.UUID `json:"variant_id,omitempty"`
+	Delta       int        `json:"delta"`
+	Reason      string     `json:"reason"`
+	ReferenceID *uuid.UUID `json:"reference_id,omitempty"`
+	Actor       string     `json:"actor"`
+	RequestID   string     `json:"request_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func FromStockMovements(list []model.StockMovement) []StockMovementResponse {
+	result := make([]StockMovementResponse, 0, len(list))
+	for _, m := range list {
+		result = append(result, StockMovementResponse{
+			ID:          m.ID,
+			WarehouseID: m.WarehouseID,
+			ProductID:   m.ProductID,
+			VariantID:   m.VariantID,
+			Delta:       m.Delta,
+			Reason:      m.Reason,
+			ReferenceID: m.ReferenceID,
+			Actor:       m.Actor,
+			RequestID:   m.RequestID,
+			CreatedAt:   m.CreatedAt,
+		})
+	}
+	return result
+}
+
+// StockDiscrepancyResponse is a product or variant whose cached quantity differs from its ledger;
+// warehouse_id is set when the stock level of a single warehouse differs.
+type StockDiscrepancyResponse struct {
+	WarehouseID    *uuid.UUID `json:"warehouse_id,omitempty"`
+	ProductID      uuid.UUID  `json:"product_id"`
+	VariantID      *uuid.UUID `json:"variant_id,omitempty"`
+	Quantity       int        `json:"quantity"`
+	LedgerQuantity int        `json:"ledger_quantity"`
+	Difference     int        `json:"difference"`
+}
+
+func FromStockDiscrepancies(list []model.StockDiscrepancy) []StockDiscrepancyResponse {
+	result := make([]StockDiscrepancyResponse, 0, len(list))
+	for _, d := range list {
+		result = append(result, StockDiscrepancyResponse{
+			WarehouseID:    d.WarehouseID,
+			ProductID:      d.ProductID,
+			VariantID:      d.VariantID,
+			Quantity:       d.Quantity,
+			LedgerQuantity: d.LedgerQuantity,
+			Difference:     d.Quantity - d.LedgerQuantity,
+		})
+	}
+	return result
+}
+
+// Warehouse DTOs
+type WarehouseRequest struct {
+	Code      string   `json:"code"`
+	Name      string   `json:"name"`
+	Priority  *int     `json:"priority,omitempty"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+	IsDefault bool     `json:"is_default"`
+	Active    *bool    `json:"active,omitempty"`
+}
+
+type WarehouseResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Priority  int       `json:"priority"`
+	Latitude  *float64  `json:"latitude"`
+	Longitude *float64  `json:"longitude"`
+	IsDefault bool      `json:"is_default"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ToModel fills defaults for omitted fields: priority 100, active.
+func (r WarehouseRequest) ToModel(id uuid.UUID) model.Warehouse {
+	w := model.Warehouse{
+		ID:        id,
+		Code:      strings.TrimSpace(r.Code),
+		Name:      r.Name,
+		Priority:  100,
+		Latitude:  r.Latitude,
+		Longitude: r.Longitude,
+		IsDefault: r.IsDefault,
+		Active:    true,
+	}
+	if r.Priority != nil {
+		w.Priority = *r.Priority
+	}
+	if r.Active != nil {
+		w.Active = *r.Active
+	}
+	return w
+}
+
+func FromWarehouse(m model.Warehouse) WarehouseResponse {
+	return WarehouseResponse{
 		ID:        m.ID,
+		Code:      m.Code,
 		Name:      m.Name,
+		Priority:  m.Priority,
+		Latitude:  m.Latitude,
+		Longitude: m.Longitude,
+		IsDefault: m.IsDefault,
+		Active:    m.Active,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+func FromWarehouses(list []model.Warehouse) []WarehouseResponse {
+	result := make([]WarehouseResponse, 0, len(list))
+	for _, w := range list {
+		result = append(result, FromWarehouse(w))
+	}
+	return result
+}
+
+// WarehouseStockRequest sets the stock level of a product or variant in the warehouse; the
+// difference is recorded as an adjustment.
+type WarehouseStockRequest struct {
+	ProductID uuid.UUID  `json:"product_id"`
+	VariantID *uuid.UUID `json:"variant_id,omitempty"`
+	Quantity  int        `json:"quantity"`
+}
+
+type WarehouseStockResponse struct {
+	WarehouseID uuid.UUID  `json:"warehouse_id"`
+	ProductID   uuid.UUID  `json:"product_id"`
+	VariantID   *uuid.UUID `json:"variant_id,omitempty"`
+	Quantity    int        `json:"quantity"`
+	Available   int        `json:"available"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (r WarehouseStockRequest) ToModel(warehouseID uuid.UUID) model.WarehouseStock {
+	return model.WarehouseStock{
+		WarehouseID: warehouseID,
+		ProductID:   r.ProductID,
+		VariantID:   r.VariantID,
+		Quantity:    r.Quantity,
+	}
+}
+
+func FromWarehouseStock(m model.WarehouseStock) WarehouseStockResponse {
+	return WarehouseStockResponse{
+		WarehouseID: m.WarehouseID,
+		ProductID:   m.ProductID,
+		VariantID:   m.VariantID,
+		Quantity:    m.Quantity,
+		Available:   m.Available,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+func FromWarehouseStocks(list []model.WarehouseStock) []WarehouseStockResponse {
+	result := make([]WarehouseStockResponse, 0, len(list))
+	for _, s := range list {
+		result = append(result, FromWarehouseStock(s))
+	}
+	return result
+}
+
+type WarehouseTransferLineRequest struct {
+	ProductID uuid.UUID  `json:"product_id"`
+	VariantID *uuid.UUID `json:"variant_id,omitempty"`
+	Quantity  int        `json:"quantity"`
+}
+
+// WarehouseTransferRequest moves stock between warehouses; it is applied as soon as it is created.
+type WarehouseTransferRequest struct {
+	FromWarehouseID uuid.UUID                      `json:"from_warehouse_id"`
+	ToWarehouseID   uuid.UUID                      `json:"to_warehouse_id"`
+	Note            string                         `json:"note"`
+	Lines           []WarehouseTransferLineRequest `json:"lines"`
+}
+
+type WarehouseTransferLineResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	ProductID uuid.UUID  `json:"product_id"`
+	VariantID *uuid.UUID `json:"variant_id,omitempty"`
+	Quantity  int        `json:"quantity"`
+}
+
+type WarehouseTransferResponse struct {
+	ID              uuid.UUID                       `json:"id"`
+	FromWarehouseID uuid.UUID                       `json:"from_warehouse_id"`
+	ToWarehouseID   uuid.UUID                       `json:"to_warehouse_id"`
+	Note            string                          `json:"note"`
+	Actor           string                          `json:"actor"`
+	Lines           []WarehouseTransferLineResponse `json:"lines"`
+	CreatedAt       time.Time                       `json:"created_at"`
+}
+
+func (r WarehouseTransferRequest) ToModel() model.WarehouseTransfer {
+	lines := make([]model.WarehouseTransferLine, 0, len(r.Lines))
+	for _, l := range r.Lines {
+		lines = append(lines, model.WarehouseTransferLine{ProductID: l.ProductID, VariantID: l.VariantID, Quantity: l.Quantity})
+	}
+	return model.WarehouseTransfer{
+		FromWarehouseID: r.FromWarehouseID,
+		ToWarehouseID:   r.ToWarehouseID,
+		Note:            r.Note,
+		Lines:           lines,
+	}
+}
+
+func FromWarehouseTransfer(m model.WarehouseTransfer) WarehouseTransferResponse {
+	lines := make([]WarehouseTransferLineResponse, 0, len(m.Lines))
+	for _, l := range m.Lines {
+		lines = append(lines, WarehouseTransferLineResponse{
+			ID:        l.ID,
+			ProductID: l.ProductID,
+			VariantID: l.VariantID,
+			Quantity:  l.Quantity,
+		})
+	}
+	return WarehouseTransferResponse{
+		ID:              m.ID,
+		FromWarehouseID: m.FromWarehouseID,
+		ToWarehouseID:   m.ToWarehouseID,
+		Note:            m.Note,
+		Actor:           m.Actor,
+		Lines:           lines,
+		CreatedAt:       m.CreatedAt,
+	}
+}
+
+func FromWarehouseTransfers(list []model.WarehouseTransfer) []WarehouseTransferResponse {
+	result := make([]WarehouseTransferResponse, 0, len(list))
+	for _, t := range list {
+		result = append(result, FromWarehouseTransfer(t))
+	}
+	return result
+}
+
+// ExchangeRateRequest sets the rate of base to quote from effective_at (now when omitted).
+type ExchangeRateRequest struct {
+	Base        string          `json:"base"`
+	Quote       string          `json:"quote"`
+	Rate        decimal.Decimal `json:"rate"`
+	EffectiveAt time.Time       `json:"effective_at"`
+}
+
+type ExchangeRateResponse struct {
+	ID          uuid.UUID       `json:"id"`
+	Base        model.Currency  `json:"base"`
+	Quote       model.Currency  `json:"quote"`
+	Rate        decimal.Decimal `json:"rate"`
+	EffectiveAt time.Time       `json:"effective_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+func (r ExchangeRateRequest) ToModel() model.ExchangeRate {
+	return model.ExchangeRate{
+		Base:        model.Currency(strings.ToUpper(r.Base)),
+		Quote:       model.Currency(strings.ToUpper(r.Quote)),
+		Rate:        r.Rate,
+		EffectiveAt: r.EffectiveAt,
+	}
+}
+
+func FromExchangeRate(m model.ExchangeRate) ExchangeRateResponse {
+	return ExchangeRateResponse{
+		ID:          m.ID,
+		Base:        m.Base,
+		Quote:       m.Quote,
+		Rate:        m.Rate,
+		EffectiveAt: m.EffectiveAt,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+func FromExchangeRates(list []model.ExchangeRate) []ExchangeRateResponse {
+	result := make([]ExchangeRateResponse, 0, len(list))
+	for _, e := range list {
+		result = append(result, FromExchangeRate(e))
+	}
+	return result
+}
+
+// ImportJobResponse is the state of a product import; report lists the result of every processed row.
+type ImportJobResponse struct {
+	ID            uuid.UUID           `json:"id"`
+	Filename      string              `json:"filename"`
+	Format        model.ImportFormat  `json:"format"`
+	DryRun        bool                `json:"dry_run"`
+	Status        model.ImportStatus  `json:"status"`
+	TotalRows     int                 `json:"total_rows"`
+	ProcessedRows int                 `json:"processed_rows"`
+	Created       int                 `json:"created"`
+	Updated       int                 `json:"updated"`
+	Failed        int                 `json:"failed"`
+	Report        []ImportRowResponse `json:"report"`
+	Error         string              `json:"error,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+	StartedAt     *time.Time          `json:"started_at"`
+	FinishedAt    *time.Time          `json:"finished_at"`
+}
+
+type ImportRowResponse struct {
+	Row       int                `json:"row"`
+	Action    model.ImportAction `json:"action"`
+	ProductID *uuid.UUID         `json:"product_id,omitempty"`
+	Errors    []string           `json:"errors,omitempty"`
+}
+
+func FromImportJob(m model.ImportJob) ImportJobResponse {
+	report := make([]ImportRowResponse, 0, len(m.Report))
+	for _, r := range m.Report {
+		report = append(report, ImportRowResponse{
+			Row:       r.Row,
+			Action:    r.Action,
+			ProductID: r.ProductID,
+			Errors:    r.Errors,
+		})
+	}
+	return ImportJobResponse{
+		ID:            m.ID,
+		Filename:      m.Filename,
+		Format:        m.Format,
+		DryRun:        m.DryRun,
+		Status:        m.Status,
+		TotalRows:     m.TotalRows,
+		ProcessedRows: m.ProcessedRows,
+		Created:       m.Created,
+		Updated:       m.Updated,
+		Failed:        m.Failed,
+		Report:        report,
+		Error:         m.Error,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+		StartedAt:     m.StartedAt,
+		FinishedAt:    m.FinishedAt,
+	}
+}
+
+// ProductAttributesRequest replaces all attribute values of a product, keyed by attribute code.
+type ProductAttributesRequest struct {
+	Attributes map[string]any `json:"attributes"`
+}
+
+// ProductCategoriesRequest replaces the full set of product categories.
+type ProductCategoriesRequest struct {
+	CategoryIDs []uuid.UUID `json:"category_ids"`
+}
+
+// Variant DTOs
+type ProductOptionRequest struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// ProductOptionsRequest replaces all option definitions of a product, in display order.
+type ProductOptionsRequest struct {
+	Options []ProductOptionRequest `json:"options"`
+}
+
+type ProductOptionResponse struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	Values   []string  `json:"values"`
+	Position int       `json:"position"`
+}
+
+func (r ProductOptionsRequest) ToModel() []model.ProductOption {
+	result := make([]model.ProductOption, 0, len(r.Options))
+	for _, o := range r.Options {
+		result = append(result, model.ProductOption{Name: o.Name, Values: o.Values})
+	}
+	return result
+}
+
+func FromProductOptions(list []model.ProductOption) []ProductOptionResponse {
+	result := make([]ProductOptionResponse, 0, len(list))
+	for _, o := range list {
+		result = append(result, ProductOptionResponse{
+			ID:       o.ID,
+			Name:     o.Name,
+			Values:   o.Values,
+			Position: o.Position,
+		})
+	}
+	return result
+}
+
+type VariantRequest struct {
+	SKU      string            `json:"sku"`
+	Options  map[string]string `json:"options"`
+	Price    *decimal.Decimal  `json:"price,omitempty"`
+	Quantity int               `json:"quantity"`
+}
+
+type VariantResponse struct {
+	ID        uuid.UUID         `json:"id"`
+	ProductID uuid.UUID         `json:"product_id"`
+	SKU       string            `json:"sku"`
+	Options   map[string]string `json:"options"`
+	Price     *decimal.Decimal  `json:"price,omitempty"`
+	Quantity  int               `json:"quantity"`
+	Available int               `json:"available"`
+	Incoming  int               `json:"incoming"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+func (r VariantRequest) ToModel(productID, id uuid.UUID) model.ProductVariant {
+	return model.ProductVariant{
+		ID:        id,
+		ProductID: productID,
+		SKU:       r.SKU,
+		Options:   r.Options,
+		Price:     r.Price,
+		Quantity:  r.Quantity,
+	}
+}
+
+func FromVariant(m model.ProductVariant) VariantResponse {
+	return VariantResponse{
+		ID:        m.ID,
+		ProductID: m.ProductID,
+		SKU:       m.SKU,
+		Options:   m.Options,
 		Price:     m.Price,
 		Quantity:  m.Quantity,
+		Available: m.Available,
+		Incoming:  m.Incoming,
 		CreatedAt: m.CreatedAt,
 		UpdatedAt: m.UpdatedAt,
 	}
 }
 
-func FromProducts(list []model.Product) []ProductResponse {
-	result := make([]ProductResponse, 0, len(list))
-	for _, p := range list {
-		result = append(result, FromProduct(p))
+func FromVariants(list []model.ProductVariant) []VariantResponse {
+	result := make([]VariantResponse, 0, len(list))
+	for _, v := range list {
+		result = append(result, FromVariant(v))
 	}
 	return result
 }
@@ -161,57 +916,78 @@ func FromProducts(list []model.Product) []ProductResponse {
 // Order DTOs
 type OrderRequest struct {
 	CustomerID uuid.UUID `json:"customer_id"`
+	Currency   string    `json:"currency"`
 	Status     string    `json:"status"`
+	// ShippingLatitude and ShippingLongitude are the delivery point used by the nearest allocation.
+	ShippingLatitude  *float64 `json:"shipping_latitude,omitempty"`
+	ShippingLongitude *float64 `json:"shipping_longitude,omitempty"`
 }
 
 func (r OrderRequest) ToModel(id uuid.UUID) model.Order {
 	return model.Order{
-		ID:         id,
-		CustomerID: r.CustomerID,
-		Status:     r.Status,
+		ID:                id,
+		CustomerID:        r.CustomerID,
+		Currency:          model.Currency(strings.ToUpper(r.Currency)),
+		Status:            r.Status,
+		ShippingLatitude:  r.ShippingLatitude,
+		ShippingLongitude: r.ShippingLongitude,
 	}
 }
 
 type OrderItemResponse struct {
-	ID        uuid.UUID       `json:"id"`
-	ProductID uuid.UUID       `json:"product_id"`
-	Quantity  int             `json:"quantity"`
-	SubTotal  decimal.Decimal `json:"sub_total"`
-	CreatedAt time.Time       `json:"created_at"`
-	UpdatedAt time.Time       `json:"updated_at"`
+	ID          uuid.UUID       `json:"id"`
+	ProductID   uuid.UUID       `json:"product_id"`
+	VariantID   *uuid.UUID      `json:"variant_id,omitempty"`
+	ProductName string          `json:"product_name"`
+	Quantity    int             `json:"quantity"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+	SubTotal    decimal.Decimal `json:"sub_total"`
+	WarehouseID *uuid.UUID      `json:"warehouse_id"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
 }
 
 type OrderResponse struct {
-	ID         uuid.UUID           `json:"id"`
-	CustomerID uuid.UUID           `json:"customer_id"`
-	Items      []OrderItemResponse `json:"items"`
-	TotalPrice decimal.Decimal     `json:"total_price"`
-	Status     string              `json:"status"`
-	CreatedAt  time.Time           `json:"created_at"`
-	UpdatedAt  time.Time           `json:"updated_at"`
+	ID                uuid.UUID           `json:"id"`
+	CustomerID        uuid.UUID           `json:"customer_id"`
+	Items             []OrderItemResponse `json:"items"`
+	TotalPrice        decimal.Decimal     `json:"total_price"`
+	Currency          model.Currency      `json:"currency"`
+	Status            string              `json:"status"`
+	ShippingLatitude  *float64            `json:"shipping_latitude"`
+	ShippingLongitude *float64            `json:"shipping_longitude"`
+	CreatedAt         time.Time           `json:"created_at"`
+	UpdatedAt         time.Time           `json:"updated_at"`
 }
 
 func FromOrder(m model.Order) OrderResponse {
 	items := make([]OrderItemResponse, 0, len(m.Items))
 	for _, it := range m.Items {
 		items = append(items, OrderItemResponse{
-			ID:        it.ID,
-			ProductID: it.ProductID,
-			Quantity:  it.Quantity,
-			SubTotal:  it.SubTotal,
-			CreatedAt: it.CreatedAt,
-			UpdatedAt: it.UpdatedAt,
+			ID:          it.ID,
+			ProductID:   it.ProductID,
+			VariantID:   it.VariantID,
+			ProductName: it.ProductName,
+			Quantity:    it.Quantity,
+			UnitPrice:   it.UnitPrice,
+			SubTotal:    it.SubTotal,
+			WarehouseID: it.WarehouseID,
+			CreatedAt:   it.CreatedAt,
+			UpdatedAt:   it.UpdatedAt,
 		})
 	}
 
 	return OrderResponse{
-		ID:         m.ID,
-		CustomerID: m.CustomerID,
-		Items:      items,
-		TotalPrice: m.TotalPrice,
-		Status:     m.Status,
-		CreatedAt:  m.CreatedAt,
-		UpdatedAt:  m.UpdatedAt,
+		ID:                m.ID,
+		CustomerID:        m.CustomerID,
+		Items:             items,
+		TotalPrice:        m.TotalPrice,
+		Currency:          m.Currency,
+		Status:            m.Status,
+		ShippingLatitude:  m.ShippingLatitude,
+		ShippingLongitude: m.ShippingLongitude,
+		CreatedAt:         m.CreatedAt,
+		UpdatedAt:         m.UpdatedAt,
 	}
 }
 
@@ -224,14 +1000,16 @@ func FromOrders(list []model.Order) []OrderResponse {
 }
 
 type AddItemRequest struct {
-	ProductID uuid.UUID `json:"product_id"`
-	Quantity  int       `json:"quantity"`
+	ProductID uuid.UUID  `json:"product_id"`
+	VariantID *uuid.UUID `json:"variant_id,omitempty"`
+	Quantity  int        `json:"quantity"`
 }
 
 // Report DTOs
 type CustomerTotalResponse struct {
 	CustomerName string          `json:"customer_name"`
 	TotalAmount  decimal.Decimal `json:"total_amount"`
+	Currency     model.Currency  `json:"currency"`
 }
 
 type CategoryChildrenResponse struct {
@@ -252,6 +1030,7 @@ func FromCustomerTotals(list []repository.CustomerTotal) []CustomerTotalResponse
 		result = append(result, CustomerTotalResponse{
 			CustomerName: row.CustomerName,
 			TotalAmount:  row.TotalAmount,
+			Currency:     row.Currency,
 		})
 	}
 	return result
@@ -280,3 +1059,386 @@ func FromTopProducts(list []repository.TopProduct) []TopProductResponse {
 	}
 	return result
 }
+
+// LowStockResponse is a product at or below its reorder point; shortfall is how many units bring
+// the available stock back to the point, incoming is already ordered from suppliers.
+type LowStockResponse struct {
+	ProductID    uuid.UUID `json:"product_id"`
+	Name         string    `json:"name"`
+	SKU          *string   `json:"sku"`
+	Quantity     int       `json:"quantity"`
+	Available    int       `json:"available"`
+	Incoming     int       `json:"incoming"`
+	ReorderPoint int       `json:"reorder_point"`
+	Source       string    `json:"source"`
+	Shortfall    int       `json:"shortfall"`
+}
+
+func FromLowStock(list []repository.LowStockProduct) []LowStockResponse {
+	result := make([]LowStockResponse, 0, len(list))
+	for _, row := range list {
+		result = append(result, LowStockResponse{
+			ProductID:    row.ProductID,
+			Name:         row.Name,
+			SKU:          row.SKU,
+			Quantity:     row.Quantity,
+			Available:    row.Available,
+			Incoming:     row.Incoming,
+			ReorderPoint: row.ReorderPoint,
+			Source:       row.Source,
+			Shortfall:    row.ReorderPoint - row.Available,
+		})
+	}
+	return result
+}
+
+// ReorderPointRequest sets a reorder point; null removes it.
+type ReorderPointRequest struct {
+	ReorderPoint *int `json:"reorder_point"`
+}
+
+type ReorderPointResponse struct {
+	ProductID            uuid.UUID `json:"product_id"`
+	ReorderPoint         *int      `json:"reorder_point"`
+	CategoryReorderPoint *int      `json:"category_reorder_point"`
+	Effective            *int      `json:"effective"`
+}
+
+func FromReorderPoint(m model.ReorderPoint) ReorderPointResponse {
+	return ReorderPointResponse{
+		ProductID:            m.ProductID,
+		ReorderPoint:         m.ReorderPoint,
+		CategoryReorderPoint: m.CategoryReorderPoint,
+		Effective:            m.Effective,
+	}
+}
+
+// Supplier DTOs
+type SupplierRequest struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Phone   string `json:"phone"`
+	Address string `json:"address"`
+}
+
+type SupplierResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Phone     string    `json:"phone"`
+	Address   string    `json:"address"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (r SupplierRequest) ToModel(id uuid.UUID) model.Supplier {
+	return model.Supplier{
+		ID:      id,
+		Name:    r.Name,
+		Email:   r.Email,
+		Phone:   r.Phone,
+		Address: r.Address,
+	}
+}
+
+func FromSupplier(m model.Supplier) SupplierResponse {
+	return SupplierResponse{
+		ID:        m.ID,
+		Name:      m.Name,
+		Email:     m.Email,
+		Phone:     m.Phone,
+		Address:   m.Address,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+func FromSuppliers(list []model.Supplier) []SupplierResponse {
+	result := make([]SupplierResponse, 0, len(list))
+	for _, s := range list {
+		result = append(result, FromSupplier(s))
+	}
+	return result
+}
+
+// Purchase order DTOs
+type PurchaseOrderLineRequest struct {
+	ProductID uuid.UUID       `json:"product_id"`
+	VariantID *uuid.UUID      `json:"variant_id,omitempty"`
+	Quantity  int             `json:"quantity"`
+	UnitCost  decimal.Decimal `json:"unit_cost"`
+}
+
+// PurchaseOrderRequest places a purchase order; currency defaults to the base currency.
+type PurchaseOrderRequest struct {
+	SupplierID  uuid.UUID                  `json:"supplier_id"`
+	WarehouseID uuid.UUID                  `json:"warehouse_id"`
+	Currency    string                     `json:"currency"`
+	ExpectedAt  *time.Time                 `json:"expected_at"`
+	Note        string                     `json:"note"`
+	Lines       []PurchaseOrderLineRequest `json:"lines"`
+}
+
+type PurchaseOrderLineResponse struct {
+	ID               uuid.UUID       `json:"id"`
+	ProductID        uuid.UUID       `json:"product_id"`
+	VariantID        *uuid.UUID      `json:"variant_id,omitempty"`
+	QuantityOrdered  int             `json:"quantity_ordered"`
+	QuantityReceived int             `json:"quantity_received"`
+	QuantityPending  int             `json:"quantity_pending"`
+	UnitCost         decimal.Decimal `json:"unit_cost"`
+}
+
+type PurchaseOrderResponse struct {
+	ID          uuid.UUID                   `json:"id"`
+	SupplierID  uuid.UUID                   `json:"supplier_id"`
+	WarehouseID uuid.UUID                   `json:"warehouse_id"`
+	Status      string                      `json:"status"`
+	Currency    model.Currency              `json:"currency"`
+	ExpectedAt  *time.Time                  `json:"expected_at"`
+	Note        string                      `json:"note"`
+	Lines       []PurchaseOrderLineResponse `json:"lines"`
+	Receipts    []PurchaseReceiptResponse   `json:"receipts,omitempty"`
+	CreatedAt   time.Time                   `json:"created_at"`
+	UpdatedAt   time.Time                   `json:"updated_at"`
+}
+
+func (r PurchaseOrderRequest) ToModel() model.PurchaseOrder {
+	lines := make([]model.PurchaseOrderLine, 0, len(r.Lines))
+	for _, l := range r.Lines {
+		lines = append(lines, model.PurchaseOrderLine{
+			ProductID:       l.ProductID,
+			VariantID:       l.VariantID,
+			QuantityOrdered: l.Quantity,
+			UnitCost:        l.UnitCost,
+		})
+	}
+	return model.PurchaseOrder{
+		SupplierID:  r.SupplierID,
+		WarehouseID: r.WarehouseID,
+		Currency:    model.Currency(strings.ToUpper(r.Currency)),
+		ExpectedAt:  r.ExpectedAt,
+		Note:        r.Note,
+		Lines:       lines,
+	}
+}
+
+// FromPurchaseOrder converts the purchase order; quantity_pending is what is still expected on
+// the line, zero once the order is cancelled.
+func FromPurchaseOrder(m model.PurchaseOrder) PurchaseOrderResponse {
+	lines := make([]PurchaseOrderLineResponse, 0, len(m.Lines))
+	for _, l := range m.Lines {
+		pending := 0
+		if model.PurchaseOrderPending(m.Status) {
+			pending = l.QuantityOrdered - l.QuantityReceived
+		}
+		lines = append(lines, PurchaseOrderLineResponse{
+			ID:               l.ID,
+			ProductID:        l.ProductID,
+			VariantID:        l.VariantID,
+			QuantityOrdered:  l.QuantityOrdered,
+			QuantityReceived: l.QuantityReceived,
+			QuantityPending:  pending,
+			UnitCost:         l.UnitCost,
+		})
+	}
+	var receipts []PurchaseReceiptResponse
+	if m.Receipts != nil {
+		receipts = make([]PurchaseReceiptResponse, 0, len(m.Receipts))
+		for _, rc := range m.Receipts {
+			receipts = append(receipts, FromPurchaseReceipt(rc))
+		}
+	}
+	return PurchaseOrderResponse{
+		ID:          m.ID,
+		SupplierID:  m.SupplierID,
+		WarehouseID: m.WarehouseID,
+		Status:      m.Status,
+		Currency:    m.Currency,
+		ExpectedAt:  m.ExpectedAt,
+		Note:        m.Note,
+		Lines:       lines,
+		Receipts:    receipts,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+func FromPurchaseOrders(list []model.PurchaseOrder) []PurchaseOrderResponse {
+	result := make([]PurchaseOrderResponse, 0, len(list))
+	for _, po := range list {
+		result = append(result, FromPurchaseOrder(po))
+	}
+	return result
+}
+
+type PurchaseReceiptLineRequest struct {
+	LineID   uuid.UUID `json:"line_id"`
+	Quantity int       `json:"quantity"`
+}
+
+// PurchaseReceiptRequest receives goods of purchase order lines; extra_cost (freight, duties) is
+// added to the landed cost of the received lines.
+type PurchaseReceiptRequest struct {
+	ExtraCost decimal.Decimal              `json:"extra_cost"`
+	Note      string                       `json:"note"`
+	Lines     []PurchaseReceiptLineRequest `json:"lines"`
+}
+
+type PurchaseReceiptLineResponse struct {
+	ID             uuid.UUID       `json:"id"`
+	LineID         uuid.UUID       `json:"line_id"`
+	ProductID      uuid.UUID       `json:"product_id"`
+	VariantID      *uuid.UUID      `json:"variant_id,omitempty"`
+	Quantity       int             `json:"quantity"`
+	UnitCost       decimal.Decimal `json:"unit_cost"`
+	LandedUnitCost decimal.Decimal `json:"landed_unit_cost"`
+}
+
+type PurchaseReceiptResponse struct {
+	ID              uuid.UUID                     `json:"id"`
+	PurchaseOrderID uuid.UUID                     `json:"purchase_order_id"`
+	ExtraCost       decimal.Decimal               `json:"extra_cost"`
+	Note            string                        `json:"note"`
+	Actor           string                        `json:"actor"`
+	Lines           []PurchaseReceiptLineResponse `json:"lines"`
+	ReceivedAt      time.Time                     `json:"received_at"`
+}
+
+func (r PurchaseReceiptRequest) ToModel(purchaseOrderID uuid.UUID) model.PurchaseReceipt {
+	lines := make([]model.PurchaseReceiptLine, 0, len(r.Lines))
+	for _, l := range r.Lines {
+		lines = append(lines, model.PurchaseReceiptLine{LineID: l.LineID, Quantity: l.Quantity})
+	}
+	return model.PurchaseReceipt{
+		PurchaseOrderID: purchaseOrderID,
+		ExtraCost:       r.ExtraCost,
+		Note:            r.Note,
+		Lines:           lines,
+	}
+}
+
+func FromPurchaseReceipt(m model.PurchaseReceipt) PurchaseReceiptResponse {
+	lines := make([]PurchaseReceiptLineResponse, 0, len(m.Lines))
+	for _, l := range m.Lines {
+		lines = append(lines, PurchaseReceiptLineResponse{
+			ID:             l.ID,
+			LineID:         l.LineID,
+			ProductID:      l.ProductID,
+			VariantID:      l.VariantID,
+			Quantity:       l.Quantity,
+			UnitCost:       l.UnitCost,
+			LandedUnitCost: l.LandedUnitCost,
+		})
+	}
+	return PurchaseReceiptResponse{
+		ID:              m.ID,
+		PurchaseOrderID: m.PurchaseOrderID,
+		ExtraCost:       m.ExtraCost,
+		Note:            m.Note,
+		Actor:           m.Actor,
+		Lines:           lines,
+		ReceivedAt:      m.ReceivedAt,
+	}
+}
+
+// Stocktake DTOs
+
+// StocktakeRequest opens a stocktake; without warehouse_id the default warehouse is counted.
+type StocktakeRequest struct {
+	WarehouseID *uuid.UUID `json:"warehouse_id"`
+	Note        string     `json:"note"`
+}
+
+type StocktakeResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	WarehouseID uuid.UUID  `json:"warehouse_id"`
+	Status      string     `json:"status"`
+	Note        string     `json:"note"`
+	Actor       string     `json:"actor"`
+	Counted     int        `json:"counted"`
+	StartedAt   time.Time  `json:"started_at"`
+	ClosedAt    *time.Time `json:"closed_at"`
+}
+
+func (r StocktakeRequest) ToModel() model.Stocktake {
+	st := model.Stocktake{Note: r.Note}
+	if r.WarehouseID != nil {
+		st.WarehouseID = *r.WarehouseID
+	}
+	return st
+}
+
+func FromStocktake(m model.Stocktake) StocktakeResponse {
+	return StocktakeResponse{
+		ID:          m.ID,
+		WarehouseID: m.WarehouseID,
+		Status:      m.Status,
+		Note:        m.Note,
+		Actor:       m.Actor,
+		Counted:     m.Counted,
+		StartedAt:   m.StartedAt,
+		ClosedAt:    m.ClosedAt,
+	}
+}
+
+func FromStocktakes(list []model.Stocktake) []StocktakeResponse {
+	result := make([]StocktakeResponse, 0, len(list))
+	for _, st := range list {
+		result = append(result, FromStocktake(st))
+	}
+	return result
+}
+
+// StocktakeCountLine is a counted quantity; counted_at is when the scanner took it, now by default.
+type StocktakeCountLine struct {
+	ProductID uuid.UUID  `json:"product_id"`
+	VariantID *uuid.UUID `json:"variant_id,omitempty"`
+	Quantity  int        `json:"quantity"`
+	CountedAt *time.Time `json:"counted_at"`
+}
+
+// StocktakeCountRequest is one batch of counts uploaded by a scanner.
+type StocktakeCountRequest struct {
+	Lines []StocktakeCountLine `json:"lines"`
+}
+
+func (r StocktakeCountRequest) ToModel() []model.StocktakeCount {
+	counts := make([]model.StocktakeCount, 0, len(r.Lines))
+	for _, l := range r.Lines {
+		c := model.StocktakeCount{ProductID: l.ProductID, VariantID: l.VariantID, Quantity: l.Quantity}
+		if l.CountedAt != nil {
+			c.CountedAt = *l.CountedAt
+		}
+		counts = append(counts, c)
+	}
+	return counts
+}
+
+type StocktakeDiscrepancyResponse struct {
+	ProductID  uuid.UUID  `json:"product_id"`
+	VariantID  *uuid.UUID `json:"variant_id,omitempty"`
+	Counted    int        `json:"counted"`
+	Expected   int        `json:"expected"`
+	Difference int        `json:"difference"`
+	Quantity   int        `json:"quantity"`
+	Adjustment int        `json:"adjustment"`
+	CountedAt  time.Time  `json:"counted_at"`
+}
+
+func FromStocktakeDiscrepancies(list []model.StocktakeDiscrepancy) []StocktakeDiscrepancyResponse {
+	result := make([]StocktakeDiscrepancyResponse, 0, len(list))
+	for _, d := range list {
+		result = append(result, StocktakeDiscrepancyResponse{
+			ProductID:  d.ProductID,
+			VariantID:  d.VariantID,
+			Counted:    d.Counted,
+			Expected:   d.Expected,
+			Difference: d.Difference,
+			Quantity:   d.Quantity,
+			Adjustment: d.Adjustment,
+			CountedAt:  d.CountedAt,
+		})
+	}
+	return result
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"store-service/internal/api/dto"
+	"store-service/internal/logger"
+	"store-service/internal/model"
+	"store-service/internal/repository"
+	"store-service/internal/service"
+)
+
+type exchangeRateHandler struct {
+	svc *service.CurrencyService
+}
+
+func registerExchangeRateRoutes(r chi.Router, svc *service.CurrencyService) {
+	h := &exchangeRateHandler{svc: svc}
+	r.Route("/exchange-rates", func(r chi.Router) {
+		r.Get("/", h.list)
+		r.Post("/", h.create)
+		r.Delete("/{id}", h.delete)
+	})
+}
+
+func (h *exchangeRateHandler) create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	var req dto.ExchangeRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	rate := req.ToModel()
+
+	if err := h.svc.CreateRate(ctx, &rate); err != nil {
+		switch err {
+		case repository.ErrInvalidCurrency, repository.ErrInvalidRate:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		case repository.ErrDuplicateRate:
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		log.Error("failed to create exchange rate", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to create exchange rate")
+		return
+	}
+	writeJSON(w, http.StatusCreated, dto.FromExchangeRate(rate))
+}
+
+func (h *exchangeRateHandler) list(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	base := model.Currency(strings.ToUpper(r.URL.Query().Get("base")))
+	quote := model.Currency(strings.ToUpper(r.URL.Query().Get("quote")))
+
+	rates, err := h.svc.ListRates(ctx, base, quote)
+	if err != nil {
+		if err == repository.ErrInvalidCurrency {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error("failed to list exchange rates", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to list exchange rates")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromExchangeRates(rates))
+}
+
+func (h *exchangeRateHandler) delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid exchange rate id")
+		return
+	}
+
+	if err := h.svc.DeleteRate(ctx, id); err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "exchange rate not found")
+			return
+		}
+		log.Error("failed to delete exchange rate", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to delete exchange rate")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseDisplayCurrency reads the currency to show amounts in from ?currency= or the X-Currency
+// header; an empty result means amounts are returned in their stored currency.
+func parseDisplayCurrency(r *http.Request) (model.Currency, bool) {
+	v := r.URL.Query().Get("currency")
+	if v == "" {
+		v = r.Header.Get("X-Currency")
+	}
+	c := model.Currency(strings.ToUpper(strings.TrimSpace(v)))
+	return c, c == "" || c.Valid()
+}
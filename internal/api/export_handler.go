@@ -0,0 +1,249 @@
+package api
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"store-service/internal/api/dto"
+	"store-service/internal/logger"
+	"store-service/internal/model"
+	"store-service/internal/repository"
+	"store-service/internal/service"
+)
+
+type exportHandler struct {
+	products  *service.ProductService
+	customers *service.CustomerService
+	orders    *service.OrderService
+}
+
+func registerExportRoutes(r chi.Router, products *service.ProductService, customers *service.CustomerService, orders *service.OrderService) {
+	h := &exportHandler{products: products, customers: customers, orders: orders}
+	r.Get("/exports/{entity}", h.export)
+}
+
+var (
+	productExportHeader  = []string{"id", "slug", "sku", "external_id", "name", "price", "currency", "quantity", "available", "incoming", "attributes", "created_at", "updated_at", "archived_at"}
+	customerExportHeader = []string{"id", "name", "email", "phone", "address", "created_at", "updated_at"}
+	// Orders are exported one CSV row per item; an order without items gets a single row with empty item columns.
+	orderExportHeader = []string{"order_id", "customer_id", "status", "currency", "total_price", "created_at", "updated_at",
+		"item_id", "product_id", "variant_id", "product_name", "quantity", "unit_price", "sub_total", "warehouse_id"}
+)
+
+// export streams all products, customers or orders as CSV or NDJSON. Rows are written batch by
+// batch as they are fetched; products accept the filters of the product list (paging is ignored).
+func (h *exportHandler) export(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "ndjson" {
+		writeError(w, http.StatusBadRequest, "format must be csv or ndjson")
+		return
+	}
+
+	entity := chi.URLParam(r, "entity")
+	var err error
+	var out *exportWriter
+	switch entity {
+	case "products":
+		f, ferr := parseProductListFilter(r)
+		if ferr != nil {
+			writeError(w, http.StatusBadRequest, ferr.Error())
+			return
+		}
+		f.Archived = parseBoolQuery(r, "archived")
+		out = newExportWriter(w, entity, format, productExportHeader)
+		err = h.products.Export(ctx, f, func(batch []model.Product) error {
+			for _, p := range batch {
+				if err := out.write(dto.FromProduct(p), productExportRecord(p)); err != nil {
+					return err
+				}
+			}
+			return out.flush()
+		})
+	case "customers":
+		out = newExportWriter(w, entity, format, customerExportHeader)
+		err = h.customers.Export(ctx, func(batch []model.Customer) error {
+			for _, c := range batch {
+				if err := out.write(dto.FromCustomer(c), customerExportRecord(c)); err != nil {
+					return err
+				}
+			}
+			return out.flush()
+		})
+	case "orders":
+		out = newExportWriter(w, entity, format, orderExportHeader)
+		err = h.orders.Export(ctx, func(batch []model.Order) error {
+			for _, o := range batch {
+				if err := out.write(dto.FromOrder(o), orderExportRecords(o)...); err != nil {
+					return err
+				}
+			}
+			return out.flush()
+		})
+	default:
+		writeError(w, http.StatusNotFound, "unknown export entity, expected products, customers or orders")
+		return
+	}
+
+	if err == nil {
+		err = out.flush()
+	}
+	if err != nil {
+		if out.started {
+			// The status is already sent: abort the connection so the client does not take
+			// a truncated dump for a complete one.
+			log.Error("export interrupted", zap.String("entity", entity), zapError(err))
+			panic(http.ErrAbortHandler)
+		}
+		if err == repository.ErrInvalidSort {
+			writeError(w, http.StatusBadRequest, "sort must be one of price, -price, name, -name, created_at, -created_at")
+			return
+		}
+		log.Error("failed to export "+entity, zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to export "+entity)
+	}
+}
+
+// exportWriter encodes rows as CSV or NDJSON. The response status and headers are sent with
+// the first row, so errors before that can still be answered with a regular error response.
+type exportWriter struct {
+	w       http.ResponseWriter
+	entity  string
+	format  string
+	header  []string
+	started bool
+	buf     *bufio.Writer
+	csv     *csv.Writer
+	json    *json.Encoder
+}
+
+func newExportWriter(w http.ResponseWriter, entity, format string, header []string) *exportWriter {
+	return &exportWriter{w: w, entity: entity, format: format, header: header}
+}
+
+func (e *exportWriter) start() error {
+	e.started = true
+	if e.format == "csv" {
+		e.w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	} else {
+		e.w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	e.w.Header().Set("Content-Disposition", `attachment; filename="`+e.entity+"."+e.format+`"`)
+	e.w.WriteHeader(http.StatusOK)
+
+	e.buf = bufio.NewWriter(e.w)
+	if e.format == "csv" {
+		e.csv = csv.NewWriter(e.buf)
+		return e.csv.Write(e.header)
+	}
+	e.json = json.NewEncoder(e.buf)
+	return nil
+}
+
+// write adds one entity: v as an NDJSON line or records as CSV rows.
+func (e *exportWriter) write(v any, records ...[]string) error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+	if e.format == "csv" {
+		for _, rec := range records {
+			if err := e.csv.Write(rec); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return e.json.Encode(v)
+}
+
+// flush sends buffered rows to the client; on an empty export it writes the CSV header.
+func (e *exportWriter) flush() error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := e.buf.Flush(); err != nil {
+		return err
+	}
+	if err := http.NewResponseController(e.w).Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+func productExportRecord(p model.Product) []string {
+	attrs := []byte("{}")
+	if len(p.Attributes) > 0 {
+		attrs, _ = json.Marshal(p.Attributes)
+	}
+	return []string{
+		p.ID.String(), p.Slug, stringValue(p.SKU), stringValue(p.ExternalID), p.Name, p.Price.String(), string(p.Currency),
+		strconv.Itoa(p.Quantity), strconv.Itoa(p.Available), strconv.Itoa(p.Incoming), string(attrs), formatTime(p.CreatedAt), formatTime(p.UpdatedAt), formatOptionalTime(p.ArchivedAt),
+	}
+}
+
+func customerExportRecord(c model.Customer) []string {
+	return []string{c.ID.String(), c.Name, c.Email, c.Phone, c.Address, formatTime(c.CreatedAt), formatTime(c.UpdatedAt)}
+}
+
+func orderExportRecords(o model.Order) [][]string {
+	order := []string{o.ID.String(), o.CustomerID.String(), o.Status, string(o.Currency), o.TotalPrice.String(),
+		formatTime(o.CreatedAt), formatTime(o.UpdatedAt)}
+	if len(o.Items) == 0 {
+		return [][]string{append(order, make([]string, 8)...)}
+	}
+	records := make([][]string, 0, len(o.Items))
+	for _, it := range o.Items {
+		var variantID, warehouseID string
+		if it.VariantID != nil {
+			variantID = it.VariantID.String()
+		}
+		if it.WarehouseID != nil {
+			warehouseID = it.WarehouseID.String()
+		}
+		rec := append([]string{}, order...)
+		records = append(records, append(rec, it.ID.String(), it.ProductID.String(), variantID, it.ProductName,
+			strconv.Itoa(it.Quantity), it.UnitPrice.String(), it.SubTotal.String(), warehouseID))
+	}
+	return records
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return formatTime(*t)
+}
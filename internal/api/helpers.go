@@ -1,19 +1,49 @@
 package api
 
 import (
+	"context"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"store-service/internal/repository"
 )
 
 func parseUUIDParam(r *http.Request, key string) (uuid.UUID, error) {
 	return uuid.Parse(chi.URLParam(r, key))
 }
 
+type pageLimitsKey struct{}
+
+type pageLimits struct {
+	def int
+	max int
+}
+
+// PaginationMiddleware stores default and maximum page sizes for parsePagination; both are
+// kept at least 1 and the default does not exceed the maximum.
+func PaginationMiddleware(defaultLimit, maxLimit int) func(http.Handler) http.Handler {
+	limits := pageLimits{def: max(defaultLimit, 1), max: max(maxLimit, 1)}
+	limits.def = min(limits.def, limits.max)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), pageLimitsKey{}, limits)))
+		})
+	}
+}
+
+// parsePagination reads limit and offset; limit is capped by the configured maximum page size.
 func parsePagination(r *http.Request) (limit, offset int) {
-	limit = 50
+	limits, ok := r.Context().Value(pageLimitsKey{}).(pageLimits)
+	if !ok {
+		limits = pageLimits{def: 50, max: 200}
+	}
+	limit = limits.def
 	offset = 0
 
 	if v := r.URL.Query().Get("limit"); v != "" {
@@ -21,6 +51,9 @@ func parsePagination(r *http.Request) (limit, offset int) {
 			limit = parsed
 		}
 	}
+	if limit > limits.max {
+		limit = limits.max
+	}
 	if v := r.URL.Query().Get("offset"); v != "" {
 		if parsed, err := strconv.Atoi(v); err == nil && parsed >= 0 {
 			offset = parsed
@@ -28,3 +61,83 @@ func parsePagination(r *http.Request) (limit, offset int) {
 	}
 	return
 }
+
+// parsePageRequest reads limit/offset plus the keyset cursor and the with_total flag.
+func parsePageRequest(r *http.Request) repository.PageRequest {
+	limit, offset := parsePagination(r)
+	return repository.PageRequest{
+		Limit:     limit,
+		Offset:    offset,
+		Cursor:    r.URL.Query().Get("cursor"),
+		WithTotal: parseBoolQuery(r, "with_total"),
+	}
+}
+
+// writePageHeaders exposes pagination state: X-Next-Cursor with a Link rel="next", and X-Total-Count when counted.
+func writePageHeaders(w http.ResponseWriter, r *http.Request, page repository.Page) {
+	if page.NextCursor != "" {
+		next := *r.URL
+		q := next.Query()
+		q.Set("cursor", page.NextCursor)
+		q.Del("offset")
+		next.RawQuery = q.Encode()
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+		w.Header().Set("Link", `<`+next.RequestURI()+`>; rel="next"`)
+	}
+	if page.Total != nil {
+		w.Header().Set("X-Total-Count", strconv.FormatInt(*page.Total, 10))
+	}
+}
+
+func parseBoolQuery(r *http.Request, key string) bool {
+	parsed, err := strconv.ParseBool(r.URL.Query().Get(key))
+	return err == nil && parsed
+}
+
+func parseOptionalUUIDQuery(r *http.Request, key string) (*uuid.UUID, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func parseOptionalDecimalQuery(r *http.Request, key string) (*decimal.Decimal, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return nil, nil
+	}
+	d, err := decimal.NewFromString(v)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// parseOptionalTimeQuery accepts RFC3339 timestamps or plain dates (YYYY-MM-DD, UTC midnight).
+func parseOptionalTimeQuery(r *http.Request, key string) (*time.Time, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		if t, err = time.Parse(time.DateOnly, v); err != nil {
+			return nil, err
+		}
+	}
+	return &t, nil
+}
+
+// redirectToSlug permanently redirects a request for an old slug to prefix+slug, keeping the query.
+func redirectToSlug(w http.ResponseWriter, r *http.Request, prefix, slug string) {
+	target := prefix + url.PathEscape(slug)
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, target, http.StatusMovedPermanently)
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"store-service/internal/api/dto"
+	"store-service/internal/logger"
+	"store-service/internal/model"
+	"store-service/internal/repository"
+	"store-service/internal/service"
+)
+
+type importHandler struct {
+	svc *service.ImportService
+}
+
+func registerImportRoutes(r chi.Router, svc *service.ImportService) {
+	h := &importHandler{svc: svc}
+	r.Route("/imports", func(r chi.Router) {
+		r.Post("/products", h.importProducts)
+		r.Get("/{id}", h.get)
+	})
+}
+
+// importProducts accepts multipart/form-data with a CSV or XLSX file in the "file" field and an
+// optional "dry_run" flag (form field or query parameter). Small files are processed within the
+// request; larger ones are queued and answered with 202 and the job location.
+func (h *importHandler) importProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	maxSize := h.svc.MaxFileSize()
+	// Leave room for multipart headers and other form fields.
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+1<<20)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, "file is too large")
+			return
+		}
+		writeError(w, http.StatusBadRequest, "multipart field file is required")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read file")
+		return
+	}
+	if int64(len(data)) > maxSize {
+		writeError(w, http.StatusRequestEntityTooLarge, "file is too large")
+		return
+	}
+
+	dryRun := false
+	if v := r.FormValue("dry_run"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid dry_run")
+			return
+		}
+	}
+
+	job, err := h.svc.ImportProducts(ctx, header.Filename, data, dryRun)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidImportFile) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error("failed to import products", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to import products")
+		return
+	}
+	if job.Status == model.ImportPending {
+		w.Header().Set("Location", "/imports/"+job.ID.String())
+		writeJSON(w, http.StatusAccepted, dto.FromImportJob(job))
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromImportJob(job))
+}
+
+func (h *importHandler) get(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid import id")
+		return
+	}
+
+	job, err := h.svc.Get(ctx, id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "import not found")
+			return
+		}
+		log.Error("failed to get import", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to get import")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromImportJob(job))
+}
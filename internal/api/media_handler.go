@@ -0,0 +1,190 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"store-service/internal/api/dto"
+	"store-service/internal/logger"
+	"store-service/internal/repository"
+	"store-service/internal/service"
+)
+
+type mediaHandler struct {
+	svc *service.MediaService
+}
+
+// upload accepts multipart/form-data with the image in the "file" field and an optional "is_primary" flag.
+func (h *mediaHandler) upload(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	maxSize := h.svc.MaxUploadSize()
+	// Leave room for multipart headers and other form fields.
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+1<<20)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, "file is too large")
+			return
+		}
+		writeError(w, http.StatusBadRequest, "multipart field file is required")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read file")
+		return
+	}
+	if int64(len(data)) > maxSize {
+		writeError(w, http.StatusRequestEntityTooLarge, "file is too large")
+		return
+	}
+	isPrimary, _ := strconv.ParseBool(r.FormValue("is_primary"))
+
+	m, err := h.svc.Upload(ctx, productID, data, isPrimary)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		case repository.ErrUnsupportedMedia:
+			writeError(w, http.StatusUnsupportedMediaType, err.Error())
+			return
+		case repository.ErrImageTooLarge:
+			writeError(w, http.StatusRequestEntityTooLarge, err.Error())
+			return
+		}
+		log.Error("failed to upload product media", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to upload product media")
+		return
+	}
+	writeJSON(w, http.StatusCreated, dto.FromMedia(m))
+}
+
+func (h *mediaHandler) list(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	media, err := h.svc.List(ctx, productID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		log.Error("failed to list product media", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to list product media")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromMediaList(media))
+}
+
+func (h *mediaHandler) reorder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	var req dto.MediaReorderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	media, err := h.svc.Reorder(ctx, productID, req.IDs)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		case repository.ErrInvalidOrder:
+			writeError(w, http.StatusBadRequest, "ids must list every media of the product exactly once")
+			return
+		}
+		log.Error("failed to reorder product media", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to reorder product media")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromMediaList(media))
+}
+
+func (h *mediaHandler) setPrimary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, mediaID, ok := parseMediaParams(w, r)
+	if !ok {
+		return
+	}
+
+	m, err := h.svc.SetPrimary(ctx, productID, mediaID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "media not found")
+			return
+		}
+		log.Error("failed to set primary media", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to set primary media")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromMedia(m))
+}
+
+func (h *mediaHandler) delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, mediaID, ok := parseMediaParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Delete(ctx, productID, mediaID); err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "media not found")
+			return
+		}
+		log.Error("failed to delete product media", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to delete product media")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func parseMediaParams(w http.ResponseWriter, r *http.Request) (productID, mediaID uuid.UUID, ok bool) {
+	productID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return productID, mediaID, false
+	}
+	mediaID, err = parseUUIDParam(r, "mediaID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid media id")
+		return productID, mediaID, false
+	}
+	return productID, mediaID, true
+}
@@ -1,12 +1,14 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"go.uber.org/zap"
 
+	"store-service/internal/actor"
 	appLogger "store-service/internal/logger"
 )
 
@@ -34,3 +36,24 @@ func LoggerMiddleware(base *zap.Logger) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// ActorMiddleware stores the X-Actor header (or "api") and the request ID as the actor recorded in
+// audit trails such as stock movements. The header is not authenticated, so only short names of
+// safe characters are accepted. Reads record nothing: there a malformed header is ignored, so it
+// cannot break health checks or GET requests.
+func ActorMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.Header.Get("X-Actor")
+		readOnly := r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions
+		if name == "" || (readOnly && !actor.Valid(name)) {
+			name = "api"
+		}
+		if !actor.Valid(name) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("X-Actor must be up to %d letters, digits or . _ - @ : characters", actor.MaxLength))
+			return
+		}
+		ctx := actor.WithContext(r.Context(), name)
+		ctx = actor.WithRequestID(ctx, middleware.GetReqID(r.Context()))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
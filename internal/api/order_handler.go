@@ -9,16 +9,19 @@ import (
 
 	"store-service/internal/api/dto"
 	"store-service/internal/logger"
+	"store-service/internal/model"
 	"store-service/internal/repository"
 	"store-service/internal/service"
 )
 
 type orderHandler struct {
-	svc *service.OrderService
+	svc        *service.OrderService
+	currencies *service.CurrencyService
 }
 
-func registerOrderRoutes(r chi.Router, svc *service.OrderService) {
-	h := &orderHandler{svc: svc}
+func registerOrderRoutes(r chi.Router, svc *service.OrderService, currencies *service.CurrencyService, recommendations *service.RecommendationService) {
+	h := &orderHandler{svc: svc, currencies: currencies}
+	rec := &recommendationHandler{svc: recommendations, currencies: currencies}
 	r.Route("/orders", func(r chi.Router) {
 		r.Get("/", h.list)
 		r.Post("/", h.create)
@@ -26,6 +29,7 @@ func registerOrderRoutes(r chi.Router, svc *service.OrderService) {
 		r.Put("/{id}", h.updateStatus)
 		r.Delete("/{id}", h.delete)
 		r.Post("/{id}/items", h.addItem)
+		r.Get("/{id}/suggestions", rec.suggestions)
 	})
 }
 
@@ -42,6 +46,10 @@ func (h *orderHandler) create(w http.ResponseWriter, r *http.Request) {
 	o := req.ToModel(uuid.Nil)
 
 	if err := h.svc.Create(ctx, &o); err != nil {
+		if err == repository.ErrInvalidCurrency || err == repository.ErrInvalidShippingPoint {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		log.Error("failed to create order", zapError(err))
 		writeError(w, http.StatusInternalServerError, "failed to create order")
 		return
@@ -58,6 +66,11 @@ func (h *orderHandler) get(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid order id")
 		return
 	}
+	display, ok := parseDisplayCurrency(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, repository.ErrInvalidCurrency.Error())
+		return
+	}
 
 	o, err := h.svc.Get(ctx, id)
 	if err != nil {
@@ -69,20 +82,51 @@ func (h *orderHandler) get(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to get order")
 		return
 	}
+	if err := h.currencies.ConvertOrders(ctx, display, &o); err != nil {
+		if err == repository.ErrRateNotFound {
+			writeError(w, http.StatusConflict, "no exchange rate to "+string(display))
+			return
+		}
+		log.Error("failed to convert order amounts", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to convert order amounts")
+		return
+	}
 	writeJSON(w, http.StatusOK, dto.FromOrder(o))
 }
 
 func (h *orderHandler) list(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	log := logger.FromContext(ctx)
-	limit, offset := parsePagination(r)
+	display, ok := parseDisplayCurrency(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, repository.ErrInvalidCurrency.Error())
+		return
+	}
 
-	orders, err := h.svc.List(ctx, limit, offset)
+	orders, page, err := h.svc.List(ctx, parsePageRequest(r))
 	if err != nil {
+		if err == repository.ErrInvalidCursor {
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
 		log.Error("failed to list orders", zapError(err))
 		writeError(w, http.StatusInternalServerError, "failed to list orders")
 		return
 	}
+	list := make([]*model.Order, 0, len(orders))
+	for i := range orders {
+		list = append(list, &orders[i])
+	}
+	if err := h.currencies.ConvertOrders(ctx, display, list...); err != nil {
+		if err == repository.ErrRateNotFound {
+			writeError(w, http.StatusConflict, "no exchange rate to "+string(display))
+			return
+		}
+		log.Error("failed to convert order amounts", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to convert order amounts")
+		return
+	}
+	writePageHeaders(w, r, page)
 	writeJSON(w, http.StatusOK, dto.FromOrders(orders))
 }
 
@@ -103,9 +147,19 @@ func (h *orderHandler) updateStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.svc.UpdateStatus(ctx, id, req.Status); err != nil {
-		if err == repository.ErrNotFound {
+		switch err {
+		case repository.ErrNotFound:
 			writeError(w, http.StatusNotFound, "order not found")
 			return
+		case repository.ErrInvalidOrderStatus:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		case repository.ErrNotEnoughStock:
+			writeError(w, http.StatusConflict, "not enough stock to fulfil the order")
+			return
+		case repository.ErrInvalidStatusTransition:
+			writeError(w, http.StatusConflict, err.Error())
+			return
 		}
 		log.Error("failed to update order", zapError(err))
 		writeError(w, http.StatusInternalServerError, "failed to update order")
@@ -156,15 +210,24 @@ func (h *orderHandler) addItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	item, err := h.svc.AddProductToOrder(ctx, orderID, req.ProductID, req.Quantity)
+	item, err := h.svc.AddProductToOrder(ctx, orderID, req.ProductID, req.VariantID, req.Quantity)
 	if err != nil {
 		switch err {
 		case repository.ErrNotFound:
-			writeError(w, http.StatusNotFound, "order or product not found")
+			writeError(w, http.StatusNotFound, "order, product or variant not found")
+			return
+		case repository.ErrVariantRequired, repository.ErrProductArchived:
+			writeError(w, http.StatusBadRequest, err.Error())
 			return
 		case repository.ErrNotEnoughStock:
 			writeError(w, http.StatusBadRequest, "not enough stock")
 			return
+		case repository.ErrRateNotFound:
+			writeError(w, http.StatusConflict, "no exchange rate from the product currency to the order currency")
+			return
+		case repository.ErrOrderNotOpen:
+			writeError(w, http.StatusConflict, err.Error())
+			return
 		default:
 			log.Error("failed to add item to order", zapError(err))
 			writeError(w, http.StatusInternalServerError, "failed to add item to order")
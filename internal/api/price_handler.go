@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"store-service/internal/api/dto"
+	"store-service/internal/logger"
+	"store-service/internal/repository"
+	"store-service/internal/service"
+)
+
+type priceHandler struct {
+	svc *service.PriceService
+}
+
+func (h *priceHandler) history(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	prices, err := h.svc.History(ctx, productID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		log.Error("failed to get price history", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to get price history")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromPrices(prices))
+}
+
+func (h *priceHandler) schedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	var req dto.ScheduledPriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Price.IsNegative() {
+		writeError(w, http.StatusBadRequest, "price must not be negative")
+		return
+	}
+
+	p := req.ToModel(productID)
+
+	if err := h.svc.Schedule(ctx, &p); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		case repository.ErrPriceNotInFuture:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error("failed to schedule price", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to schedule price")
+		return
+	}
+	writeJSON(w, http.StatusCreated, dto.FromPrice(p))
+}
+
+func (h *priceHandler) cancel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+	priceID, err := parseUUIDParam(r, "priceID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid price id")
+		return
+	}
+
+	if err := h.svc.CancelScheduled(ctx, productID, priceID); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "price not found")
+			return
+		case repository.ErrPriceApplied:
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		log.Error("failed to cancel scheduled price", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to cancel scheduled price")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
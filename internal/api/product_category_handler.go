@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"store-service/internal/api/dto"
+	"store-service/internal/logger"
+	"store-service/internal/repository"
+	"store-service/internal/service"
+)
+
+type productCategoryHandler struct {
+	svc        *service.ProductCategoryService
+	currencies *service.CurrencyService
+}
+
+func (h *productCategoryHandler) replaceCategories(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	var req dto.ProductCategoriesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	categories, err := h.svc.ReplaceCategories(ctx, productID, req.CategoryIDs)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "product or category not found")
+			return
+		}
+		log.Error("failed to replace product categories", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to replace product categories")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromCategories(categories))
+}
+
+func (h *productCategoryHandler) listCategories(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	categories, err := h.svc.ListCategories(ctx, productID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		log.Error("failed to list product categories", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to list product categories")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromCategories(categories))
+}
+
+func (h *productCategoryHandler) listProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	categoryID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid category id")
+		return
+	}
+	limit, offset := parsePagination(r)
+	display, ok := parseDisplayCurrency(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, repository.ErrInvalidCurrency.Error())
+		return
+	}
+
+	products, err := h.svc.ListProducts(ctx, categoryID, limit, offset)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "category not found")
+			return
+		}
+		log.Error("failed to list category products", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to list category products")
+		return
+	}
+	if err := h.currencies.ConvertProducts(ctx, display, productPointers(products)...); err != nil {
+		if err == repository.ErrRateNotFound {
+			writeError(w, http.StatusConflict, "no exchange rate to "+string(display))
+			return
+		}
+		log.Error("failed to convert product prices", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to convert product prices")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromProducts(products))
+}
@@ -2,29 +2,73 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 
 	"store-service/internal/api/dto"
 	"store-service/internal/logger"
+	"store-service/internal/model"
 	"store-service/internal/repository"
 	"store-service/internal/service"
 )
 
 type productHandler struct {
-	svc *service.ProductService
+	svc        *service.ProductService
+	currencies *service.CurrencyService
 }
 
-func registerProductRoutes(r chi.Router, svc *service.ProductService) {
-	h := &productHandler{svc: svc}
+func registerProductRoutes(r chi.Router, svc *service.ProductService, links *service.ProductCategoryService, variants *service.VariantService, attrs *service.AttributeService, media *service.MediaService, prices *service.PriceService, currencies *service.CurrencyService,
+	recommendations *service.RecommendationService, stock *service.StockService, warehouses *service.WarehouseService) {
+	h := &productHandler{svc: svc, currencies: currencies}
+	l := &productCategoryHandler{svc: links, currencies: currencies}
+	v := &variantHandler{svc: variants}
+	a := &attributeHandler{svc: attrs}
+	m := &mediaHandler{svc: media}
+	pr := &priceHandler{svc: prices}
+	rec := &recommendationHandler{svc: recommendations, currencies: currencies}
+	st := &stockHandler{svc: stock}
+	wh := &warehouseHandler{svc: warehouses}
 	r.Route("/products", func(r chi.Router) {
 		r.Get("/", h.list)
 		r.Post("/", h.create)
+		r.Get("/search", h.search)
+		r.Get("/facets", h.facets)
+		r.Get("/archived", h.listArchived)
+		r.Get("/by-slug/{slug}", h.getBySlug)
 		r.Get("/{id}", h.get)
 		r.Put("/{id}", h.update)
 		r.Delete("/{id}", h.delete)
+		r.Post("/{id}/restore", h.restore)
+		r.Get("/{id}/related", rec.related)
+		r.Get("/{id}/categories", l.listCategories)
+		r.Put("/{id}/categories", l.replaceCategories)
+		r.Put("/{id}/attributes", a.replaceForProduct)
+		r.Get("/{id}/options", v.listOptions)
+		r.Put("/{id}/options", v.replaceOptions)
+		r.Get("/{id}/variants", v.list)
+		r.Post("/{id}/variants", v.create)
+		r.Get("/{id}/variants/{variantID}", v.get)
+		r.Put("/{id}/variants/{variantID}", v.update)
+		r.Delete("/{id}/variants/{variantID}", v.delete)
+		r.Get("/{id}/media", m.list)
+		r.Post("/{id}/media", m.upload)
+		r.Put("/{id}/media/order", m.reorder)
+		r.Post("/{id}/media/{mediaID}/primary", m.setPrimary)
+		r.Delete("/{id}/media/{mediaID}", m.delete)
+		r.Get("/{id}/price-history", pr.history)
+		r.Post("/{id}/prices", pr.schedule)
+		r.Delete("/{id}/prices/{priceID}", pr.cancel)
+		r.Get("/{id}/stock", wh.productStock)
+		r.Get("/{id}/stock-movements", st.movements)
+		r.Get("/{id}/reorder-point", st.reorderPoint)
+		r.Put("/{id}/reorder-point", st.setReorderPoint)
 	})
 }
 
@@ -41,6 +85,17 @@ func (h *productHandler) create(w http.ResponseWriter, r *http.Request) {
 	p := req.ToModel(uuid.Nil)
 
 	if err := h.svc.Create(ctx, &p); err != nil {
+		switch err {
+		case repository.ErrInvalidCurrency, repository.ErrInvalidSlug:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		case repository.ErrDuplicateProductKey, repository.ErrNoDefaultWarehouse:
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		case repository.ErrNotEnoughStock:
+			writeError(w, http.StatusConflict, "quantity change exceeds the stock of the default warehouse")
+			return
+		}
 		log.Error("failed to create product", zapError(err))
 		writeError(w, http.StatusInternalServerError, "failed to create product")
 		return
@@ -57,6 +112,11 @@ func (h *productHandler) get(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid product id")
 		return
 	}
+	display, ok := parseDisplayCurrency(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, repository.ErrInvalidCurrency.Error())
+		return
+	}
 
 	p, err := h.svc.Get(ctx, id)
 	if err != nil {
@@ -68,6 +128,49 @@ func (h *productHandler) get(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to get product")
 		return
 	}
+	h.writeProduct(w, r, p, display)
+}
+
+// getBySlug returns the product by slug; an old slug of a renamed product redirects to the current one.
+func (h *productHandler) getBySlug(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	display, ok := parseDisplayCurrency(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, repository.ErrInvalidCurrency.Error())
+		return
+	}
+
+	p, moved, err := h.svc.GetBySlug(ctx, chi.URLParam(r, "slug"))
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		log.Error("failed to get product by slug", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to get product")
+		return
+	}
+	if moved {
+		redirectToSlug(w, r, "/products/by-slug/", p.Slug)
+		return
+	}
+	h.writeProduct(w, r, p, display)
+}
+
+// writeProduct responds with the product converted to the display currency.
+func (h *productHandler) writeProduct(w http.ResponseWriter, r *http.Request, p model.Product, display model.Currency) {
+	ctx := r.Context()
+	if err := h.currencies.ConvertProducts(ctx, display, &p); err != nil {
+		if err == repository.ErrRateNotFound {
+			writeError(w, http.StatusConflict, "no exchange rate to "+string(display))
+			return
+		}
+		logger.FromContext(ctx).Error("failed to convert product price", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to convert product price")
+		return
+	}
 	writeJSON(w, http.StatusOK, dto.FromProduct(p))
 }
 
@@ -90,9 +193,19 @@ func (h *productHandler) update(w http.ResponseWriter, r *http.Request) {
 	p := req.ToModel(id)
 
 	if err := h.svc.Update(ctx, &p); err != nil {
-		if err == repository.ErrNotFound {
+		switch err {
+		case repository.ErrNotFound:
 			writeError(w, http.StatusNotFound, "product not found")
 			return
+		case repository.ErrInvalidCurrency, repository.ErrInvalidSlug:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		case repository.ErrDuplicateProductKey, repository.ErrNoDefaultWarehouse:
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		case repository.ErrNotEnoughStock:
+			writeError(w, http.StatusConflict, "quantity change exceeds the stock of the default warehouse")
+			return
 		}
 		log.Error("failed to update product", zapError(err))
 		writeError(w, http.StatusInternalServerError, "failed to update product")
@@ -101,6 +214,7 @@ func (h *productHandler) update(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, dto.FromProduct(p))
 }
 
+// delete archives the product; it stays available to order history and can be restored.
 func (h *productHandler) delete(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	log := logger.FromContext(ctx)
@@ -111,28 +225,258 @@ func (h *productHandler) delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.svc.Delete(ctx, id); err != nil {
+	if err := h.svc.Archive(ctx, id); err != nil {
 		if err == repository.ErrNotFound {
 			writeError(w, http.StatusNotFound, "product not found")
 			return
 		}
-		log.Error("failed to delete product", zapError(err))
-		writeError(w, http.StatusInternalServerError, "failed to delete product")
+		log.Error("failed to archive product", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to archive product")
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *productHandler) restore(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	p, err := h.svc.Restore(ctx, id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "archived product not found")
+			return
+		}
+		log.Error("failed to restore product", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to restore product")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromProduct(p))
+}
+
 func (h *productHandler) list(w http.ResponseWriter, r *http.Request) {
+	h.listProducts(w, r, false)
+}
+
+// listArchived is the admin listing of archived products; it accepts the same filters as list.
+func (h *productHandler) listArchived(w http.ResponseWriter, r *http.Request) {
+	h.listProducts(w, r, true)
+}
+
+func (h *productHandler) listProducts(w http.ResponseWriter, r *http.Request, archived bool) {
 	ctx := r.Context()
 	log := logger.FromContext(ctx)
-	limit, offset := parsePagination(r)
+	f, err := parseProductListFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	f.Archived = archived
+	display, ok := parseDisplayCurrency(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, repository.ErrInvalidCurrency.Error())
+		return
+	}
 
-	products, err := h.svc.List(ctx, limit, offset)
+	products, page, err := h.svc.List(ctx, f)
 	if err != nil {
+		switch err {
+		case repository.ErrInvalidSort:
+			writeError(w, http.StatusBadRequest, "sort must be one of price, -price, name, -name, created_at, -created_at")
+			return
+		case repository.ErrInvalidCursor:
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
 		log.Error("failed to list products", zapError(err))
 		writeError(w, http.StatusInternalServerError, "failed to list products")
 		return
 	}
+	if err := h.currencies.ConvertProducts(ctx, display, productPointers(products)...); err != nil {
+		if err == repository.ErrRateNotFound {
+			writeError(w, http.StatusConflict, "no exchange rate to "+string(display))
+			return
+		}
+		log.Error("failed to convert product prices", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to convert product prices")
+		return
+	}
+	writePageHeaders(w, r, page)
 	writeJSON(w, http.StatusOK, dto.FromProducts(products))
 }
+
+func (h *productHandler) search(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		writeError(w, http.StatusBadRequest, "query parameter q is required")
+		return
+	}
+	categoryID, err := parseOptionalUUIDQuery(r, "category_id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid category id")
+		return
+	}
+	limit, offset := parsePagination(r)
+	display, ok := parseDisplayCurrency(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, repository.ErrInvalidCurrency.Error())
+		return
+	}
+
+	hits, err := h.svc.Search(ctx, repository.ProductSearchFilter{
+		Query:      q,
+		CategoryID: categoryID,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		log.Error("failed to search products", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to search products")
+		return
+	}
+	products := make([]*model.Product, 0, len(hits))
+	for i := range hits {
+		products = append(products, &hits[i].Product)
+	}
+	if err := h.currencies.ConvertProducts(ctx, display, products...); err != nil {
+		if err == repository.ErrRateNotFound {
+			writeError(w, http.StatusConflict, "no exchange rate to "+string(display))
+			return
+		}
+		log.Error("failed to convert product prices", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to convert product prices")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromProductSearchHits(hits))
+}
+
+// facets returns attribute value counts for products matching the same filters as list.
+func (h *productHandler) facets(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+	f, err := parseProductListFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	facets, err := h.svc.Facets(ctx, f)
+	if err != nil {
+		log.Error("failed to get product facets", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to get product facets")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromFacets(facets))
+}
+
+func parseProductListFilter(r *http.Request) (repository.ProductListFilter, error) {
+	var f repository.ProductListFilter
+	var err error
+
+	f.PageRequest = parsePageRequest(r)
+	f.Sort = r.URL.Query().Get("sort")
+	f.InStock = parseBoolQuery(r, "in_stock")
+
+	if f.MinPrice, err = parseOptionalDecimalQuery(r, "min_price"); err != nil {
+		return f, errors.New("invalid min_price")
+	}
+	if f.MaxPrice, err = parseOptionalDecimalQuery(r, "max_price"); err != nil {
+		return f, errors.New("invalid max_price")
+	}
+	if f.CategoryID, err = parseOptionalUUIDQuery(r, "category_id"); err != nil {
+		return f, errors.New("invalid category id")
+	}
+	for _, p := range []struct {
+		key string
+		dst **time.Time
+	}{
+		{"created_from", &f.CreatedFrom},
+		{"created_to", &f.CreatedTo},
+		{"updated_from", &f.UpdatedFrom},
+		{"updated_to", &f.UpdatedTo},
+	} {
+		if *p.dst, err = parseOptionalTimeQuery(r, p.key); err != nil {
+			return f, errors.New("invalid " + p.key)
+		}
+	}
+	if f.Attributes, err = parseAttributeFilters(r); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// parseAttributeFilters reads attr.<code>=v1,v2 (any of the values) and
+// attr.<code>.min / attr.<code>.max (numeric range) query parameters.
+func parseAttributeFilters(r *http.Request) ([]repository.AttributeFilter, error) {
+	byCode := map[string]*repository.AttributeFilter{}
+	var codes []string
+	get := func(code string) *repository.AttributeFilter {
+		if a, ok := byCode[code]; ok {
+			return a
+		}
+		a := &repository.AttributeFilter{Code: code}
+		byCode[code] = a
+		codes = append(codes, code)
+		return a
+	}
+
+	query := r.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		code, ok := strings.CutPrefix(key, "attr.")
+		if !ok || code == "" {
+			continue
+		}
+		value := query.Get(key)
+		if base, ok := strings.CutSuffix(code, ".min"); ok {
+			d, err := decimal.NewFromString(value)
+			if err != nil {
+				return nil, errors.New("invalid " + key)
+			}
+			get(base).Min = &d
+			continue
+		}
+		if base, ok := strings.CutSuffix(code, ".max"); ok {
+			d, err := decimal.NewFromString(value)
+			if err != nil {
+				return nil, errors.New("invalid " + key)
+			}
+			get(base).Max = &d
+			continue
+		}
+		a := get(code)
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				a.Values = append(a.Values, v)
+			}
+		}
+	}
+
+	result := make([]repository.AttributeFilter, 0, len(codes))
+	for _, code := range codes {
+		result = append(result, *byCode[code])
+	}
+	return result, nil
+}
+
+func productPointers(list []model.Product) []*model.Product {
+	result := make([]*model.Product, 0, len(list))
+	for i := range list {
+		result = append(result, &list[i])
+	}
+	return result
+}
@@ -0,0 +1,317 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"store-service/internal/api/dto"
+	"store-service/internal/logger"
+	"store-service/internal/repository"
+	"store-service/internal/service"
+)
+
+type purchaseHandler struct {
+	suppliers *service.SupplierService
+	orders    *service.PurchaseOrderService
+}
+
+func registerPurchaseRoutes(r chi.Router, suppliers *service.SupplierService, orders *service.PurchaseOrderService) {
+	h := &purchaseHandler{suppliers: suppliers, orders: orders}
+	r.Route("/suppliers", func(r chi.Router) {
+		r.Get("/", h.listSuppliers)
+		r.Post("/", h.createSupplier)
+		r.Get("/{id}", h.getSupplier)
+		r.Put("/{id}", h.updateSupplier)
+		r.Delete("/{id}", h.deleteSupplier)
+	})
+	r.Route("/purchase-orders", func(r chi.Router) {
+		r.Get("/", h.listOrders)
+		r.Post("/", h.createOrder)
+		r.Get("/{id}", h.getOrder)
+		r.Post("/{id}/receive", h.receive)
+		r.Post("/{id}/cancel", h.cancel)
+	})
+}
+
+func (h *purchaseHandler) createSupplier(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	var req dto.SupplierRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	s := req.ToModel(uuid.Nil)
+
+	if err := h.suppliers.Create(ctx, &s); err != nil {
+		if err == repository.ErrInvalidSupplier {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error("failed to create supplier", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to create supplier")
+		return
+	}
+	writeJSON(w, http.StatusCreated, dto.FromSupplier(s))
+}
+
+func (h *purchaseHandler) getSupplier(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid supplier id")
+		return
+	}
+
+	s, err := h.suppliers.Get(ctx, id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "supplier not found")
+			return
+		}
+		log.Error("failed to get supplier", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to get supplier")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromSupplier(s))
+}
+
+func (h *purchaseHandler) updateSupplier(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid supplier id")
+		return
+	}
+
+	var req dto.SupplierRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	s := req.ToModel(id)
+
+	if err := h.suppliers.Update(ctx, &s); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "supplier not found")
+			return
+		case repository.ErrInvalidSupplier:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error("failed to update supplier", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to update supplier")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromSupplier(s))
+}
+
+func (h *purchaseHandler) deleteSupplier(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid supplier id")
+		return
+	}
+
+	if err := h.suppliers.Delete(ctx, id); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "supplier not found")
+			return
+		case repository.ErrSupplierInUse:
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		log.Error("failed to delete supplier", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to delete supplier")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *purchaseHandler) listSuppliers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	list, page, err := h.suppliers.List(ctx, parsePageRequest(r))
+	if err != nil {
+		if err == repository.ErrInvalidCursor {
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		log.Error("failed to list suppliers", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to list suppliers")
+		return
+	}
+	writePageHeaders(w, r, page)
+	writeJSON(w, http.StatusOK, dto.FromSuppliers(list))
+}
+
+func (h *purchaseHandler) createOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	var req dto.PurchaseOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	po := req.ToModel()
+
+	if err := h.orders.Create(ctx, &po); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "supplier, warehouse, product or variant not found")
+			return
+		case repository.ErrInvalidCurrency, repository.ErrInvalidPurchaseOrder, repository.ErrVariantRequired:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error("failed to create purchase order", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to create purchase order")
+		return
+	}
+	writeJSON(w, http.StatusCreated, dto.FromPurchaseOrder(po))
+}
+
+func (h *purchaseHandler) getOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid purchase order id")
+		return
+	}
+
+	po, err := h.orders.Get(ctx, id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "purchase order not found")
+			return
+		}
+		log.Error("failed to get purchase order", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to get purchase order")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromPurchaseOrder(po))
+}
+
+func (h *purchaseHandler) listOrders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	supplierID, err := parseOptionalUUIDQuery(r, "supplier_id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid supplier_id")
+		return
+	}
+	warehouseID, err := parseOptionalUUIDQuery(r, "warehouse_id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid warehouse_id")
+		return
+	}
+	f := repository.PurchaseOrderFilter{
+		Status:      r.URL.Query().Get("status"),
+		SupplierID:  supplierID,
+		WarehouseID: warehouseID,
+	}
+
+	list, page, err := h.orders.List(ctx, f, parsePageRequest(r))
+	if err != nil {
+		switch err {
+		case repository.ErrInvalidCursor:
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		case repository.ErrInvalidPurchaseOrderStatus:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error("failed to list purchase orders", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to list purchase orders")
+		return
+	}
+	writePageHeaders(w, r, page)
+	writeJSON(w, http.StatusOK, dto.FromPurchaseOrders(list))
+}
+
+// receive books a full or partial delivery of the purchase order into its warehouse.
+func (h *purchaseHandler) receive(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid purchase order id")
+		return
+	}
+
+	var req dto.PurchaseReceiptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	rc := req.ToModel(id)
+
+	if err := h.orders.Receive(ctx, &rc); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "purchase order or line not found")
+			return
+		case repository.ErrInvalidReceipt:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		case repository.ErrPurchaseOrderClosed, repository.ErrOverReceipt:
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		log.Error("failed to receive purchase order", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to receive purchase order")
+		return
+	}
+	writeJSON(w, http.StatusCreated, dto.FromPurchaseReceipt(rc))
+}
+
+// cancel closes the purchase order; goods not received yet are no longer expected.
+func (h *purchaseHandler) cancel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid purchase order id")
+		return
+	}
+
+	po, err := h.orders.Cancel(ctx, id)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "purchase order not found")
+			return
+		case repository.ErrPurchaseOrderClosed:
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		log.Error("failed to cancel purchase order", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to cancel purchase order")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromPurchaseOrder(po))
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"store-service/internal/api/dto"
+	"store-service/internal/logger"
+	"store-service/internal/model"
+	"store-service/internal/repository"
+	"store-service/internal/service"
+)
+
+const (
+	defaultRecommendationLimit = 10
+	maxRecommendationLimit     = 50
+)
+
+type recommendationHandler struct {
+	svc        *service.RecommendationService
+	currencies *service.CurrencyService
+}
+
+// related serves GET /products/{id}/related.
+func (h *recommendationHandler) related(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+	display, ok := parseDisplayCurrency(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, repository.ErrInvalidCurrency.Error())
+		return
+	}
+
+	recs, err := h.svc.Related(ctx, productID, parseRecommendationLimit(r))
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		log.Error("failed to get related products", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to get related products")
+		return
+	}
+	h.writeRecommendations(w, r, recs, display)
+}
+
+// suggestions serves GET /orders/{id}/suggestions.
+func (h *recommendationHandler) suggestions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	orderID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid order id")
+		return
+	}
+	display, ok := parseDisplayCurrency(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, repository.ErrInvalidCurrency.Error())
+		return
+	}
+
+	recs, err := h.svc.Suggestions(ctx, orderID, parseRecommendationLimit(r))
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "order not found")
+			return
+		}
+		log.Error("failed to get order suggestions", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to get order suggestions")
+		return
+	}
+	h.writeRecommendations(w, r, recs, display)
+}
+
+func (h *recommendationHandler) writeRecommendations(w http.ResponseWriter, r *http.Request, recs []model.ProductRecommendation, display model.Currency) {
+	ctx := r.Context()
+	products := make([]*model.Product, 0, len(recs))
+	for i := range recs {
+		products = append(products, &recs[i].Product)
+	}
+	if err := h.currencies.ConvertProducts(ctx, display, products...); err != nil {
+		if err == repository.ErrRateNotFound {
+			writeError(w, http.StatusConflict, "no exchange rate to "+string(display))
+			return
+		}
+		logger.FromContext(ctx).Error("failed to convert product prices", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to convert product prices")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromRecommendations(recs))
+}
+
+// parseRecommendationLimit reads ?limit=, defaulting to 10 and capped at 50.
+func parseRecommendationLimit(r *http.Request) int {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		return defaultRecommendationLimit
+	}
+	return min(limit, maxRecommendationLimit)
+}
@@ -2,11 +2,14 @@ package api
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 
 	"store-service/internal/api/dto"
 	"store-service/internal/logger"
+	"store-service/internal/model"
+	"store-service/internal/repository"
 	"store-service/internal/service"
 )
 
@@ -20,6 +23,7 @@ func registerReportRoutes(r chi.Router, svc *service.ReportService) {
 		r.Get("/customer-totals", h.customerTotals)
 		r.Get("/category-children", h.categoryChildren)
 		r.Get("/top-products-last-month", h.topProductsLastMonth)
+		r.Get("/low-stock", h.lowStock)
 	})
 }
 
@@ -27,8 +31,16 @@ func (h *reportHandler) customerTotals(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	log := logger.FromContext(ctx)
 
-	data, err := h.svc.CustomerTotals(ctx)
+	data, err := h.svc.CustomerTotals(ctx, model.Currency(strings.ToUpper(r.URL.Query().Get("currency"))))
 	if err != nil {
+		switch err {
+		case repository.ErrInvalidCurrency:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		case repository.ErrRateNotFound:
+			writeError(w, http.StatusConflict, "exchange rates are missing for some order currencies")
+			return
+		}
 		log.Error("failed to fetch customer totals", zapError(err))
 		writeError(w, http.StatusInternalServerError, "failed to fetch customer totals")
 		return
@@ -61,3 +73,17 @@ func (h *reportHandler) topProductsLastMonth(w http.ResponseWriter, r *http.Requ
 	}
 	writeJSON(w, http.StatusOK, dto.FromTopProducts(data))
 }
+
+// lowStock lists products whose available stock reached their reorder point.
+func (h *reportHandler) lowStock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	data, err := h.svc.LowStock(ctx)
+	if err != nil {
+		log.Error("failed to fetch low stock products", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to fetch low stock products")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromLowStock(data))
+}
@@ -2,33 +2,80 @@ package api
 
 import (
 	"net/http"
+	"os"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"go.uber.org/zap"
 
+	"store-service/internal/config"
 	"store-service/internal/service"
 )
 
-func NewRouter(log *zap.Logger, services *service.Services) *chi.Mux {
+func NewRouter(log *zap.Logger, services *service.Services, cfg config.HTTP, media config.Media) *chi.Mux {
 	r := chi.NewRouter()
 
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Recoverer)
 	r.Use(LoggerMiddleware(log))
+	r.Use(ActorMiddleware)
+	r.Use(PaginationMiddleware(cfg.DefaultPageSize, cfg.MaxPageSize))
 
 	r.Get("/healthz", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok"))
 	})
 
-	registerCategoryRoutes(r, services.Categories)
+	registerCategoryRoutes(r, services.Categories, services.ProductCategories, services.Attributes, services.Currencies,
+		services.Stock)
 	registerCustomerRoutes(r, services.Customers)
-	registerProductRoutes(r, services.Products)
-	registerOrderRoutes(r, services.Orders)
+	registerProductRoutes(r, services.Products, services.ProductCategories, services.Variants, services.Attributes, services.Media, services.Prices, services.Currencies,
+		services.Recommendations, services.Stock, services.Warehouses)
+	registerOrderRoutes(r, services.Orders, services.Currencies, services.Recommendations)
+	registerAttributeRoutes(r, services.Attributes)
 	registerReportRoutes(r, services.Reports)
+	registerExchangeRateRoutes(r, services.Currencies)
+	registerImportRoutes(r, services.Imports)
+	registerExportRoutes(r, services.Products, services.Customers, services.Orders)
+	registerStockRoutes(r, services.Stock)
+	registerWarehouseRoutes(r, services.Warehouses)
+	registerPurchaseRoutes(r, services.Suppliers, services.PurchaseOrders)
+	registerStocktakeRoutes(r, services.Stocktakes)
 	registerDocsRoutes(r)
+	registerMediaFiles(r, media)
 
 	return r
 }
+
+// registerMediaFiles serves locally stored media when the public base URL is a path of this service.
+func registerMediaFiles(r chi.Router, cfg config.Media) {
+	if !strings.HasPrefix(cfg.BaseURL, "/") {
+		return
+	}
+	prefix := strings.TrimSuffix(cfg.BaseURL, "/")
+	r.Handle(prefix+"/*", http.StripPrefix(prefix, http.FileServer(filesOnly{http.Dir(cfg.Dir)})))
+}
+
+// filesOnly hides directories, so the file server does not list the stored media.
+type filesOnly struct {
+	fs http.FileSystem
+}
+
+func (f filesOnly) Open(name string) (http.File, error) {
+	file, err := f.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, os.ErrNotExist
+	}
+	return file, nil
+}
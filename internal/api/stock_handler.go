@@ -0,0 +1,186 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"store-service/internal/api/dto"
+	"store-service/internal/logger"
+	"store-service/internal/repository"
+	"store-service/internal/service"
+)
+
+type stockHandler struct {
+	svc *service.StockService
+}
+
+func registerStockRoutes(r chi.Router, svc *service.StockService) {
+	h := &stockHandler{svc: svc}
+	r.Route("/stock", func(r chi.Router) {
+		r.Get("/reconciliation", h.discrepancies)
+		r.Post("/reconciliation", h.reconcile)
+	})
+}
+
+func (h *stockHandler) movements(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+	warehouseID, err := parseOptionalUUIDQuery(r, "warehouse_id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid warehouse_id")
+		return
+	}
+	variantID, err := parseOptionalUUIDQuery(r, "variant_id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid variant_id")
+		return
+	}
+
+	f := repository.StockMovementFilter{WarehouseID: warehouseID, VariantID: variantID, Reason: r.URL.Query().Get("reason")}
+	movements, page, err := h.svc.Movements(ctx, productID, f, parsePageRequest(r))
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		case repository.ErrInvalidStockReason:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		case repository.ErrInvalidCursor:
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		log.Error("failed to list stock movements", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to list stock movements")
+		return
+	}
+	writePageHeaders(w, r, page)
+	writeJSON(w, http.StatusOK, dto.FromStockMovements(movements))
+}
+
+// discrepancies lists products, variants and warehouse stock levels whose quantity differs from
+// the sum of their movements.
+func (h *stockHandler) discrepancies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	list, err := h.svc.Discrepancies(ctx)
+	if err != nil {
+		log.Error("failed to check stock against the ledger", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to check stock against the ledger")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromStockDiscrepancies(list))
+}
+
+// reconcile resets cached quantities to the ledger and returns what was corrected.
+func (h *stockHandler) reconcile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	list, err := h.svc.Reconcile(ctx)
+	if err != nil {
+		log.Error("failed to reconcile stock", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to reconcile stock")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromStockDiscrepancies(list))
+}
+
+func (h *stockHandler) reorderPoint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	rp, err := h.svc.ReorderPoint(ctx, productID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		log.Error("failed to get reorder point", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to get reorder point")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromReorderPoint(rp))
+}
+
+// setReorderPoint sets the own reorder point of the product; null falls back to its categories.
+func (h *stockHandler) setReorderPoint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	var req dto.ReorderPointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	rp, err := h.svc.SetProductReorderPoint(ctx, productID, req.ReorderPoint)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		case repository.ErrInvalidReorderPoint:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error("failed to set reorder point", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to set reorder point")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromReorderPoint(rp))
+}
+
+// setCategoryReorderPoint sets the default reorder point of products in the category.
+func (h *stockHandler) setCategoryReorderPoint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	categoryID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid category id")
+		return
+	}
+
+	var req dto.ReorderPointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.svc.SetCategoryReorderPoint(ctx, categoryID, req.ReorderPoint); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "category not found")
+			return
+		case repository.ErrInvalidReorderPoint:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error("failed to set category reorder point", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to set category reorder point")
+		return
+	}
+	writeJSON(w, http.StatusOK, req)
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"store-service/internal/api/dto"
+	"store-service/internal/logger"
+	"store-service/internal/repository"
+	"store-service/internal/service"
+)
+
+type stocktakeHandler struct {
+	svc *service.StocktakeService
+}
+
+func registerStocktakeRoutes(r chi.Router, svc *service.StocktakeService) {
+	h := &stocktakeHandler{svc: svc}
+	r.Route("/stocktakes", func(r chi.Router) {
+		r.Get("/", h.list)
+		r.Post("/", h.create)
+		r.Get("/{id}", h.get)
+		r.Post("/{id}/counts", h.count)
+		r.Get("/{id}/discrepancies", h.discrepancies)
+		r.Post("/{id}/commit", h.commit)
+		r.Post("/{id}/cancel", h.cancel)
+	})
+}
+
+func (h *stocktakeHandler) create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	var req dto.StocktakeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	st := req.ToModel()
+
+	if err := h.svc.Create(ctx, &st); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "warehouse not found")
+			return
+		case repository.ErrNoDefaultWarehouse, repository.ErrStocktakeInProgress:
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		log.Error("failed to create stocktake", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to create stocktake")
+		return
+	}
+	writeJSON(w, http.StatusCreated, dto.FromStocktake(st))
+}
+
+func (h *stocktakeHandler) get(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid stocktake id")
+		return
+	}
+
+	st, err := h.svc.Get(ctx, id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "stocktake not found")
+			return
+		}
+		log.Error("failed to get stocktake", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to get stocktake")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromStocktake(st))
+}
+
+func (h *stocktakeHandler) list(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	warehouseID, err := parseOptionalUUIDQuery(r, "warehouse_id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid warehouse_id")
+		return
+	}
+	f := repository.StocktakeFilter{
+		Status:      r.URL.Query().Get("status"),
+		WarehouseID: warehouseID,
+	}
+
+	list, page, err := h.svc.List(ctx, f, parsePageRequest(r))
+	if err != nil {
+		switch err {
+		case repository.ErrInvalidCursor:
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		case repository.ErrInvalidStocktakeStatus:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error("failed to list stocktakes", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to list stocktakes")
+		return
+	}
+	writePageHeaders(w, r, page)
+	writeJSON(w, http.StatusOK, dto.FromStocktakes(list))
+}
+
+// count records a batch of counted quantities uploaded by a scanner.
+func (h *stocktakeHandler) count(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid stocktake id")
+		return
+	}
+
+	var req dto.StocktakeCountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	st, err := h.svc.Count(ctx, id, req.ToModel())
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "stocktake, product or variant not found")
+			return
+		case repository.ErrInvalidStocktakeCount, repository.ErrVariantRequired:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		case repository.ErrStocktakeClosed:
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		log.Error("failed to record stocktake counts", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to record stocktake counts")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromStocktake(st))
+}
+
+// discrepancies previews the adjustments the stocktake makes on commit.
+func (h *stocktakeHandler) discrepancies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid stocktake id")
+		return
+	}
+
+	list, err := h.svc.Discrepancies(ctx, id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "stocktake not found")
+			return
+		}
+		log.Error("failed to list stocktake discrepancies", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to list stocktake discrepancies")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromStocktakeDiscrepancies(list))
+}
+
+func (h *stocktakeHandler) commit(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid stocktake id")
+		return
+	}
+
+	st, err := h.svc.Commit(ctx, id)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "stocktake not found")
+			return
+		case repository.ErrStocktakeClosed:
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		log.Error("failed to commit stocktake", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to commit stocktake")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromStocktake(st))
+}
+
+func (h *stocktakeHandler) cancel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid stocktake id")
+		return
+	}
+
+	st, err := h.svc.Cancel(ctx, id)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "stocktake not found")
+			return
+		case repository.ErrStocktakeClosed:
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		log.Error("failed to cancel stocktake", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to cancel stocktake")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromStocktake(st))
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"store-service/internal/api/dto"
+	"store-service/internal/logger"
+	"store-service/internal/repository"
+	"store-service/internal/service"
+)
+
+type variantHandler struct {
+	svc *service.VariantService
+}
+
+func (h *variantHandler) listOptions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	options, err := h.svc.ListOptions(ctx, productID)
+	if err != nil {
+		log.Error("failed to list product options", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to list product options")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromProductOptions(options))
+}
+
+func (h *variantHandler) replaceOptions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	var req dto.ProductOptionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	options, err := h.svc.ReplaceOptions(ctx, productID, req.ToModel())
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		case repository.ErrInvalidVariantOptions:
+			writeError(w, http.StatusBadRequest, "options need unique names and unique non-empty values")
+			return
+		case repository.ErrOptionsInUse:
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		log.Error("failed to replace product options", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to replace product options")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromProductOptions(options))
+}
+
+func (h *variantHandler) create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	var req dto.VariantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.SKU == "" {
+		writeError(w, http.StatusBadRequest, "sku is required")
+		return
+	}
+
+	v := req.ToModel(productID, uuid.Nil)
+
+	if err := h.svc.Create(ctx, &v); err != nil {
+		if writeVariantError(w, err) {
+			return
+		}
+		log.Error("failed to create variant", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to create variant")
+		return
+	}
+	writeJSON(w, http.StatusCreated, dto.FromVariant(v))
+}
+
+func (h *variantHandler) get(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, variantID, ok := parseVariantParams(w, r)
+	if !ok {
+		return
+	}
+
+	v, err := h.svc.Get(ctx, productID, variantID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "variant not found")
+			return
+		}
+		log.Error("failed to get variant", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to get variant")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromVariant(v))
+}
+
+func (h *variantHandler) update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, variantID, ok := parseVariantParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.VariantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.SKU == "" {
+		writeError(w, http.StatusBadRequest, "sku is required")
+		return
+	}
+
+	v := req.ToModel(productID, variantID)
+
+	if err := h.svc.Update(ctx, &v); err != nil {
+		if writeVariantError(w, err) {
+			return
+		}
+		log.Error("failed to update variant", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to update variant")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromVariant(v))
+}
+
+func (h *variantHandler) delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, variantID, ok := parseVariantParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Delete(ctx, productID, variantID); err != nil {
+		if writeVariantError(w, err) {
+			return
+		}
+		log.Error("failed to delete variant", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to delete variant")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *variantHandler) list(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	productID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	variants, err := h.svc.List(ctx, productID)
+	if err != nil {
+		log.Error("failed to list variants", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to list variants")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromVariants(variants))
+}
+
+func parseVariantParams(w http.ResponseWriter, r *http.Request) (productID, variantID uuid.UUID, ok bool) {
+	productID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return productID, variantID, false
+	}
+	variantID, err = parseUUIDParam(r, "variantID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid variant id")
+		return productID, variantID, false
+	}
+	return productID, variantID, true
+}
+
+// writeVariantError maps known variant errors to responses and reports whether it wrote one.
+func writeVariantError(w http.ResponseWriter, err error) bool {
+	switch err {
+	case repository.ErrNotFound:
+		writeError(w, http.StatusNotFound, "product or variant not found")
+	case repository.ErrInvalidVariantOptions:
+		writeError(w, http.StatusBadRequest, err.Error())
+	case repository.ErrDuplicateSKU, repository.ErrVariantInUse, repository.ErrNoDefaultWarehouse:
+		writeError(w, http.StatusConflict, err.Error())
+	case repository.ErrNotEnoughStock:
+		writeError(w, http.StatusConflict, "quantity change exceeds the stock of the default warehouse")
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,324 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"store-service/internal/api/dto"
+	"store-service/internal/logger"
+	"store-service/internal/repository"
+	"store-service/internal/service"
+)
+
+type warehouseHandler struct {
+	svc *service.WarehouseService
+}
+
+func registerWarehouseRoutes(r chi.Router, svc *service.WarehouseService) {
+	h := &warehouseHandler{svc: svc}
+	r.Route("/warehouses", func(r chi.Router) {
+		r.Get("/", h.list)
+		r.Post("/", h.create)
+		r.Get("/{id}", h.get)
+		r.Put("/{id}", h.update)
+		r.Delete("/{id}", h.delete)
+		r.Get("/{id}/stock", h.stock)
+		r.Put("/{id}/stock", h.setStock)
+	})
+	r.Route("/warehouse-transfers", func(r chi.Router) {
+		r.Get("/", h.listTransfers)
+		r.Post("/", h.createTransfer)
+		r.Get("/{id}", h.getTransfer)
+	})
+}
+
+func (h *warehouseHandler) create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	var req dto.WarehouseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	wh := req.ToModel(uuid.Nil)
+
+	if err := h.svc.Create(ctx, &wh); err != nil {
+		switch err {
+		case repository.ErrInvalidWarehouse, repository.ErrDefaultWarehouse:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		case repository.ErrDuplicateWarehouseCode:
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		log.Error("failed to create warehouse", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to create warehouse")
+		return
+	}
+	writeJSON(w, http.StatusCreated, dto.FromWarehouse(wh))
+}
+
+func (h *warehouseHandler) get(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid warehouse id")
+		return
+	}
+
+	wh, err := h.svc.Get(ctx, id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "warehouse not found")
+			return
+		}
+		log.Error("failed to get warehouse", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to get warehouse")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromWarehouse(wh))
+}
+
+func (h *warehouseHandler) list(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	list, err := h.svc.List(ctx)
+	if err != nil {
+		log.Error("failed to list warehouses", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to list warehouses")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromWarehouses(list))
+}
+
+func (h *warehouseHandler) update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid warehouse id")
+		return
+	}
+
+	var req dto.WarehouseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	wh := req.ToModel(id)
+
+	if err := h.svc.Update(ctx, &wh); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "warehouse not found")
+			return
+		case repository.ErrInvalidWarehouse:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		case repository.ErrDefaultWarehouse, repository.ErrDuplicateWarehouseCode:
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		log.Error("failed to update warehouse", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to update warehouse")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromWarehouse(wh))
+}
+
+func (h *warehouseHandler) delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid warehouse id")
+		return
+	}
+
+	if err := h.svc.Delete(ctx, id); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "warehouse not found")
+			return
+		case repository.ErrDefaultWarehouse, repository.ErrWarehouseInUse:
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		log.Error("failed to delete warehouse", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to delete warehouse")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// stock lists stock levels of the warehouse.
+func (h *warehouseHandler) stock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid warehouse id")
+		return
+	}
+
+	list, err := h.svc.Stock(ctx, id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "warehouse not found")
+			return
+		}
+		log.Error("failed to list warehouse stock", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to list warehouse stock")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromWarehouseStocks(list))
+}
+
+// setStock sets the counted stock level of a product or variant in the warehouse.
+func (h *warehouseHandler) setStock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid warehouse id")
+		return
+	}
+
+	var req dto.WarehouseStockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Quantity < 0 {
+		writeError(w, http.StatusBadRequest, "quantity must not be negative")
+		return
+	}
+
+	st := req.ToModel(id)
+
+	if err := h.svc.SetStock(ctx, &st); err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "warehouse, product or variant not found")
+			return
+		}
+		log.Error("failed to set warehouse stock", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to set warehouse stock")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromWarehouseStock(st))
+}
+
+// productStock lists stock levels of the product per warehouse.
+func (h *warehouseHandler) productStock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	list, err := h.svc.ProductStock(ctx, id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		log.Error("failed to list product stock", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to list product stock")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromWarehouseStocks(list))
+}
+
+func (h *warehouseHandler) createTransfer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	var req dto.WarehouseTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	t := req.ToModel()
+
+	if err := h.svc.CreateTransfer(ctx, &t); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			writeError(w, http.StatusNotFound, "warehouse, product or variant not found")
+			return
+		case repository.ErrInvalidTransfer:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		case repository.ErrNotEnoughStock:
+			writeError(w, http.StatusConflict, "not enough stock in the source warehouse")
+			return
+		}
+		log.Error("failed to create warehouse transfer", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to create warehouse transfer")
+		return
+	}
+	writeJSON(w, http.StatusCreated, dto.FromWarehouseTransfer(t))
+}
+
+func (h *warehouseHandler) getTransfer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid transfer id")
+		return
+	}
+
+	t, err := h.svc.GetTransfer(ctx, id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			writeError(w, http.StatusNotFound, "transfer not found")
+			return
+		}
+		log.Error("failed to get warehouse transfer", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to get warehouse transfer")
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.FromWarehouseTransfer(t))
+}
+
+func (h *warehouseHandler) listTransfers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	warehouseID, err := parseOptionalUUIDQuery(r, "warehouse_id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid warehouse_id")
+		return
+	}
+
+	list, page, err := h.svc.ListTransfers(ctx, warehouseID, parsePageRequest(r))
+	if err != nil {
+		if err == repository.ErrInvalidCursor {
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		log.Error("failed to list warehouse transfers", zapError(err))
+		writeError(w, http.StatusInternalServerError, "failed to list warehouse transfers")
+		return
+	}
+	writePageHeaders(w, r, page)
+	writeJSON(w, http.StatusOK, dto.FromWarehouseTransfers(list))
+}
@@ -2,6 +2,7 @@ package application
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"os/signal"
 	"syscall"
@@ -14,16 +15,21 @@ import (
 	"store-service/internal/api"
 	"store-service/internal/config"
 	"store-service/internal/logger"
+	"store-service/internal/model"
+	"store-service/internal/notify"
 	"store-service/internal/repository"
 	"store-service/internal/service"
+	"store-service/internal/storage"
+	"store-service/internal/worker"
 )
 
 type Application struct {
-	cfg    config.Config
-	log    *zap.Logger
-	db     *pgxpool.Pool
-	router *chi.Mux
-	server *http.Server
+	cfg     config.Config
+	log     *zap.Logger
+	db      *pgxpool.Pool
+	router  *chi.Mux
+	server  *http.Server
+	workers *worker.Runner
 }
 
 // New builds application with all dependencies.
@@ -33,11 +39,24 @@ func New(ctx context.Context) (*Application, error) {
 		return nil, err
 	}
 
+	baseCurrency := model.Currency(cfg.BaseCurrency)
+	if !baseCurrency.Valid() {
+		return nil, fmt.Errorf("unsupported base currency %q", cfg.BaseCurrency)
+	}
+	if !model.ValidAllocation(cfg.Orders.AllocationStrategy) {
+		return nil, fmt.Errorf("unsupported allocation strategy %q", cfg.Orders.AllocationStrategy)
+	}
+
 	log, err := logger.New(cfg.LogLevel)
 	if err != nil {
 		return nil, err
 	}
 
+	notifier, err := newNotifier(cfg.Alerts, log)
+	if err != nil {
+		return nil, err
+	}
+
 	dbCfg, err := pgxpool.ParseConfig(cfg.Postgres.DSN)
 	if err != nil {
 		return nil, err
@@ -54,9 +73,30 @@ func New(ctx context.Context) (*Application, error) {
 	productRepo := repository.NewProductRepository(pool)
 	orderRepo := repository.NewOrderRepository(pool)
 	reportRepo := repository.NewReportRepository(pool)
+	productCategoryRepo := repository.NewProductCategoryRepository(pool)
+	variantRepo := repository.NewVariantRepository(pool)
+	attributeRepo := repository.NewAttributeRepository(pool)
+	mediaRepo := repository.NewMediaRepository(pool)
+	priceRepo := repository.NewPriceRepository(pool)
+	exchangeRateRepo := repository.NewExchangeRateRepository(pool)
+	importRepo := repository.NewImportRepository(pool)
+	recommendationRepo := repository.NewRecommendationRepository(pool)
+	reservationRepo := repository.NewReservationRepository(pool)
+	stockRepo := repository.NewStockRepository(pool)
+	warehouseRepo := repository.NewWarehouseRepository(pool)
+	supplierRepo := repository.NewSupplierRepository(pool)
+	purchaseOrderRepo := repository.NewPurchaseOrderRepository(pool)
+	stocktakeRepo := repository.NewStocktakeRepository(pool)
+
+	store, err := storage.NewLocal(cfg.Media.Dir, cfg.Media.BaseURL)
+	if err != nil {
+		return nil, err
+	}
 
-	services := service.NewServices(categoryRepo, customerRepo, productRepo, orderRepo, reportRepo)
-	router := api.NewRouter(log, services)
+	services := service.NewServices(categoryRepo, customerRepo, productRepo, orderRepo, reportRepo, productCategoryRepo, variantRepo, attributeRepo,
+		mediaRepo, store, cfg.Media, priceRepo, exchangeRateRepo, baseCurrency, importRepo, cfg.Import,
+		recommendationRepo, cfg.Recommendations, reservationRepo, cfg.Orders, stockRepo, warehouseRepo, notifier, supplierRepo, purchaseOrderRepo, stocktakeRepo)
+	router := api.NewRouter(log, services, cfg.HTTP, cfg.Media)
 
 	server := &http.Server{
 		Addr:              cfg.HTTP.Addr,
@@ -64,12 +104,70 @@ func New(ctx context.Context) (*Application, error) {
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
+	workers := worker.New(log)
+	workers.Add(worker.Job{
+		Name:     "price-scheduler",
+		Interval: cfg.Workers.PriceSchedulerInterval,
+		Run: func(ctx context.Context) error {
+			n, err := services.Prices.ApplyDue(ctx)
+			if n > 0 {
+				log.Info("scheduled prices applied", zap.Int("count", n))
+			}
+			return err
+		},
+	})
+	workers.Add(worker.Job{
+		Name:     "product-import",
+		Interval: cfg.Workers.ImportInterval,
+		Run: func(ctx context.Context) error {
+			n, err := services.Imports.RunPending(ctx)
+			if n > 0 {
+				log.Info("import jobs processed", zap.Int("count", n))
+			}
+			return err
+		},
+	})
+	workers.Add(worker.Job{
+		Name:     "recommendations",
+		Interval: cfg.Workers.RecommendationsInterval,
+		Run: func(ctx context.Context) error {
+			n, err := services.Recommendations.Recompute(ctx)
+			if err == nil {
+				log.Info("product recommendations recomputed", zap.Int("pairs", n))
+			}
+			return err
+		},
+	})
+	workers.Add(worker.Job{
+		Name:     "reservation-sweeper",
+		Interval: cfg.Workers.ReservationSweepInterval,
+		Run: func(ctx context.Context) error {
+			n, err := services.Orders.ExpireReservations(ctx)
+			if n > 0 {
+				log.Info("expired stock reservations released", zap.Int("count", n))
+			}
+			return err
+		},
+	})
+	workers.Add(worker.Job{
+		Name:     "stock-alerts",
+		Interval: cfg.Workers.StockAlertInterval,
+		Run: func(ctx context.Context) error {
+			n, err := services.Stock.DispatchAlerts(ctx)
+			if n > 0 {
+				log.Info("low stock alerts sent", zap.Int("count", n))
+			}
+			return err
+		},
+	})
+
 	return &Application{
-		cfg:    cfg,
-		log:    log,
-		db:     pool,
-		router: router,
-		server: server,
+		cfg:     cfg,
+		log:     log,
+		db:      pool,
+		router:  router,
+		server:  server,
+		workers: workers,
 	}, nil
 }
 
@@ -79,6 +177,10 @@ func (a *Application) Run(ctx context.Context) error {
 	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	workersCtx, stopWorkers := context.WithCancel(ctx)
+	defer stopWorkers()
+	a.workers.Start(workersCtx)
+
 	errCh := make(chan error, 1)
 	go func() {
 		if err := a.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -102,6 +204,23 @@ func (a *Application) Run(ctx context.Context) error {
 		return err
 	}
 
+	stopWorkers()
+	a.workers.Wait()
+
 	a.db.Close()
 	return nil
 }
+
+// newNotifier builds the alert notifier selected by ALERT_NOTIFIER.
+func newNotifier(cfg config.Alerts, log *zap.Logger) (notify.Notifier, error) {
+	switch cfg.Notifier {
+	case "log":
+		return notify.NewLog(log), nil
+	case "webhook":
+		if cfg.WebhookURL == "" {
+			return nil, fmt.Errorf("ALERT_WEBHOOK_URL is required for the webhook notifier")
+		}
+		return notify.NewWebhook(cfg.WebhookURL, cfg.WebhookTimeout), nil
+	}
+	return nil, fmt.Errorf("unsupported alert notifier %q", cfg.Notifier)
+}
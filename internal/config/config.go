@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
@@ -9,6 +10,59 @@ import (
 // HTTP holds HTTP server configuration.
 type HTTP struct {
 	Addr string `envconfig:"HTTP_ADDR" default:":8080"`
+	// DefaultPageSize is used when a list request has no limit; MaxPageSize caps the limit.
+	DefaultPageSize int `envconfig:"HTTP_DEFAULT_PAGE_SIZE" default:"50"`
+	MaxPageSize     int `envconfig:"HTTP_MAX_PAGE_SIZE" default:"200"`
+}
+
+// Media holds product media storage settings.
+type Media struct {
+	Dir string `envconfig:"MEDIA_DIR" default:"./data/media"`
+	// BaseURL prefixes public file URLs; a path (starting with "/") is served by the service itself.
+	BaseURL        string `envconfig:"MEDIA_BASE_URL" default:"/media"`
+	ThumbnailSizes []int  `envconfig:"MEDIA_THUMBNAIL_SIZES" default:"160,480"`
+	MaxUploadSize  int64  `envconfig:"MEDIA_MAX_UPLOAD_SIZE" default:"10485760"`
+	// MaxPixels caps width*height of an uploaded image; a small file can unpack to a huge bitmap.
+	MaxPixels int64 `envconfig:"MEDIA_MAX_PIXELS" default:"40000000"`
+}
+
+// Import holds product import settings.
+type Import struct {
+	MaxFileSize int64 `envconfig:"IMPORT_MAX_FILE_SIZE" default:"20971520"`
+	// SyncMaxRows is the largest file (in data rows) processed within the request; larger files
+	// are queued for the import worker.
+	SyncMaxRows int `envconfig:"IMPORT_SYNC_MAX_ROWS" default:"500"`
+}
+
+// Recommendations holds settings of "frequently bought together" scores.
+type Recommendations struct {
+	// MinOrders is how many orders must contain both products for the pair to be recommended.
+	MinOrders int `envconfig:"RECOMMENDATIONS_MIN_ORDERS" default:"2"`
+}
+
+// Orders holds order settings.
+type Orders struct {
+	// ReservationTTL is how long stock added to an unpaid order stays held for it.
+	ReservationTTL time.Duration `envconfig:"RESERVATION_TTL" default:"30m"`
+	// AllocationStrategy picks the warehouse that supplies an order item: "priority" or "nearest".
+	AllocationStrategy string `envconfig:"ALLOCATION_STRATEGY" default:"priority"`
+}
+
+// Alerts holds settings of operator alerts such as low stock.
+type Alerts struct {
+	// Notifier is where alerts are sent: "log" or "webhook".
+	Notifier       string        `envconfig:"ALERT_NOTIFIER" default:"log"`
+	WebhookURL     string        `envconfig:"ALERT_WEBHOOK_URL"`
+	WebhookTimeout time.Duration `envconfig:"ALERT_WEBHOOK_TIMEOUT" default:"5s"`
+}
+
+// Workers holds intervals of background jobs; a zero interval disables the job.
+type Workers struct {
+	PriceSchedulerInterval   time.Duration `envconfig:"PRICE_SCHEDULER_INTERVAL" default:"1m"`
+	ImportInterval           time.Duration `envconfig:"IMPORT_WORKER_INTERVAL" default:"5s"`
+	RecommendationsInterval  time.Duration `envconfig:"RECOMMENDATIONS_INTERVAL" default:"1h"`
+	ReservationSweepInterval time.Duration `envconfig:"RESERVATION_SWEEP_INTERVAL" default:"1m"`
+	StockAlertInterval       time.Duration `envconfig:"STOCK_ALERT_INTERVAL" default:"30s"`
 }
 
 // Postgres holds connection settings for PostgreSQL.
@@ -21,8 +75,16 @@ type Postgres struct {
 type Config struct {
 	HTTP            HTTP
 	Postgres        Postgres
+	Media           Media
+	Import          Import
+	Recommendations Recommendations
+	Orders          Orders
+	Alerts          Alerts
+	Workers         Workers
 	GracefulTimeout time.Duration `envconfig:"GRACEFUL_TIMEOUT" default:"10s"`
 	LogLevel        string        `envconfig:"LOG_LEVEL" default:"info"`
+	// BaseCurrency is the default currency of new products and orders and of reports.
+	BaseCurrency string `envconfig:"BASE_CURRENCY" default:"RUB"`
 }
 
 // Load reads configuration values from the environment.
@@ -31,5 +93,21 @@ func Load() (Config, error) {
 	if err := envconfig.Process("", &cfg); err != nil {
 		return cfg, err
 	}
+	if err := cfg.HTTP.validate(); err != nil {
+		return cfg, err
+	}
 	return cfg, nil
 }
+
+// validate rejects page sizes that would make list handlers return empty or unbounded pages.
+func (h HTTP) validate() error {
+	if h.DefaultPageSize < 1 || h.MaxPageSize < 1 {
+		return fmt.Errorf("HTTP_DEFAULT_PAGE_SIZE and HTTP_MAX_PAGE_SIZE must be at least 1, got %d and %d",
+			h.DefaultPageSize, h.MaxPageSize)
+	}
+	if h.DefaultPageSize > h.MaxPageSize {
+		return fmt.Errorf("HTTP_DEFAULT_PAGE_SIZE (%d) must not exceed HTTP_MAX_PAGE_SIZE (%d)",
+			h.DefaultPageSize, h.MaxPageSize)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// тип значения атрибута
+type AttributeType string
+
+const (
+	AttributeString  AttributeType = "string"
+	AttributeNumber  AttributeType = "number"
+	AttributeBoolean AttributeType = "boolean"
+	AttributeEnum    AttributeType = "enum"
+)
+
+// атрибут товара (бренд, материал, объем памяти)
+// id уникальный идентификатор атрибута
+// code код атрибута, ключ в products.attributes
+// name название атрибута
+// type тип значения
+// options допустимые значения для enum
+type Attribute struct {
+	ID        uuid.UUID     `json:"id"`
+	Code      string        `json:"code"`
+	Name      string        `json:"name"`
+	Type      AttributeType `json:"type"`
+	Options   []string      `json:"options"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// фасет атрибута для текущего набора фильтров
+// values количество товаров по значению (string, enum, boolean)
+// min, max диапазон значений (number)
+type AttributeFacet struct {
+	Code   string           `json:"code"`
+	Name   string           `json:"name"`
+	Type   AttributeType    `json:"type"`
+	Values []FacetValue     `json:"values,omitempty"`
+	Min    *decimal.Decimal `json:"min,omitempty"`
+	Max    *decimal.Decimal `json:"max,omitempty"`
+}
+
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
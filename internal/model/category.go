@@ -11,20 +11,43 @@ import (
 // name название категории
 // slug уникальный идентификатор категории для url
 // parent_id идентификатор родительской категории
-// level уровень вложенности категории
+// level уровень вложенности категории, вычисляется по родителю
+// path материализованный путь: id от корня до категории через '/'
+// root_category_id идентификатор корневой категории
 // is_active флаг активности категории
 // sort_order порядок сортировки категории
 // created_at дата создания категории
 // updated_at дата обновления категории
 
 type Category struct {
-	ID        uuid.UUID  `json:"id"`
-	Name      string     `json:"name"`
-	Slug      string     `json:"slug"`
-	ParentID  *uuid.UUID `json:"parent_id,omitempty"`
-	Level     int        `json:"level"`
-	IsActive  bool       `json:"is_active"`
-	SortOrder int        `json:"sort_order"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
+	ID             uuid.UUID  `json:"id"`
+	Name           string     `json:"name"`
+	Slug           string     `json:"slug"`
+	ParentID       *uuid.UUID `json:"parent_id,omitempty"`
+	Level          int        `json:"level"`
+	Path           string     `json:"path"`
+	RootCategoryID *uuid.UUID `json:"root_category_id,omitempty"`
+	IsActive       bool       `json:"is_active"`
+	SortOrder      int        `json:"sort_order"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
 }
+
+// узел дерева категорий
+// children дочерние категории, отсортированные по sort_order
+type CategoryNode struct {
+	Category
+	Children []*CategoryNode `json:"children"`
+}
+
+// стратегия удаления категории с дочерними категориями и товарами
+// reject отказать, если есть дочерние категории или товары
+// reparent перенести дочерние категории и товары к родителю удаляемой
+// cascade удалить всё поддерево
+type CategoryDeleteStrategy string
+
+const (
+	CategoryDeleteReject   CategoryDeleteStrategy = "reject"
+	CategoryDeleteReparent CategoryDeleteStrategy = "reparent"
+	CategoryDeleteCascade  CategoryDeleteStrategy = "cascade"
+)
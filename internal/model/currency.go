@@ -0,0 +1,50 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// код валюты ISO 4217
+type Currency string
+
+const (
+	CurrencyRUB Currency = "RUB"
+	CurrencyKZT Currency = "KZT"
+	CurrencyBYN Currency = "BYN"
+)
+
+// currencyScales задает число знаков после запятой для сумм в валюте.
+// Тиыны в обороте не используются, поэтому суммы в тенге округляются до целых.
+var currencyScales = map[Currency]int32{
+	CurrencyRUB: 2,
+	CurrencyKZT: 0,
+	CurrencyBYN: 2,
+}
+
+// Currencies returns supported currencies in a stable order.
+func Currencies() []Currency {
+	return []Currency{CurrencyRUB, CurrencyKZT, CurrencyBYN}
+}
+
+func (c Currency) Valid() bool {
+	_, ok := currencyScales[c]
+	return ok
+}
+
+// Round rounds the amount half away from zero to the minor unit used for the currency.
+func (c Currency) Round(d decimal.Decimal) decimal.Decimal {
+	return d.Round(currencyScales[c])
+}
+
+// курс валюты: 1 base = rate quote начиная с effective_at
+type ExchangeRate struct {
+	ID          uuid.UUID       `json:"id"`
+	Base        Currency        `json:"base"`
+	Quote       Currency        `json:"quote"`
+	Rate        decimal.Decimal `json:"rate"`
+	EffectiveAt time.Time       `json:"effective_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
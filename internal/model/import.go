@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ImportStatus string
+
+const (
+	ImportPending   ImportStatus = "pending"
+	ImportRunning   ImportStatus = "running"
+	ImportCompleted ImportStatus = "completed"
+	ImportFailed    ImportStatus = "failed"
+)
+
+type ImportFormat string
+
+const (
+	ImportCSV  ImportFormat = "csv"
+	ImportXLSX ImportFormat = "xlsx"
+)
+
+// действие над строкой импорта; при dry_run — действие, которое было бы выполнено
+type ImportAction string
+
+const (
+	ImportActionCreate ImportAction = "create"
+	ImportActionUpdate ImportAction = "update"
+	ImportActionError  ImportAction = "error"
+)
+
+// задание импорта товаров; report содержит результат по каждой обработанной строке,
+// error — причину, по которой файл не удалось обработать целиком
+type ImportJob struct {
+	ID            uuid.UUID         `json:"id"`
+	Filename      string            `json:"filename"`
+	Format        ImportFormat      `json:"format"`
+	DryRun        bool              `json:"dry_run"`
+	Status        ImportStatus      `json:"status"`
+	TotalRows     int               `json:"total_rows"`
+	ProcessedRows int               `json:"processed_rows"`
+	Created       int               `json:"created"`
+	Updated       int               `json:"updated"`
+	Failed        int               `json:"failed"`
+	Report        []ImportRowResult `json:"report"`
+	Error         string            `json:"error,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	StartedAt     *time.Time        `json:"started_at"`
+	FinishedAt    *time.Time        `json:"finished_at"`
+}
+
+// результат строки файла; row — номер строки в файле, заголовок — строка 1
+type ImportRowResult struct {
+	Row       int          `json:"row"`
+	Action    ImportAction `json:"action"`
+	ProductID *uuid.UUID   `json:"product_id,omitempty"`
+	Errors    []string     `json:"errors,omitempty"`
+}
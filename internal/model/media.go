@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// изображение товара
+// key ключ оригинала в хранилище
+// url публичная ссылка, заполняется сервисом
+// position порядок в галерее
+// is_primary главное изображение товара (не больше одного)
+type ProductMedia struct {
+	ID          uuid.UUID        `json:"id"`
+	ProductID   uuid.UUID        `json:"product_id"`
+	Key         string           `json:"key"`
+	URL         string           `json:"url"`
+	ContentType string           `json:"content_type"`
+	Size        int64            `json:"size"`
+	Width       int              `json:"width"`
+	Height      int              `json:"height"`
+	Thumbnails  []MediaThumbnail `json:"thumbnails"`
+	Position    int              `json:"position"`
+	IsPrimary   bool             `json:"is_primary"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// миниатюра изображения
+// size максимальная сторона в пикселях
+type MediaThumbnail struct {
+	Size int    `json:"size"`
+	Key  string `json:"key"`
+	URL  string `json:"url,omitempty"`
+}
@@ -7,22 +7,63 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// статусы заказа: в новый заказ можно добавлять товары, под них резервируется остаток;
+// при оплате резервы списываются со склада, при отмене снимаются
+const (
+	OrderStatusNew       = "new"
+	OrderStatusPaid      = "paid"
+	OrderStatusCancelled = "cancelled"
+)
+
+// orderTransitions lists the statuses an order may move to from each status; a cancelled order is final.
+var orderTransitions = map[string][]string{
+	OrderStatusNew:  {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid: {OrderStatusCancelled},
+}
+
+// ValidOrderStatus reports whether status is one of the known order statuses.
+func ValidOrderStatus(status string) bool {
+	return status == OrderStatusNew || status == OrderStatusPaid || status == OrderStatusCancelled
+}
+
+// OrderStatusTransitionAllowed reports whether an order in status from may be moved to status to.
+func OrderStatusTransitionAllowed(from, to string) bool {
+	for _, s := range orderTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
 type Order struct {
 	ID         uuid.UUID       `json:"id"`
 	CustomerID uuid.UUID       `json:"customer_id"`
 	Items      []OrderItem     `json:"items"`
 	TotalPrice decimal.Decimal `json:"total_price"`
+	Currency   Currency        `json:"currency"`
 	Status     string          `json:"status"`
-	CreatedAt  time.Time       `json:"created_at"`
-	UpdatedAt  time.Time       `json:"updated_at"`
+	// точка доставки для выбора ближайшего склада
+	ShippingLatitude  *float64  `json:"shipping_latitude"`
+	ShippingLongitude *float64  `json:"shipping_longitude"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
 }
 
+// позиция заказа
+// product_name, unit_price название и цена товара на момент добавления, цена в валюте заказа;
+// единицы, добавленные по другой цене или с другого склада, образуют отдельную позицию;
+// warehouse_id склад, с которого берется товар
 type OrderItem struct {
-	ID        uuid.UUID       `json:"id"`
-	OrderID   uuid.UUID       `json:"order_id"`
-	ProductID uuid.UUID       `json:"product_id"`
-	Quantity  int             `json:"quantity"`
-	SubTotal  decimal.Decimal `json:"sub_total"`
-	CreatedAt time.Time       `json:"created_at"`
-	UpdatedAt time.Time       `json:"updated_at"`
+	ID          uuid.UUID       `json:"id"`
+	OrderID     uuid.UUID       `json:"order_id"`
+	ProductID   uuid.UUID       `json:"product_id"`
+	VariantID   *uuid.UUID      `json:"variant_id,omitempty"`
+	WarehouseID *uuid.UUID      `json:"warehouse_id"`
+	ProductName string          `json:"product_name"`
+	Quantity    int             `json:"quantity"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+	SubTotal    decimal.Decimal `json:"sub_total"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
 }
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// цена товара в истории цен
+// effective_from, effective_to интервал действия цены, effective_to пустой у текущей цены
+// applied_at момент применения, пустой у запланированной цены
+type ProductPrice struct {
+	ID            uuid.UUID       `json:"id"`
+	ProductID     uuid.UUID       `json:"product_id"`
+	Price         decimal.Decimal `json:"price"`
+	Currency      Currency        `json:"currency"`
+	EffectiveFrom time.Time       `json:"effective_from"`
+	EffectiveTo   *time.Time      `json:"effective_to"`
+	AppliedAt     *time.Time      `json:"applied_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// Scheduled reports whether the price is waiting to be applied.
+func (p ProductPrice) Scheduled() bool {
+	return p.AppliedAt == nil
+}
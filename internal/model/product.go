@@ -7,11 +7,33 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// archived_at заполнено у товаров в архиве: они скрыты из списков и недоступны для заказа;
+// sku и external_id необязательны и уникальны, по ним сопоставляются строки импорта;
+// slug уникален и используется в url, старые slug после переименования ведут на товар;
+// available остаток за вычетом активных резервов неоплаченных заказов;
+// incoming заказано у поставщиков и еще не получено по открытым заказам на закупку
 type Product struct {
-	ID        uuid.UUID       `json:"id"`
-	Name      string          `json:"name"`
-	Price     decimal.Decimal `json:"price"`
-	Quantity  int             `json:"quantity"`
-	CreatedAt time.Time       `json:"created_at"`
-	UpdatedAt time.Time       `json:"updated_at"`
+	ID         uuid.UUID       `json:"id"`
+	Slug       string          `json:"slug"`
+	SKU        *string         `json:"sku"`
+	ExternalID *string         `json:"external_id"`
+	Name       string          `json:"name"`
+	Price      decimal.Decimal `json:"price"`
+	Currency   Currency        `json:"currency"`
+	Quantity   int             `json:"quantity"`
+	Available  int             `json:"available"`
+	Incoming   int             `json:"incoming"`
+	Attributes map[string]any  `json:"attributes"`
+	Media      []ProductMedia  `json:"media"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	ArchivedAt *time.Time      `json:"archived_at"`
+}
+
+// ProductSearchHit is a product matched by full-text search.
+// Snippet is the HTML-escaped product name with matched words wrapped in <b></b>.
+type ProductSearchHit struct {
+	Product
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
 }
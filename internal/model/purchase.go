@@ -0,0 +1,96 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// поставщик
+// id уникальный идентификатор поставщика
+// name название поставщика
+// email, phone, address контакты поставщика
+type Supplier struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Phone     string    `json:"phone"`
+	Address   string    `json:"address"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// статусы заказа на закупку
+const (
+	// ничего не получено
+	PurchaseOrderOpen = "open"
+	// получена часть строк или количеств
+	PurchaseOrderPartiallyReceived = "partially_received"
+	// получено все заказанное
+	PurchaseOrderReceived = "received"
+	// отменен, неполученное больше не ожидается
+	PurchaseOrderCancelled = "cancelled"
+)
+
+// PurchaseOrderPending reports whether goods of the purchase order are still expected.
+func PurchaseOrderPending(status string) bool {
+	return status == PurchaseOrderOpen || status == PurchaseOrderPartiallyReceived
+}
+
+// заказ на закупку у поставщика
+// warehouse_id склад, на который приходует товар
+// currency валюта цен закупки
+// expected_at ожидаемая дата поставки
+// receipts поступления по заказу, заполняются при получении одного заказа
+type PurchaseOrder struct {
+	ID          uuid.UUID           `json:"id"`
+	SupplierID  uuid.UUID           `json:"supplier_id"`
+	WarehouseID uuid.UUID           `json:"warehouse_id"`
+	Status      string              `json:"status"`
+	Currency    Currency            `json:"currency"`
+	ExpectedAt  *time.Time          `json:"expected_at"`
+	Note        string              `json:"note"`
+	Lines       []PurchaseOrderLine `json:"lines"`
+	Receipts    []PurchaseReceipt   `json:"receipts,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+// строка заказа на закупку
+// quantity_received сколько уже получено, не больше quantity_ordered
+// unit_cost цена единицы у поставщика
+type PurchaseOrderLine struct {
+	ID               uuid.UUID       `json:"id"`
+	ProductID        uuid.UUID       `json:"product_id"`
+	VariantID        *uuid.UUID      `json:"variant_id,omitempty"`
+	QuantityOrdered  int             `json:"quantity_ordered"`
+	QuantityReceived int             `json:"quantity_received"`
+	UnitCost         decimal.Decimal `json:"unit_cost"`
+}
+
+// поступление товара по заказу на закупку, проводится сразу при создании
+// extra_cost доставка, пошлины и прочие расходы поступления, распределяются по строкам пропорционально стоимости
+// actor кто принял поступление
+type PurchaseReceipt struct {
+	ID              uuid.UUID             `json:"id"`
+	PurchaseOrderID uuid.UUID             `json:"purchase_order_id"`
+	ExtraCost       decimal.Decimal       `json:"extra_cost"`
+	Note            string                `json:"note"`
+	Actor           string                `json:"actor"`
+	Lines           []PurchaseReceiptLine `json:"lines"`
+	ReceivedAt      time.Time             `json:"received_at"`
+}
+
+// строка поступления
+// line_id строка заказа на закупку
+// landed_unit_cost себестоимость единицы: цена поставщика плюс доля расходов поступления
+type PurchaseReceiptLine struct {
+	ID             uuid.UUID       `json:"id"`
+	LineID         uuid.UUID       `json:"line_id"`
+	ProductID      uuid.UUID       `json:"product_id"`
+	VariantID      *uuid.UUID      `json:"variant_id,omitempty"`
+	Quantity       int             `json:"quantity"`
+	UnitCost       decimal.Decimal `json:"unit_cost"`
+	LandedUnitCost decimal.Decimal `json:"landed_unit_cost"`
+}
@@ -0,0 +1,12 @@
+package model
+
+// рекомендованный товар: часто покупается вместе с исходным товаром (или товарами заказа)
+// orders_together число заказов, в которых товары встречались вместе
+// confidence доля заказов исходного товара, в которых есть рекомендованный
+// lift во сколько раз рекомендованный товар покупают с исходным чаще, чем в среднем
+type ProductRecommendation struct {
+	Product
+	OrdersTogether int     `json:"orders_together"`
+	Confidence     float64 `json:"confidence"`
+	Lift           float64 `json:"lift"`
+}
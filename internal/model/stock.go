@@ -0,0 +1,78 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// причины движения остатка
+const (
+	StockSale       = "sale"
+	StockReturn     = "return"
+	StockAdjustment = "adjustment"
+	StockReceipt    = "receipt"
+	StockTransfer   = "transfer"
+)
+
+// движение остатка товара или варианта на складе: журнал, сумма delta по которому равна quantity
+// (по складу — остатку склада)
+// variant_id заполнен у движений остатка варианта
+// reference_id документ-основание: заказ для sale/return, задание импорта, перемещение, поступление,
+// инвентаризация
+// actor кто изменил остаток: заголовок X-Actor запроса (не проверяется) или фоновая задача
+// request_id идентификатор HTTP-запроса, пуст у фоновых задач
+type StockMovement struct {
+	ID          uuid.UUID  `json:"id"`
+	WarehouseID uuid.UUID  `json:"warehouse_id"`
+	ProductID   uuid.UUID  `json:"product_id"`
+	VariantID   *uuid.UUID `json:"variant_id,omitempty"`
+	Delta       int        `json:"delta"`
+	Reason      string     `json:"reason"`
+	ReferenceID *uuid.UUID `json:"reference_id,omitempty"`
+	Actor       string     `json:"actor"`
+	RequestID   string     `json:"request_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// расхождение закешированного остатка с журналом движений
+// warehouse_id заполнен у расхождения остатка склада, пуст у общего остатка товара
+type StockDiscrepancy struct {
+	WarehouseID    *uuid.UUID `json:"warehouse_id,omitempty"`
+	ProductID      uuid.UUID  `json:"product_id"`
+	VariantID      *uuid.UUID `json:"variant_id,omitempty"`
+	Quantity       int        `json:"quantity"`
+	LedgerQuantity int        `json:"ledger_quantity"`
+}
+
+// откуда взята точка заказа товара
+const (
+	ReorderPointProduct  = "product"
+	ReorderPointCategory = "category"
+)
+
+// точка заказа товара
+// reorder_point своя точка товара, category_reorder_point — точка по умолчанию из его категорий
+// effective действующая точка: своя, иначе из категории; пусто, если не задана нигде
+type ReorderPoint struct {
+	ProductID            uuid.UUID `json:"product_id"`
+	ReorderPoint         *int      `json:"reorder_point"`
+	CategoryReorderPoint *int      `json:"category_reorder_point"`
+	Effective            *int      `json:"effective"`
+}
+
+// тип события оповещения о низком остатке
+const StockLowEvent = "stock.low"
+
+// оповещение о том, что заказ опустил доступный остаток товара до точки заказа;
+// доставляется фоновой задачей
+// available доступный остаток товара и его вариантов после добавления в заказ
+type StockAlert struct {
+	ID           uuid.UUID  `json:"id"`
+	ProductID    uuid.UUID  `json:"product_id"`
+	ProductName  string     `json:"product_name"`
+	OrderID      *uuid.UUID `json:"order_id"`
+	ReorderPoint int        `json:"reorder_point"`
+	Available    int        `json:"available"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
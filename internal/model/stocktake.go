@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// статусы инвентаризации
+const (
+	// идет подсчет, принимаются результаты
+	StocktakeOpen = "open"
+	// расхождения проведены корректировками остатка
+	StocktakeCommitted = "committed"
+	// отменена без изменения остатков
+	StocktakeCancelled = "cancelled"
+)
+
+// инвентаризация склада: пересчет остатков, который проводится корректировками при завершении
+// actor кто начал инвентаризацию
+// closed_at время проведения или отмены
+// counted сколько товаров и вариантов уже посчитано
+type Stocktake struct {
+	ID          uuid.UUID  `json:"id"`
+	WarehouseID uuid.UUID  `json:"warehouse_id"`
+	Status      string     `json:"status"`
+	Note        string     `json:"note"`
+	Actor       string     `json:"actor"`
+	Counted     int        `json:"counted"`
+	StartedAt   time.Time  `json:"started_at"`
+	ClosedAt    *time.Time `json:"closed_at"`
+}
+
+// результат подсчета товара или варианта; повторный подсчет заменяет предыдущий
+// counted_at когда товар посчитан: продажи и другие движения после этого момента не считаются расхождением
+type StocktakeCount struct {
+	ProductID uuid.UUID  `json:"product_id"`
+	VariantID *uuid.UUID `json:"variant_id,omitempty"`
+	Quantity  int        `json:"quantity"`
+	CountedAt time.Time  `json:"counted_at"`
+}
+
+// расхождение подсчета с остатком
+// expected остаток склада по журналу на момент подсчета
+// difference counted - expected
+// quantity текущий остаток склада
+// adjustment корректировка остатка при проведении: difference, но не ниже текущего остатка с минусом
+type StocktakeDiscrepancy struct {
+	ProductID  uuid.UUID  `json:"product_id"`
+	VariantID  *uuid.UUID `json:"variant_id,omitempty"`
+	Counted    int        `json:"counted"`
+	Expected   int        `json:"expected"`
+	Difference int        `json:"difference"`
+	Quantity   int        `json:"quantity"`
+	Adjustment int        `json:"adjustment"`
+	CountedAt  time.Time  `json:"counted_at"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// опция товара, по которой различаются варианты (размер, цвет)
+// id уникальный идентификатор опции
+// product_id идентификатор товара
+// name название опции, уникально в пределах товара
+// values допустимые значения опции
+// position порядок отображения опции
+type ProductOption struct {
+	ID        uuid.UUID `json:"id"`
+	ProductID uuid.UUID `json:"product_id"`
+	Name      string    `json:"name"`
+	Values    []string  `json:"values"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// вариант товара (SKU)
+// id уникальный идентификатор варианта
+// product_id идентификатор товара
+// sku уникальный артикул
+// options значения опций варианта: имя опции -> значение
+// price цена варианта, если не задана — берется цена товара
+// quantity остаток варианта на складе
+// available остаток за вычетом активных резервов неоплаченных заказов
+// incoming заказано у поставщиков и еще не получено по открытым заказам на закупку
+type ProductVariant struct {
+	ID        uuid.UUID         `json:"id"`
+	ProductID uuid.UUID         `json:"product_id"`
+	SKU       string            `json:"sku"`
+	Options   map[string]string `json:"options"`
+	Price     *decimal.Decimal  `json:"price,omitempty"`
+	Quantity  int               `json:"quantity"`
+	Available int               `json:"available"`
+	Incoming  int               `json:"incoming"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// EffectivePrice returns the variant price override or the product base price.
+func (v ProductVariant) EffectivePrice(base decimal.Decimal) decimal.Decimal {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return base
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// стратегии выбора склада при добавлении товара в заказ
+const (
+	// склад с наименьшим priority, где хватает остатка
+	AllocationPriority = "priority"
+	// ближайший к точке доставки заказа склад, где хватает остатка; без координат — как priority
+	AllocationNearest = "nearest"
+)
+
+// ValidAllocation reports whether the allocation strategy is supported.
+func ValidAllocation(strategy string) bool {
+	return strategy == AllocationPriority || strategy == AllocationNearest
+}
+
+// ValidCoordinates reports whether latitude and longitude are both omitted or both set and in range.
+func ValidCoordinates(lat, lon *float64) bool {
+	if lat == nil || lon == nil {
+		return lat == nil && lon == nil
+	}
+	return *lat >= -90 && *lat <= 90 && *lon >= -180 && *lon <= 180
+}
+
+// склад
+// code уникальный код склада
+// priority порядок выбора стратегией priority, меньше — раньше
+// latitude, longitude координаты для стратегии nearest
+// is_default склад, на котором меняется остаток при изменении quantity товара, создании и импорте
+// active только с активных складов резервируется остаток заказов
+type Warehouse struct {
+	ID        uuid.UUID `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Priority  int       `json:"priority"`
+	Latitude  *float64  `json:"latitude"`
+	Longitude *float64  `json:"longitude"`
+	IsDefault bool      `json:"is_default"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// остаток товара или варианта на складе
+// available остаток за вычетом активных резервов заказов на этом складе
+type WarehouseStock struct {
+	WarehouseID uuid.UUID  `json:"warehouse_id"`
+	ProductID   uuid.UUID  `json:"product_id"`
+	VariantID   *uuid.UUID `json:"variant_id,omitempty"`
+	Quantity    int        `json:"quantity"`
+	Available   int        `json:"available"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// перемещение остатка между складами, проводится сразу при создании
+// actor кто создал перемещение
+type WarehouseTransfer struct {
+	ID              uuid.UUID               `json:"id"`
+	FromWarehouseID uuid.UUID               `json:"from_warehouse_id"`
+	ToWarehouseID   uuid.UUID               `json:"to_warehouse_id"`
+	Note            string                  `json:"note"`
+	Actor           string                  `json:"actor"`
+	Lines           []WarehouseTransferLine `json:"lines"`
+	CreatedAt       time.Time               `json:"created_at"`
+}
+
+// строка перемещения
+type WarehouseTransferLine struct {
+	ID        uuid.UUID  `json:"id"`
+	ProductID uuid.UUID  `json:"product_id"`
+	VariantID *uuid.UUID `json:"variant_id,omitempty"`
+	Quantity  int        `json:"quantity"`
+}
@@ -0,0 +1,22 @@
+package notify
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// Log writes events to the service log.
+type Log struct {
+	log *zap.Logger
+}
+
+func NewLog(log *zap.Logger) *Log {
+	return &Log{log: log}
+}
+
+func (n *Log) Notify(_ context.Context, e Event) error {
+	n.log.Warn("alert", zap.String("type", e.Type), zap.String("id", e.ID.String()),
+		zap.Time("occurred_at", e.OccurredAt), zap.Any("data", e.Data))
+	return nil
+}
@@ -0,0 +1,21 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event is an alert for operators; Data is the event payload, encoded as JSON by webhooks.
+type Event struct {
+	ID         uuid.UUID `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// Notifier delivers events; an error means the event was not delivered and may be retried.
+type Notifier interface {
+	Notify(ctx context.Context, e Event) error
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"store-service/internal/model"
+)
+
+const attributeColumns = `a.id, a.code, a.name, a.type, a.options, a.created_at, a.updated_at`
+
+type AttributeRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAttributeRepository(pool *pgxpool.Pool) *AttributeRepository {
+	return &AttributeRepository{pool: pool}
+}
+
+func (r *AttributeRepository) Create(ctx context.Context, a *model.Attribute) error {
+	now := time.Now().UTC()
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	if a.Options == nil {
+		a.Options = []string{}
+	}
+	a.CreatedAt = now
+	a.UpdatedAt = now
+
+	query := `INSERT INTO attributes (id, code, name, type, options, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := r.pool.Exec(ctx, query, a.ID, a.Code, a.Name, a.Type, a.Options, a.CreatedAt, a.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateAttribute
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *AttributeRepository) Get(ctx context.Context, id uuid.UUID) (model.Attribute, error) {
+	a, err := scanAttribute(r.pool.QueryRow(ctx, `SELECT `+attributeColumns+` FROM attributes a WHERE a.id=$1`, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return a, ErrNotFound
+		}
+		return a, err
+	}
+	return a, nil
+}
+
+// Update changes the definition; the code is immutable because product values are keyed by it.
+func (r *AttributeRepository) Update(ctx context.Context, a *model.Attribute) error {
+	if a.Options == nil {
+		a.Options = []string{}
+	}
+	a.UpdatedAt = time.Now().UTC()
+	query := `UPDATE attributes a SET name=$1, type=$2, options=$3, updated_at=$4 WHERE a.id=$5 RETURNING ` + attributeColumns
+	updated, err := scanAttribute(r.pool.QueryRow(ctx, query, a.Name, a.Type, a.Options, a.UpdatedAt, a.ID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+	*a = updated
+	return nil
+}
+
+func (r *AttributeRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	cmd, err := r.pool.Exec(ctx, `DELETE FROM attributes WHERE id=$1`, id)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *AttributeRepository) List(ctx context.Context) ([]model.Attribute, error) {
+	return r.queryAttributes(ctx, `SELECT `+attributeColumns+` FROM attributes a ORDER BY a.code ASC`)
+}
+
+// ListByCodes returns definitions for the given codes; unknown codes are skipped.
+func (r *AttributeRepository) ListByCodes(ctx context.Context, codes []string) ([]model.Attribute, error) {
+	return r.queryAttributes(ctx, `SELECT `+attributeColumns+` FROM attributes a WHERE a.code = ANY($1)`, codes)
+}
+
+// ReplaceForCategory replaces the attributes attached to the category; positions follow the slice order.
+func (r *AttributeRepository) ReplaceForCategory(ctx context.Context, categoryID uuid.UUID, attributeIDs []uuid.UUID) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.QueryRow(ctx, `SELECT id FROM categories WHERE id=$1 FOR UPDATE`, categoryID).Scan(new(uuid.UUID)); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	ids := uniqueIDs(attributeIDs)
+	var found int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM attributes WHERE id = ANY($1)`, ids).Scan(&found); err != nil {
+		return err
+	}
+	if found != len(ids) {
+		return ErrNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM category_attributes WHERE category_id=$1`, categoryID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO category_attributes (category_id, attribute_id, position)
+		SELECT $1, aid, pos - 1 FROM unnest($2::uuid[]) WITH ORDINALITY AS t(aid, pos)`, categoryID, ids); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ListByCategory returns attributes of the category, including ones attached to its ancestors.
+func (r *AttributeRepository) ListByCategory(ctx context.Context, categoryID uuid.UUID) ([]model.Attribute, error) {
+	if err := r.pool.QueryRow(ctx, `SELECT id FROM categories WHERE id=$1`, categoryID).Scan(new(uuid.UUID)); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	query := `SELECT ` + attributeColumns + `
+		FROM attributes a
+		JOIN category_attributes ca ON ca.attribute_id = a.id
+		JOIN categories c ON c.id = ca.category_id
+		JOIN categories f ON f.id = $1
+		WHERE c.id = f.id OR f.path LIKE c.path || '/%'
+		ORDER BY c.level ASC, ca.position ASC`
+	result, err := r.queryAttributes(ctx, query, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	return uniqueAttributes(result), nil
+}
+
+func (r *AttributeRepository) queryAttributes(ctx context.Context, query string, args ...any) ([]model.Attribute, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []model.Attribute
+	for rows.Next() {
+		a, err := scanAttribute(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, a)
+	}
+	return result, rows.Err()
+}
+
+// uniqueAttributes keeps the first occurrence of an attribute attached at several levels.
+func uniqueAttributes(list []model.Attribute) []model.Attribute {
+	seen := make(map[uuid.UUID]struct{}, len(list))
+	result := list[:0]
+	for _, a := range list {
+		if _, ok := seen[a.ID]; ok {
+			continue
+		}
+		seen[a.ID] = struct{}{}
+		result = append(result, a)
+	}
+	return result
+}
+
+func scanAttribute(row pgx.Row) (model.Attribute, error) {
+	var a model.Attribute
+	err := row.Scan(&a.ID, &a.Code, &a.Name, &a.Type, &a.Options, &a.CreatedAt, &a.UpdatedAt)
+	return a, err
+}
@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,6 +14,8 @@ import (
 	"store-service/internal/model"
 )
 
+const categoryColumns = `id, name, slug, parent_id, level, path, root_category_id, is_active, sort_order, created_at, updated_at`
+
 type CategoryRepository struct {
 	pool *pgxpool.Pool
 }
@@ -19,6 +24,8 @@ func NewCategoryRepository(pool *pgxpool.Pool) *CategoryRepository {
 	return &CategoryRepository{pool: pool}
 }
 
+// Create inserts a category placing it under its parent: level, path and root are derived from the parent row.
+// A taken slug gets a numeric suffix.
 func (r *CategoryRepository) Create(ctx context.Context, c *model.Category) error {
 	now := time.Now().UTC()
 	if c.ID == uuid.Nil {
@@ -27,23 +34,33 @@ func (r *CategoryRepository) Create(ctx context.Context, c *model.Category) erro
 	c.CreatedAt = now
 	c.UpdatedAt = now
 
-	query := `INSERT INTO categories 
-		(id, name, slug, parent_id, level, is_active, sort_order, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
 
-	_, err := r.pool.Exec(ctx, query, c.ID, c.Name, c.Slug, c.ParentID, c.Level, c.IsActive, c.SortOrder, c.CreatedAt, c.UpdatedAt)
-	return err
+	if err := placeCategory(ctx, tx, c, ""); err != nil {
+		return err
+	}
+	if c.Slug, err = claimSlug(ctx, tx, categorySlugs, c.ID, c.Slug); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO categories
+		(id, name, slug, parent_id, level, path, root_category_id, is_active, sort_order, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	if _, err := tx.Exec(ctx, query, c.ID, c.Name, c.Slug, c.ParentID, c.Level, c.Path, c.RootCategoryID, c.IsActive, c.SortOrder, c.CreatedAt, c.UpdatedAt); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 func (r *CategoryRepository) Get(ctx context.Context, id uuid.UUID) (model.Category, error) {
-	var c model.Category
-
-	query := `SELECT id, name, slug, parent_id, level, is_active, sort_order, created_at, updated_at 
-		FROM categories WHERE id = $1`
+	query := `SELECT ` + categoryColumns + ` FROM categories WHERE id = $1`
 
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&c.ID, &c.Name, &c.Slug, &c.ParentID, &c.Level, &c.IsActive, &c.SortOrder, &c.CreatedAt, &c.UpdatedAt,
-	)
+	c, err := scanCategory(r.pool.QueryRow(ctx, query, id))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return c, ErrNotFound
@@ -53,34 +70,235 @@ func (r *CategoryRepository) Get(ctx context.Context, id uuid.UUID) (model.Categ
 	return c, nil
 }
 
+// GetBySlug finds the category by its current slug or, with moved set, by an old slug it was renamed from.
+func (r *CategoryRepository) GetBySlug(ctx context.Context, slug string) (model.Category, bool, error) {
+	c, err := scanCategory(r.pool.QueryRow(ctx, `SELECT `+categoryColumns+` FROM categories WHERE slug = $1`, slug))
+	if err == nil {
+		return c, false, nil
+	}
+	if err != pgx.ErrNoRows {
+		return c, false, err
+	}
+	c, err = scanCategory(r.pool.QueryRow(ctx, `SELECT `+categoryColumns+` FROM categories
+		WHERE id = (SELECT category_id FROM category_slug_redirects WHERE slug = $1)`, slug))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return c, false, ErrNotFound
+		}
+		return c, false, err
+	}
+	return c, true, nil
+}
+
+// Update saves the category. When the parent changes, the whole subtree gets new path, level and root.
+// An empty slug keeps the current one; after a slug change the old slug redirects to the category.
 func (r *CategoryRepository) Update(ctx context.Context, c *model.Category) error {
 	c.UpdatedAt = time.Now().UTC()
-	query := `UPDATE categories SET name=$1, slug=$2, parent_id=$3, level=$4, is_active=$5, sort_order=$6, updated_at=$7 WHERE id=$8`
-	cmd, err := r.pool.Exec(ctx, query, c.Name, c.Slug, c.ParentID, c.Level, c.IsActive, c.SortOrder, c.UpdatedAt, c.ID)
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var oldPath, oldSlug string
+	var oldLevel int
+	err = tx.QueryRow(ctx, `SELECT path, level, slug, created_at FROM categories WHERE id=$1 FOR UPDATE`, c.ID).
+		Scan(&oldPath, &oldLevel, &oldSlug, &c.CreatedAt)
 	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
 		return err
 	}
-	if cmd.RowsAffected() == 0 {
-		return ErrNotFound
+
+	if err := placeCategory(ctx, tx, c, oldPath); err != nil {
+		return err
 	}
-	return nil
+	if c.Slug, err = setSlug(ctx, tx, categorySlugs, c.ID, oldSlug, c.Slug); err != nil {
+		return err
+	}
+
+	query := `UPDATE categories SET name=$1, slug=$2, parent_id=$3, level=$4, path=$5, root_category_id=$6, is_active=$7, sort_order=$8, updated_at=$9 WHERE id=$10`
+	if _, err := tx.Exec(ctx, query, c.Name, c.Slug, c.ParentID, c.Level, c.Path, c.RootCategoryID, c.IsActive, c.SortOrder, c.UpdatedAt, c.ID); err != nil {
+		return err
+	}
+
+	if c.Path != oldPath {
+		if err := rewriteSubtree(ctx, tx, oldPath, c.Path, c.Level-oldLevel, c.RootCategoryID, c.UpdatedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
 }
 
-func (r *CategoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	cmd, err := r.pool.Exec(ctx, `DELETE FROM categories WHERE id=$1`, id)
+// Delete removes the category according to strategy:
+// reject fails with ErrCategoryInUse when the category has children or products,
+// reparent lifts children (and product links) to the deleted category's parent,
+// cascade removes the whole subtree.
+func (r *CategoryRepository) Delete(ctx context.Context, id uuid.UUID, strategy model.CategoryDeleteStrategy) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return err
 	}
-	if cmd.RowsAffected() == 0 {
-		return ErrNotFound
+	defer tx.Rollback(ctx)
+
+	c, err := scanCategory(tx.QueryRow(ctx, `SELECT `+categoryColumns+` FROM categories WHERE id=$1 FOR UPDATE`, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
 	}
-	return nil
+
+	siblings, err := childIDs(ctx, tx, c.ParentID, c.ID)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+
+	switch strategy {
+	case model.CategoryDeleteReject:
+		var inUse bool
+		err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM categories WHERE parent_id=$1)
+			OR EXISTS (SELECT 1 FROM product_catagories WHERE catagory_id=$1)`, c.ID).Scan(&inUse)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return ErrCategoryInUse
+		}
+
+	case model.CategoryDeleteReparent:
+		children, err := childIDs(ctx, tx, &c.ID, uuid.Nil)
+		if err != nil {
+			return err
+		}
+		if err := liftSubtree(ctx, tx, c, now); err != nil {
+			return err
+		}
+		if c.ParentID != nil {
+			if _, err := tx.Exec(ctx, `INSERT INTO product_catagories (product_id, catagory_id, created_at, updated_at)
+				SELECT product_id, $2, $3, $3 FROM product_catagories WHERE catagory_id=$1
+				ON CONFLICT (product_id, catagory_id) DO NOTHING`, c.ID, *c.ParentID, now); err != nil {
+				return err
+			}
+		}
+		// Children take the deleted category's place among its siblings.
+		pos := c.SortOrder - 1
+		if pos < 0 || pos > len(siblings) {
+			pos = len(siblings)
+		}
+		ordered := make([]uuid.UUID, 0, len(siblings)+len(children))
+		ordered = append(ordered, siblings[:pos]...)
+		ordered = append(ordered, children...)
+		siblings = append(ordered, siblings[pos:]...)
+
+	case model.CategoryDeleteCascade:
+		if _, err := tx.Exec(ctx, `DELETE FROM categories WHERE path LIKE $1`, c.Path+"/%"); err != nil {
+			return err
+		}
+
+	default:
+		return ErrInvalidDeleteStrategy
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM categories WHERE id=$1`, c.ID); err != nil {
+		return err
+	}
+	if err := renumberCategories(ctx, tx, siblings, now); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
-func (r *CategoryRepository) List(ctx context.Context, limit, offset int) ([]model.Category, error) {
-	query := `SELECT id, name, slug, parent_id, level, is_active, sort_order, created_at, updated_at 
-		FROM categories ORDER BY sort_order ASC, created_at DESC LIMIT $1 OFFSET $2`
-	rows, err := r.pool.Query(ctx, query, limit, offset)
+var categoryKeyset = keyset{
+	columns: []string{"sort_order", "created_at", "id"},
+	types:   []string{"int", "timestamptz", "uuid"},
+}
+
+func (r *CategoryRepository) List(ctx context.Context, p PageRequest) ([]model.Category, Page, error) {
+	var b queryBuilder
+	var total *int64
+	if p.WithTotal {
+		var err error
+		if total, err = countRows(ctx, r.pool, "categories", b); err != nil {
+			return nil, Page{}, err
+		}
+	}
+	tail, err := categoryKeyset.paginate(&b, "", p)
+	if err != nil {
+		return nil, Page{}, err
+	}
+
+	query := `SELECT ` + categoryColumns + ` FROM categories` + b.whereClause() + tail
+	result, err := r.queryCategories(ctx, query, b.args...)
+	if err != nil {
+		return nil, Page{}, err
+	}
+
+	result, page := finishPage(result, p.Limit, "", func(c model.Category) []string {
+		return []string{strconv.Itoa(c.SortOrder), c.CreatedAt.Format(time.RFC3339Nano), c.ID.String()}
+	})
+	page.Total = total
+	return result, page, nil
+}
+
+const categorySubtreeQuery = `
+WITH RECURSIVE tree AS (
+    SELECT id, 0 AS depth, ARRAY[id] AS visited
+    FROM categories
+    WHERE %s AND (NOT $1::boolean OR is_active)
+    UNION ALL
+    SELECT c.id, t.depth + 1, t.visited || c.id
+    FROM categories c
+    JOIN tree t ON c.parent_id = t.id
+    WHERE NOT c.id = ANY(t.visited) AND (NOT $1::boolean OR c.is_active)
+)
+SELECT c.id, c.name, c.slug, c.parent_id, c.level, c.path, c.root_category_id, c.is_active, c.sort_order, c.created_at, c.updated_at
+FROM tree t
+JOIN categories c ON c.id = t.id
+ORDER BY t.depth ASC, c.sort_order ASC, c.created_at ASC`
+
+// Tree returns all categories reachable from root categories, ordered by depth and sort_order.
+func (r *CategoryRepository) Tree(ctx context.Context, activeOnly bool) ([]model.Category, error) {
+	return r.queryCategories(ctx, fmt.Sprintf(categorySubtreeQuery, "parent_id IS NULL"), activeOnly)
+}
+
+// Subtree returns the category with all its descendants, ordered by depth and sort_order.
+func (r *CategoryRepository) Subtree(ctx context.Context, id uuid.UUID, activeOnly bool) ([]model.Category, error) {
+	return r.queryCategories(ctx, fmt.Sprintf(categorySubtreeQuery, "id = $2"), activeOnly, id)
+}
+
+// Ancestors returns the chain from the root category down to the given category inclusive.
+func (r *CategoryRepository) Ancestors(ctx context.Context, id uuid.UUID) ([]model.Category, error) {
+	const q = `
+WITH RECURSIVE chain AS (
+    SELECT id, parent_id, 0 AS depth, ARRAY[id] AS visited
+    FROM categories
+    WHERE id = $1
+    UNION ALL
+    SELECT p.id, p.parent_id, ch.depth + 1, ch.visited || p.id
+    FROM categories p
+    JOIN chain ch ON p.id = ch.parent_id
+    WHERE NOT p.id = ANY(ch.visited)
+)
+SELECT c.id, c.name, c.slug, c.parent_id, c.level, c.path, c.root_category_id, c.is_active, c.sort_order, c.created_at, c.updated_at
+FROM chain ch
+JOIN categories c ON c.id = ch.id
+ORDER BY ch.depth DESC`
+	result, err := r.queryCategories(ctx, q, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, ErrNotFound
+	}
+	return result, nil
+}
+
+func (r *CategoryRepository) queryCategories(ctx context.Context, query string, args ...any) ([]model.Category, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -88,11 +306,242 @@ func (r *CategoryRepository) List(ctx context.Context, limit, offset int) ([]mod
 
 	var result []model.Category
 	for rows.Next() {
-		var c model.Category
-		if err := rows.Scan(&c.ID, &c.Name, &c.Slug, &c.ParentID, &c.Level, &c.IsActive, &c.SortOrder, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		c, err := scanCategory(rows)
+		if err != nil {
 			return nil, err
 		}
 		result = append(result, c)
 	}
 	return result, rows.Err()
 }
+
+func scanCategory(row pgx.Row) (model.Category, error) {
+	var c model.Category
+	err := row.Scan(&c.ID, &c.Name, &c.Slug, &c.ParentID, &c.Level, &c.Path, &c.RootCategoryID, &c.IsActive, &c.SortOrder, &c.CreatedAt, &c.UpdatedAt)
+	return c, err
+}
+
+// placeCategory derives level, path and root of c from its parent, locking the parent row.
+// currentPath is the stored path of c when it already exists; a parent inside it would form a cycle.
+func placeCategory(ctx context.Context, tx pgx.Tx, c *model.Category, currentPath string) error {
+	if c.ParentID == nil {
+		c.Level = 0
+		c.Path = c.ID.String()
+		root := c.ID
+		c.RootCategoryID = &root
+		return nil
+	}
+	if *c.ParentID == c.ID {
+		return ErrCategoryCycle
+	}
+
+	var parentLevel int
+	var parentPath string
+	var parentRoot *uuid.UUID
+	err := tx.QueryRow(ctx, `SELECT level, path, root_category_id FROM categories WHERE id=$1 FOR UPDATE`, *c.ParentID).
+		Scan(&parentLevel, &parentPath, &parentRoot)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrParentNotFound
+		}
+		return err
+	}
+	if currentPath != "" && strings.HasPrefix(parentPath+"/", currentPath+"/") {
+		return ErrCategoryCycle
+	}
+
+	c.Level = parentLevel + 1
+	c.Path = parentPath + "/" + c.ID.String()
+	c.RootCategoryID = parentRoot
+	return nil
+}
+
+// rewriteSubtree moves every descendant of oldPath under newPath, shifting levels by levelDelta.
+func rewriteSubtree(ctx context.Context, tx pgx.Tx, oldPath, newPath string, levelDelta int, root *uuid.UUID, now time.Time) error {
+	query := `UPDATE categories
+		SET path = $1 || substr(path, $2), level = level + $3, root_category_id = $4, updated_at = $5
+		WHERE path LIKE $6`
+	_, err := tx.Exec(ctx, query, newPath, len(oldPath)+1, levelDelta, root, now, oldPath+"/%")
+	return err
+}
+
+// Move places the category under parentID at the given sibling position (nil appends to the end);
+// a position outside 0..len(siblings) returns ErrInvalidPosition. The subtree, the new siblings and the former siblings are rewritten in one transaction.
+func (r *CategoryRepository) Move(ctx context.Context, id uuid.UUID, parentID *uuid.UUID, position *int) (model.Category, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return model.Category{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	c, err := scanCategory(tx.QueryRow(ctx, `SELECT `+categoryColumns+` FROM categories WHERE id=$1 FOR UPDATE`, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return c, ErrNotFound
+		}
+		return c, err
+	}
+	oldParentID, oldPath, oldLevel := c.ParentID, c.Path, c.Level
+
+	c.ParentID = parentID
+	if err := placeCategory(ctx, tx, &c, oldPath); err != nil {
+		return c, err
+	}
+
+	siblings, err := childIDs(ctx, tx, parentID, id)
+	if err != nil {
+		return c, err
+	}
+	pos := len(siblings)
+	if position != nil {
+		if *position < 0 || *position > len(siblings) {
+			return c, ErrInvalidPosition
+		}
+		pos = *position
+	}
+	ordered := make([]uuid.UUID, 0, len(siblings)+1)
+	ordered = append(ordered, siblings[:pos]...)
+	ordered = append(ordered, id)
+	ordered = append(ordered, siblings[pos:]...)
+
+	c.UpdatedAt = time.Now().UTC()
+	c.SortOrder = pos + 1
+	query := `UPDATE categories SET parent_id=$1, level=$2, path=$3, root_category_id=$4, updated_at=$5 WHERE id=$6`
+	if _, err := tx.Exec(ctx, query, c.ParentID, c.Level, c.Path, c.RootCategoryID, c.UpdatedAt, c.ID); err != nil {
+		return c, err
+	}
+	if c.Path != oldPath {
+		if err := rewriteSubtree(ctx, tx, oldPath, c.Path, c.Level-oldLevel, c.RootCategoryID, c.UpdatedAt); err != nil {
+			return c, err
+		}
+	}
+	if err := renumberCategories(ctx, tx, ordered, c.UpdatedAt); err != nil {
+		return c, err
+	}
+
+	if !sameParent(oldParentID, parentID) {
+		former, err := childIDs(ctx, tx, oldParentID, id)
+		if err != nil {
+			return c, err
+		}
+		if err := renumberCategories(ctx, tx, former, c.UpdatedAt); err != nil {
+			return c, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// ReorderChildren sets sort_order of the parent's children (root categories when parentID is nil)
+// to follow ids, which must list every child exactly once.
+func (r *CategoryRepository) ReorderChildren(ctx context.Context, parentID *uuid.UUID, ids []uuid.UUID) ([]model.Category, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if parentID != nil {
+		if err := tx.QueryRow(ctx, `SELECT id FROM categories WHERE id=$1 FOR UPDATE`, *parentID).Scan(new(uuid.UUID)); err != nil {
+			if err == pgx.ErrNoRows {
+				return nil, ErrNotFound
+			}
+			return nil, err
+		}
+	}
+
+	current, err := childIDs(ctx, tx, parentID, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(uniqueIDs(ids)) != len(ids) || len(ids) != len(current) {
+		return nil, ErrInvalidOrder
+	}
+	known := make(map[uuid.UUID]struct{}, len(current))
+	for _, id := range current {
+		known[id] = struct{}{}
+	}
+	for _, id := range ids {
+		if _, ok := known[id]; !ok {
+			return nil, ErrInvalidOrder
+		}
+	}
+
+	now := time.Now().UTC()
+	if err := renumberCategories(ctx, tx, ids, now); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + categoryColumns + ` FROM categories WHERE parent_id IS NOT DISTINCT FROM $1 ORDER BY sort_order ASC, created_at ASC`
+	return r.queryCategories(ctx, query, parentID)
+}
+
+// childIDs locks and returns children of parentID (roots when nil) in display order, skipping exclude.
+func childIDs(ctx context.Context, tx pgx.Tx, parentID *uuid.UUID, exclude uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := tx.Query(ctx, `SELECT id FROM categories
+		WHERE parent_id IS NOT DISTINCT FROM $1 AND id <> $2
+		ORDER BY sort_order ASC, created_at ASC
+		FOR UPDATE`, parentID, exclude)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// renumberCategories assigns sort_order 1..n following the order of ids.
+func renumberCategories(ctx context.Context, tx pgx.Tx, ids []uuid.UUID, now time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx, `UPDATE categories AS c SET sort_order = o.ord, updated_at = $2
+		FROM unnest($1::uuid[]) WITH ORDINALITY AS o(id, ord)
+		WHERE c.id = o.id AND c.sort_order <> o.ord`, ids, now)
+	return err
+}
+
+func sameParent(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// liftSubtree moves the descendants of c one level up, as if c was removed from the path.
+// Direct children of a root category become roots of their own subtrees.
+func liftSubtree(ctx context.Context, tx pgx.Tx, c model.Category, now time.Time) error {
+	if c.ParentID == nil {
+		query := `UPDATE categories
+			SET path = substr(path, $1), level = level - 1,
+				root_category_id = split_part(substr(path, $1), '/', 1)::uuid, updated_at = $2
+			WHERE path LIKE $3`
+		if _, err := tx.Exec(ctx, query, len(c.Path)+2, now, c.Path+"/%"); err != nil {
+			return err
+		}
+	} else {
+		parentPath := strings.TrimSuffix(c.Path, "/"+c.ID.String())
+		query := `UPDATE categories
+			SET path = $1 || substr(path, $2), level = level - 1, updated_at = $3
+			WHERE path LIKE $4`
+		if _, err := tx.Exec(ctx, query, parentPath, len(c.Path)+1, now, c.Path+"/%"); err != nil {
+			return err
+		}
+	}
+	_, err := tx.Exec(ctx, `UPDATE categories SET parent_id=$1 WHERE parent_id=$2`, c.ParentID, c.ID)
+	return err
+}
@@ -11,6 +11,8 @@ import (
 	"store-service/internal/model"
 )
 
+const customerColumns = `id, name, email, phone, address, created_at, updated_at`
+
 type CustomerRepository struct {
 	pool *pgxpool.Pool
 }
@@ -34,11 +36,7 @@ func (r *CustomerRepository) Create(ctx context.Context, c *model.Customer) erro
 }
 
 func (r *CustomerRepository) Get(ctx context.Context, id uuid.UUID) (model.Customer, error) {
-	var c model.Customer
-	query := `SELECT id, name, email, phone, address, created_at, updated_at FROM customers WHERE id=$1`
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&c.ID, &c.Name, &c.Email, &c.Phone, &c.Address, &c.CreatedAt, &c.UpdatedAt,
-	)
+	c, err := scanCustomer(r.pool.QueryRow(ctx, `SELECT `+customerColumns+` FROM customers WHERE id=$1`, id))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return c, ErrNotFound
@@ -72,21 +70,62 @@ func (r *CustomerRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-func (r *CustomerRepository) List(ctx context.Context, limit, offset int) ([]model.Customer, error) {
-	query := `SELECT id, name, email, phone, address, created_at, updated_at FROM customers ORDER BY created_at DESC LIMIT $1 OFFSET $2`
-	rows, err := r.pool.Query(ctx, query, limit, offset)
+var customerKeyset = keyset{
+	columns: []string{"created_at", "id"},
+	types:   []string{"timestamptz", "uuid"},
+	desc:    true,
+}
+
+func (r *CustomerRepository) List(ctx context.Context, p PageRequest) ([]model.Customer, Page, error) {
+	var b queryBuilder
+	var total *int64
+	if p.WithTotal {
+		var err error
+		if total, err = countRows(ctx, r.pool, "customers", b); err != nil {
+			return nil, Page{}, err
+		}
+	}
+	tail, err := customerKeyset.paginate(&b, "", p)
+	if err != nil {
+		return nil, Page{}, err
+	}
+
+	query := `SELECT ` + customerColumns + ` FROM customers` + b.whereClause() + tail
+	rows, err := r.pool.Query(ctx, query, b.args...)
 	if err != nil {
-		return nil, err
+		return nil, Page{}, err
 	}
 	defer rows.Close()
 
 	var result []model.Customer
 	for rows.Next() {
-		var c model.Customer
-		if err := rows.Scan(&c.ID, &c.Name, &c.Email, &c.Phone, &c.Address, &c.CreatedAt, &c.UpdatedAt); err != nil {
-			return nil, err
+		c, err := scanCustomer(rows)
+		if err != nil {
+			return nil, Page{}, err
 		}
 		result = append(result, c)
 	}
-	return result, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, Page{}, err
+	}
+
+	result, page := finishPage(result, p.Limit, "", func(c model.Customer) []string {
+		return []string{c.CreatedAt.Format(time.RFC3339Nano), c.ID.String()}
+	})
+	page.Total = total
+	return result, page, nil
+}
+
+// Export streams all customers in list order.
+func (r *CustomerRepository) Export(ctx context.Context, fn func([]model.Customer) error) error {
+	query := `SELECT ` + customerColumns + ` FROM customers ORDER BY ` + customerKeyset.orderBy()
+	return streamRows(ctx, r.pool, query, nil, scanCustomer, func(_ pgx.Tx, batch []model.Customer) error {
+		return fn(batch)
+	})
+}
+
+func scanCustomer(row pgx.Row) (model.Customer, error) {
+	var c model.Customer
+	err := row.Scan(&c.ID, &c.Name, &c.Email, &c.Phone, &c.Address, &c.CreatedAt, &c.UpdatedAt)
+	return c, err
 }
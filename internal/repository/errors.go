@@ -1,10 +1,126 @@
 package repository
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
 
 var (
 	// ErrNotFound is returned when record does not exist.
 	ErrNotFound = errors.New("not found")
 	// ErrNotEnoughStock is returned when there is not enough product quantity.
 	ErrNotEnoughStock = errors.New("not enough stock")
+	// ErrParentNotFound is returned when the referenced parent category does not exist.
+	ErrParentNotFound = errors.New("parent category not found")
+	// ErrCategoryCycle is returned when a category would become its own ancestor.
+	ErrCategoryCycle = errors.New("category cannot be placed under itself or its descendant")
+	// ErrInvalidOrder is returned when a reorder request does not list every child (or gallery item) exactly once.
+	ErrInvalidOrder = errors.New("ids must list every child exactly once")
+	// ErrCategoryInUse is returned when a category with children or products is deleted with the reject strategy.
+	ErrCategoryInUse = errors.New("category has children or products")
+	// ErrInvalidDeleteStrategy is returned for an unknown category delete strategy.
+	ErrInvalidDeleteStrategy = errors.New("unknown delete strategy")
+	// ErrInvalidSort is returned for an unsupported sort key.
+	ErrInvalidSort = errors.New("unsupported sort")
+	// ErrInvalidCursor is returned when a pagination cursor is malformed or belongs to another sort.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrDuplicateSKU is returned when a variant SKU or option combination is already taken.
+	ErrDuplicateSKU = errors.New("variant with this sku or options already exists")
+	// ErrInvalidVariantOptions is returned when variant options do not match the product option definitions.
+	ErrInvalidVariantOptions = errors.New("variant options do not match product options")
+	// ErrOptionsInUse is returned when new option definitions would invalidate existing variants.
+	ErrOptionsInUse = errors.New("existing variants do not match the new options")
+	// ErrVariantInUse is returned when a variant referenced by order items is deleted.
+	ErrVariantInUse = errors.New("variant is referenced by orders")
+	// ErrVariantRequired is returned when a product with variants is ordered without a variant.
+	ErrVariantRequired = errors.New("product has variants, variant_id is required")
+	// ErrDuplicateAttribute is returned when an attribute code is already taken.
+	ErrDuplicateAttribute = errors.New("attribute with this code already exists")
+	// ErrInvalidAttribute is returned for an attribute definition with a bad code, type or options.
+	ErrInvalidAttribute = errors.New("invalid attribute definition")
+	// ErrInvalidAttributeValue is returned when product attribute values do not match the definitions.
+	ErrInvalidAttributeValue = errors.New("attribute values do not match attribute definitions")
+	// ErrUnsupportedMedia is returned when an uploaded file is not a supported image.
+	ErrUnsupportedMedia = errors.New("unsupported media type, expected jpeg, png or gif")
+	// ErrPriceApplied is returned when an already applied price is cancelled.
+	ErrPriceApplied = errors.New("price change has already been applied")
+	// ErrPriceNotInFuture is returned when a scheduled price does not start in the future.
+	ErrPriceNotInFuture = errors.New("effective_from must be in the future")
+	// ErrInvalidCurrency is returned for an unsupported currency code.
+	ErrInvalidCurrency = errors.New("unsupported currency, expected one of RUB, KZT, BYN")
+	// ErrRateNotFound is returned when no exchange rate is known for a currency pair.
+	ErrRateNotFound = errors.New("exchange rate not found")
+	// ErrDuplicateRate is returned when a rate for the pair and effective time already exists.
+	ErrDuplicateRate = errors.New("exchange rate for this pair and time already exists")
+	// ErrInvalidRate is returned for a non-positive rate or a pair of the same currency.
+	ErrInvalidRate = errors.New("rate must be positive and currencies must differ")
+	// ErrProductArchived is returned when an archived product is added to an order.
+	ErrProductArchived = errors.New("product is archived")
+	// ErrDuplicateProductKey is returned when a product sku or external_id is already taken.
+	ErrDuplicateProductKey = errors.New("product with this sku or external_id already exists")
+	// ErrInvalidImportFile is returned when an import file cannot be read or has an invalid header.
+	ErrInvalidImportFile = errors.New("invalid import file")
+	// ErrInvalidSlug is returned for a slug without latin letters or digits after transliteration.
+	ErrInvalidSlug = errors.New("slug must contain letters or digits")
+	// ErrOrderNotOpen is returned when items are added to an order that is no longer new.
+	ErrOrderNotOpen = errors.New("items can only be added to a new order")
+	// ErrInvalidStockReason is returned for an unknown stock movement reason.
+	ErrInvalidStockReason = errors.New("reason must be one of sale, return, adjustment, receipt, transfer")
+	// ErrNoDefaultWarehouse is returned when stock changes without a warehouse and no default one is set.
+	ErrNoDefaultWarehouse = errors.New("default warehouse is not set")
+	// ErrDefaultWarehouse is returned when the default warehouse would be unset or deactivated.
+	ErrDefaultWarehouse = errors.New("default warehouse must stay default and active, make another warehouse default instead")
+	// ErrDuplicateWarehouseCode is returned when a warehouse code is already taken.
+	ErrDuplicateWarehouseCode = errors.New("warehouse with this code already exists")
+	// ErrInvalidTransfer is returned for a transfer within one warehouse, to an inactive one or without lines.
+	ErrInvalidTransfer = errors.New("transfer needs two different warehouses, an active destination and lines with positive quantities")
+	// ErrInvalidWarehouse is returned for a warehouse without code or name or with incomplete coordinates.
+	ErrInvalidWarehouse = errors.New("code and name are required, latitude and longitude must be set together and be in range")
+	// ErrInvalidShippingPoint is returned for an order delivery point with one or out-of-range coordinates.
+	ErrInvalidShippingPoint = errors.New("shipping_latitude and shipping_longitude must be set together and be in range")
+	// ErrWarehouseInUse is returned when a warehouse with stock, movements or orders is deleted.
+	ErrWarehouseInUse = errors.New("warehouse has stock history, deactivate it instead")
+	// ErrInvalidReorderPoint is returned for a negative reorder point.
+	ErrInvalidReorderPoint = errors.New("reorder_point must not be negative")
+	// ErrInvalidSupplier is returned for a supplier without a name.
+	ErrInvalidSupplier = errors.New("name is required")
+	// ErrSupplierInUse is returned when a supplier with purchase orders is deleted.
+	ErrSupplierInUse = errors.New("supplier has purchase orders")
+	// ErrInvalidPurchaseOrder is returned for a purchase order without lines or with a non-positive quantity or negative cost.
+	ErrInvalidPurchaseOrder = errors.New("purchase order needs lines with positive quantities and non-negative unit costs")
+	// ErrPurchaseOrderClosed is returned when a received or cancelled purchase order is received or cancelled again.
+	ErrPurchaseOrderClosed = errors.New("purchase order is already received or cancelled")
+	// ErrInvalidPurchaseOrderStatus is returned for an unknown purchase order status filter.
+	ErrInvalidPurchaseOrderStatus = errors.New("status must be one of open, partially_received, received, cancelled")
+	// ErrInvalidReceipt is returned for a receipt without lines, with a non-positive quantity or a negative extra cost.
+	ErrInvalidReceipt = errors.New("receipt needs lines with positive quantities and a non-negative extra_cost")
+	// ErrOverReceipt is returned when a receipt brings more than is still expected on a purchase order line.
+	ErrOverReceipt = errors.New("received quantity exceeds the quantity still expected on the line")
+	// ErrStocktakeInProgress is returned when a stocktake is opened for a warehouse that is already being counted.
+	ErrStocktakeInProgress = errors.New("warehouse already has an open stocktake")
+	// ErrStocktakeClosed is returned when a committed or cancelled stocktake is counted, committed or cancelled.
+	ErrStocktakeClosed = errors.New("stocktake is already committed or cancelled")
+	// ErrInvalidStocktakeCount is returned for counts without lines, with a negative quantity or a count time outside the stocktake.
+	ErrInvalidStocktakeCount = errors.New("counts need lines with non-negative quantities counted after the stocktake was opened and not in the future")
+	// ErrInvalidStocktakeStatus is returned for an unknown stocktake status filter.
+	ErrInvalidStocktakeStatus = errors.New("status must be one of open, committed, cancelled")
+	// ErrInvalidOrderStatus is returned when an order is moved to an unknown status.
+	ErrInvalidOrderStatus = errors.New("status must be one of new, paid, cancelled")
+	// ErrInvalidStatusTransition is returned when an order is moved to a status its current status does not lead to.
+	ErrInvalidStatusTransition = errors.New("order status can only change from new to paid or cancelled and from paid to cancelled")
+	// ErrImageTooLarge is returned when an uploaded image has more pixels than allowed.
+	ErrImageTooLarge = errors.New("image dimensions exceed the allowed number of pixels")
+	// ErrInvalidPosition is returned when a category is moved to a position outside its new siblings.
+	ErrInvalidPosition = errors.New("position must be between 0 and the number of siblings")
 )
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+
+	"store-service/internal/model"
+)
+
+const exchangeRateColumns = `id, base_currency, quote_currency, rate, effective_at, created_at`
+
+type ExchangeRateRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewExchangeRateRepository(pool *pgxpool.Pool) *ExchangeRateRepository {
+	return &ExchangeRateRepository{pool: pool}
+}
+
+func (r *ExchangeRateRepository) Create(ctx context.Context, rate *model.ExchangeRate) error {
+	if rate.ID == uuid.Nil {
+		rate.ID = uuid.New()
+	}
+	rate.CreatedAt = time.Now().UTC()
+	if rate.EffectiveAt.IsZero() {
+		rate.EffectiveAt = rate.CreatedAt
+	}
+
+	_, err := r.pool.Exec(ctx, `INSERT INTO exchange_rates (`+exchangeRateColumns+`) VALUES ($1, $2, $3, $4, $5, $6)`,
+		rate.ID, rate.Base, rate.Quote, rate.Rate, rate.EffectiveAt, rate.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateRate
+		}
+		return err
+	}
+	return nil
+}
+
+// List returns rates, latest first; empty base or quote matches any currency.
+func (r *ExchangeRateRepository) List(ctx context.Context, base, quote model.Currency) ([]model.ExchangeRate, error) {
+	var b queryBuilder
+	if base != "" {
+		b.where("base_currency = " + b.arg(base))
+	}
+	if quote != "" {
+		b.where("quote_currency = " + b.arg(quote))
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT `+exchangeRateColumns+` FROM exchange_rates`+b.whereClause()+`
+		ORDER BY effective_at DESC, base_currency, quote_currency`, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []model.ExchangeRate
+	for rows.Next() {
+		var e model.ExchangeRate
+		if err := rows.Scan(&e.ID, &e.Base, &e.Quote, &e.Rate, &e.EffectiveAt, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
+
+func (r *ExchangeRateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	cmd, err := r.pool.Exec(ctx, `DELETE FROM exchange_rates WHERE id=$1`, id)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Rates returns how many target units one unit of each source currency is worth at the given time.
+// Currencies without a known rate to the target are left out of the map.
+func (r *ExchangeRateRepository) Rates(ctx context.Context, from []model.Currency, to model.Currency, at time.Time) (map[model.Currency]decimal.Decimal, error) {
+	codes := make([]string, 0, len(from))
+	for _, c := range from {
+		codes = append(codes, string(c))
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT c, exchange_rate(c, $2, $3) FROM unnest($1::text[]) AS c`, codes, to, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[model.Currency]decimal.Decimal, len(from))
+	for rows.Next() {
+		var c model.Currency
+		var rate *decimal.Decimal
+		if err := rows.Scan(&c, &rate); err != nil {
+			return nil, err
+		}
+		if rate != nil {
+			result[c] = *rate
+		}
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// exportBatchSize is how many rows one FETCH from an export cursor returns.
+const exportBatchSize = 500
+
+// querier is satisfied by both the pool and a transaction.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// streamRows runs query through a server-side cursor in a read-only REPEATABLE READ transaction
+// and hands the rows to fn batch by batch, so a dump of any size is never held in memory and
+// all batches see the same snapshot. fn may run more queries in tx between batches.
+func streamRows[T any](ctx context.Context, pool *pgxpool.Pool, query string, args []any,
+	scan func(pgx.Row) (T, error), fn func(tx pgx.Tx, batch []T) error) error {
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DECLARE export_cursor NO SCROLL CURSOR FOR `+query, args...); err != nil {
+		return err
+	}
+	for {
+		rows, err := tx.Query(ctx, `FETCH FORWARD `+strconv.Itoa(exportBatchSize)+` FROM export_cursor`)
+		if err != nil {
+			return err
+		}
+		batch, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (T, error) {
+			return scan(row)
+		})
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return tx.Commit(ctx)
+		}
+		if err := fn(tx, batch); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+
+	"store-service/internal/model"
+)
+
+const importJobColumns = `id, filename, format, dry_run, status, total_rows, processed_rows,
+	created_count, updated_count, error_count, report, COALESCE(error, ''), created_at, updated_at, started_at, finished_at`
+
+// ProductImport is a validated import row. Nil fields keep the current values of the matched
+// product; a new product gets zero quantity, no categories and the default currency.
+// Attributes are merged into the current values. Slug is only used for a new product.
+type ProductImport struct {
+	Slug        string
+	SKU         *string
+	ExternalID  *string
+	Name        string
+	Price       decimal.Decimal
+	Currency    model.Currency
+	Quantity    *int
+	CategoryIDs []uuid.UUID
+	Attributes  map[string]any
+}
+
+type ImportRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewImportRepository(pool *pgxpool.Pool) *ImportRepository {
+	return &ImportRepository{pool: pool}
+}
+
+// CreateJob stores the job; payload is kept until the job finishes.
+func (r *ImportRepository) CreateJob(ctx context.Context, job *model.ImportJob, payload []byte) error {
+	now := time.Now().UTC()
+	if job.ID == uuid.Nil {
+		job.ID = uuid.New()
+	}
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	if job.Status == model.ImportRunning {
+		job.StartedAt = &now
+	}
+	if job.Report == nil {
+		job.Report = []model.ImportRowResult{}
+	}
+
+	_, err := r.pool.Exec(ctx, `INSERT INTO import_jobs
+		(id, filename, format, dry_run, status, payload, total_rows, report, created_at, updated_at, started_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9, $10)`,
+		job.ID, job.Filename, job.Format, job.DryRun, job.Status, payload, job.TotalRows, job.Report, now, job.StartedAt)
+	return err
+}
+
+func (r *ImportRepository) GetJob(ctx context.Context, id uuid.UUID) (model.ImportJob, error) {
+	job, err := scanImportJob(r.pool.QueryRow(ctx, `SELECT `+importJobColumns+` FROM import_jobs WHERE id=$1`, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return job, ErrNotFound
+		}
+		return job, err
+	}
+	return job, nil
+}
+
+// ClaimJob marks the oldest pending job as running and returns it with its payload. Running jobs
+// without progress since staleBefore are claimed again. ErrNotFound means there is nothing to do.
+func (r *ImportRepository) ClaimJob(ctx context.Context, staleBefore time.Time) (model.ImportJob, []byte, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return model.ImportJob{}, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT id FROM import_jobs
+		WHERE status = 'pending' OR (status = 'running' AND updated_at < $1)
+		ORDER BY created_at ASC
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED`, staleBefore).Scan(&id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return model.ImportJob{}, nil, ErrNotFound
+		}
+		return model.ImportJob{}, nil, err
+	}
+
+	// A reclaimed job starts over, so its counters are reset.
+	now := time.Now().UTC()
+	job, err := scanImportJob(tx.QueryRow(ctx, `UPDATE import_jobs
+		SET status='running', processed_rows=0, created_count=0, updated_count=0, error_count=0, report='[]',
+			started_at=$2, updated_at=$2
+		WHERE id=$1
+		RETURNING `+importJobColumns, id, now))
+	if err != nil {
+		return job, nil, err
+	}
+	var payload []byte
+	if err := tx.QueryRow(ctx, `SELECT payload FROM import_jobs WHERE id=$1`, id).Scan(&payload); err != nil {
+		return job, nil, err
+	}
+	return job, payload, tx.Commit(ctx)
+}
+
+// SaveProgress stores the counters of a running job; it also marks the job as alive.
+func (r *ImportRepository) SaveProgress(ctx context.Context, job *model.ImportJob) error {
+	job.UpdatedAt = time.Now().UTC()
+	_, err := r.pool.Exec(ctx, `UPDATE import_jobs
+		SET total_rows=$2, processed_rows=$3, created_count=$4, updated_count=$5, error_count=$6, updated_at=$7
+		WHERE id=$1`,
+		job.ID, job.TotalRows, job.ProcessedRows, job.Created, job.Updated, job.Failed, job.UpdatedAt)
+	return err
+}
+
+// FinishJob stores the final state and report of the job and drops its payload.
+func (r *ImportRepository) FinishJob(ctx context.Context, job *model.ImportJob) error {
+	now := time.Now().UTC()
+	job.UpdatedAt = now
+	job.FinishedAt = &now
+	if job.Report == nil {
+		job.Report = []model.ImportRowResult{}
+	}
+	_, err := r.pool.Exec(ctx, `UPDATE import_jobs
+		SET status=$2, total_rows=$3, processed_rows=$4, created_count=$5, updated_count=$6, error_count=$7,
+			report=$8, error=NULLIF($9, ''), payload=NULL, updated_at=$10, finished_at=$10
+		WHERE id=$1`,
+		job.ID, job.Status, job.TotalRows, job.ProcessedRows, job.Created, job.Updated, job.Failed,
+		job.Report, job.Error, now)
+	return err
+}
+
+// CategoryIDsBySlugs resolves category slugs; unknown slugs are left out of the map.
+func (r *ImportRepository) CategoryIDsBySlugs(ctx context.Context, slugs []string) (map[string]uuid.UUID, error) {
+	rows, err := r.pool.Query(ctx, `SELECT slug, id FROM categories WHERE slug = ANY($1)`, slugs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]uuid.UUID, len(slugs))
+	for rows.Next() {
+		var slug string
+		var id uuid.UUID
+		if err := rows.Scan(&slug, &id); err != nil {
+			return nil, err
+		}
+		result[slug] = id
+	}
+	return result, rows.Err()
+}
+
+// FindProduct returns the product matched by the row keys: external_id first, then sku.
+func (r *ImportRepository) FindProduct(ctx context.Context, sku, externalID *string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := r.pool.QueryRow(ctx, `SELECT id FROM products WHERE `+importMatch, externalID, sku).Scan(&id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return id, ErrNotFound
+		}
+		return id, err
+	}
+	return id, nil
+}
+
+// importMatch selects the product by external_id ($1) or sku ($2), preferring external_id.
+const importMatch = `external_id = $1 OR sku = $2
+	ORDER BY (external_id = $1) IS TRUE DESC
+	LIMIT 1`
+
+// UpsertProduct creates the product or updates the one matched by external_id or sku.
+// Price changes are recorded in the price history; stock is changed in the default warehouse
+// with the job as reference of the movement.
+func (r *ImportRepository) UpsertProduct(ctx context.Context, jobID uuid.UUID, p ProductImport, defaultCurrency model.Currency) (uuid.UUID, bool, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		id          uuid.UUID
+		oldPrice    decimal.Decimal
+		oldCurrency model.Currency
+		oldQuantity int
+	)
+	created := false
+	err = tx.QueryRow(ctx, `SELECT id, price, currency, quantity FROM products WHERE `+importMatch+` FOR UPDATE`, p.ExternalID, p.SKU).
+		Scan(&id, &oldPrice, &oldCurrency, &oldQuantity)
+	switch {
+	case err == pgx.ErrNoRows:
+		created = true
+	case err != nil:
+		return uuid.Nil, false, err
+	}
+
+	currency := p.Currency
+	if currency == "" {
+		currency = oldCurrency
+		if created {
+			currency = defaultCurrency
+		}
+	}
+	price := currency.Round(p.Price)
+	attrs := p.Attributes
+	if attrs == nil {
+		attrs = map[string]any{}
+	}
+	now := time.Now().UTC()
+
+	if created {
+		id = uuid.New()
+		var slug string
+		if slug, err = claimSlug(ctx, tx, productSlugs, id, p.Slug); err != nil {
+			return uuid.Nil, false, err
+		}
+		_, err = tx.Exec(ctx, `INSERT INTO products (id, slug, sku, external_id, name, price, currency, quantity, attributes, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, 0, $8, $9, $9)`,
+			id, slug, p.SKU, p.ExternalID, p.Name, price, currency, attrs, now)
+	} else {
+		_, err = tx.Exec(ctx, `UPDATE products
+			SET sku=COALESCE($2, sku), external_id=COALESCE($3, external_id), name=$4, price=$5, currency=$6,
+				attributes=attributes || $7, updated_at=$8
+			WHERE id=$1`,
+			id, p.SKU, p.ExternalID, p.Name, price, currency, attrs, now)
+	}
+	if err != nil {
+		if isUniqueViolation(err) {
+			return uuid.Nil, false, ErrDuplicateProductKey
+		}
+		return uuid.Nil, false, err
+	}
+	if created || !oldPrice.Equal(price) || oldCurrency != currency {
+		if err := recordPrice(ctx, tx, id, price, currency, now); err != nil {
+			return uuid.Nil, false, err
+		}
+	}
+	if p.Quantity != nil {
+		reason := model.StockAdjustment
+		if created {
+			reason = model.StockReceipt
+		}
+		if err := adjustDefaultStock(ctx, tx, id, nil, *p.Quantity-oldQuantity, reason, &jobID, now); err != nil {
+			return uuid.Nil, false, err
+		}
+	}
+
+	if p.CategoryIDs != nil {
+		if _, err := tx.Exec(ctx, `DELETE FROM product_catagories WHERE product_id=$1`, id); err != nil {
+			return uuid.Nil, false, err
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO product_catagories (product_id, catagory_id, created_at, updated_at)
+			SELECT $1, cid, $3, $3 FROM unnest($2::uuid[]) AS cid
+			ON CONFLICT (product_id, catagory_id) DO NOTHING`, id, uniqueIDs(p.CategoryIDs), now); err != nil {
+			return uuid.Nil, false, err
+		}
+	}
+	return id, created, tx.Commit(ctx)
+}
+
+func scanImportJob(row pgx.Row) (model.ImportJob, error) {
+	var j model.ImportJob
+	err := row.Scan(&j.ID, &j.Filename, &j.Format, &j.DryRun, &j.Status, &j.TotalRows, &j.ProcessedRows,
+		&j.Created, &j.Updated, &j.Failed, &j.Report, &j.Error, &j.CreatedAt, &j.UpdatedAt, &j.StartedAt, &j.FinishedAt)
+	return j, err
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"store-service/internal/model"
+)
+
+const mediaColumns = `id, product_id, storage_key, content_type, size_bytes, width, height, thumbnails, position, is_primary, created_at, updated_at`
+
+type MediaRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewMediaRepository(pool *pgxpool.Pool) *MediaRepository {
+	return &MediaRepository{pool: pool}
+}
+
+// Create appends media to the end of the product gallery. The first media of a product
+// becomes primary; a new primary media takes the flag from the previous one.
+func (r *MediaRepository) Create(ctx context.Context, m *model.ProductMedia) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.QueryRow(ctx, `SELECT id FROM products WHERE id=$1 FOR UPDATE`, m.ProductID).Scan(new(uuid.UUID)); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	var count int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*), COALESCE(MAX(position) + 1, 0) FROM product_media WHERE product_id=$1`,
+		m.ProductID).Scan(&count, &m.Position); err != nil {
+		return err
+	}
+	if count == 0 {
+		m.IsPrimary = true
+	}
+	if m.IsPrimary {
+		if _, err := tx.Exec(ctx, `UPDATE product_media SET is_primary=FALSE WHERE product_id=$1 AND is_primary`, m.ProductID); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now().UTC()
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	m.CreatedAt = now
+	m.UpdatedAt = now
+	if m.Thumbnails == nil {
+		m.Thumbnails = []model.MediaThumbnail{}
+	}
+
+	query := `INSERT INTO product_media (` + mediaColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+	if _, err := tx.Exec(ctx, query, m.ID, m.ProductID, m.Key, m.ContentType, m.Size, m.Width, m.Height,
+		m.Thumbnails, m.Position, m.IsPrimary, m.CreatedAt, m.UpdatedAt); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *MediaRepository) Get(ctx context.Context, productID, id uuid.UUID) (model.ProductMedia, error) {
+	m, err := scanMedia(r.pool.QueryRow(ctx, `SELECT `+mediaColumns+` FROM product_media WHERE id=$1 AND product_id=$2`, id, productID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return m, ErrNotFound
+		}
+		return m, err
+	}
+	return m, nil
+}
+
+// List returns the product gallery in display order.
+func (r *MediaRepository) List(ctx context.Context, productID uuid.UUID) ([]model.ProductMedia, error) {
+	if err := r.pool.QueryRow(ctx, `SELECT id FROM products WHERE id=$1`, productID).Scan(new(uuid.UUID)); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT `+mediaColumns+` FROM product_media WHERE product_id=$1 ORDER BY position ASC, created_at ASC`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []model.ProductMedia
+	for rows.Next() {
+		m, err := scanMedia(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, m)
+	}
+	return result, rows.Err()
+}
+
+// ListForProducts returns galleries of several products keyed by product id.
+func (r *MediaRepository) ListForProducts(ctx context.Context, productIDs []uuid.UUID) (map[uuid.UUID][]model.ProductMedia, error) {
+	result := make(map[uuid.UUID][]model.ProductMedia, len(productIDs))
+	if len(productIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT `+mediaColumns+` FROM product_media
+		WHERE product_id = ANY($1) ORDER BY product_id, position ASC, created_at ASC`, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		m, err := scanMedia(rows)
+		if err != nil {
+			return nil, err
+		}
+		result[m.ProductID] = append(result[m.ProductID], m)
+	}
+	return result, rows.Err()
+}
+
+// Reorder sets gallery positions; ids must list every media of the product exactly once.
+func (r *MediaRepository) Reorder(ctx context.Context, productID uuid.UUID, ids []uuid.UUID) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.QueryRow(ctx, `SELECT id FROM products WHERE id=$1 FOR UPDATE`, productID).Scan(new(uuid.UUID)); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	var total, matched int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*), COUNT(*) FILTER (WHERE id = ANY($2)) FROM product_media WHERE product_id=$1`,
+		productID, ids).Scan(&total, &matched); err != nil {
+		return err
+	}
+	if len(uniqueIDs(ids)) != len(ids) || matched != len(ids) || total != len(ids) {
+		return ErrInvalidOrder
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE product_media m SET position = t.pos - 1, updated_at = $3
+		FROM unnest($2::uuid[]) WITH ORDINALITY AS t(id, pos)
+		WHERE m.id = t.id AND m.product_id = $1`, productID, ids, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// SetPrimary moves the primary flag of the product gallery to the media.
+func (r *MediaRepository) SetPrimary(ctx context.Context, productID, id uuid.UUID) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.QueryRow(ctx, `SELECT id FROM product_media WHERE id=$1 AND product_id=$2 FOR UPDATE`, id, productID).Scan(new(uuid.UUID)); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	now := time.Now().UTC()
+	if _, err := tx.Exec(ctx, `UPDATE product_media SET is_primary=FALSE, updated_at=$2 WHERE product_id=$1 AND is_primary`, productID, now); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE product_media SET is_primary=TRUE, updated_at=$2 WHERE id=$1`, id, now); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Delete removes the media row and returns it so that the caller can remove stored files.
+// When the primary media is removed the first remaining one becomes primary.
+func (r *MediaRepository) Delete(ctx context.Context, productID, id uuid.UUID) (model.ProductMedia, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return model.ProductMedia{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	m, err := scanMedia(tx.QueryRow(ctx, `DELETE FROM product_media WHERE id=$1 AND product_id=$2 RETURNING `+mediaColumns, id, productID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return m, ErrNotFound
+		}
+		return m, err
+	}
+
+	if m.IsPrimary {
+		_, err := tx.Exec(ctx, `UPDATE product_media SET is_primary=TRUE, updated_at=$2
+			WHERE id = (SELECT id FROM product_media WHERE product_id=$1 ORDER BY position ASC, created_at ASC LIMIT 1)`,
+			productID, time.Now().UTC())
+		if err != nil {
+			return m, err
+		}
+	}
+	return m, tx.Commit(ctx)
+}
+
+func scanMedia(row pgx.Row) (model.ProductMedia, error) {
+	var m model.ProductMedia
+	err := row.Scan(&m.ID, &m.ProductID, &m.Key, &m.ContentType, &m.Size, &m.Width, &m.Height,
+		&m.Thumbnails, &m.Position, &m.IsPrimary, &m.CreatedAt, &m.UpdatedAt)
+	return m, err
+}
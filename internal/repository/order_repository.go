@@ -12,6 +12,10 @@ import (
 	"store-service/internal/model"
 )
 
+const orderColumns = `id, customer_id, total_price, currency, status, created_at, updated_at, shipping_latitude, shipping_longitude`
+
+const orderItemColumns = `id, order_id, product_id, variant_id, product_name, quantity, unit_price, sub_total, created_at, updated_at, warehouse_id`
+
 type OrderRepository struct {
 	pool *pgxpool.Pool
 }
@@ -26,7 +30,7 @@ func (r *OrderRepository) Create(ctx context.Context, o *model.Order) error {
 		o.ID = uuid.New()
 	}
 	if o.Status == "" {
-		o.Status = "new"
+		o.Status = model.OrderStatusNew
 	}
 	o.CreatedAt = now
 	o.UpdatedAt = now
@@ -34,39 +38,159 @@ func (r *OrderRepository) Create(ctx context.Context, o *model.Order) error {
 		o.TotalPrice = decimal.Zero
 	}
 
-	query := `INSERT INTO orders (id, customer_id, total_price, status, created_at, updated_at)
-			  VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err := r.pool.Exec(ctx, query, o.ID, o.CustomerID, o.TotalPrice, o.Status, o.CreatedAt, o.UpdatedAt)
+	query := `INSERT INTO orders (` + orderColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	_, err := r.pool.Exec(ctx, query, o.ID, o.CustomerID, o.TotalPrice, o.Currency, o.Status, o.CreatedAt, o.UpdatedAt,
+		o.ShippingLatitude, o.ShippingLongitude)
 	return err
 }
 
+// UpdateStatus changes the order status. Paying an order turns its stock holds into a real
+// decrement, holds that have expired meanwhile are taken again if the stock is still there;
+// cancelling releases the holds and returns the stock of a paid order. Items added before
+// reservations existed hold nothing and are not decremented again. Only new→paid, new→cancelled
+// and paid→cancelled are allowed, anything else returns ErrInvalidStatusTransition.
 func (r *OrderRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
-	cmd, err := r.pool.Exec(ctx, `UPDATE orders SET status=$1, updated_at=$2 WHERE id=$3`, status, time.Now().UTC(), id)
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var current string
+	if err := tx.QueryRow(ctx, `SELECT status FROM orders WHERE id=$1 FOR UPDATE`, id).Scan(&current); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+	if !model.OrderStatusTransitionAllowed(current, status) {
+		return ErrInvalidStatusTransition
+	}
+
+	now := time.Now().UTC()
+	switch status {
+	case model.OrderStatusPaid:
+		if err := commitReservations(ctx, tx, id, now); err != nil {
+			return err
+		}
+	case model.OrderStatusCancelled:
+		if _, err := tx.Exec(ctx, `UPDATE stock_reservations SET status='released', updated_at=$1
+			WHERE order_id=$2 AND status IN ('active', 'expired')`, now, id); err != nil {
+			return err
+		}
+		if err := returnReservations(ctx, tx, id, now); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE orders SET status=$1, updated_at=$2 WHERE id=$3`, status, now, id); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// commitReservations decrements stock by the order holds, records the sales and marks the holds committed.
+func commitReservations(ctx context.Context, tx pgx.Tx, orderID uuid.UUID, now time.Time) error {
+	holds, err := orderHolds(ctx, tx, orderID, "active", "expired")
+	if err != nil {
+		return err
+	}
+
+	for _, h := range holds {
+		if _, err := lockStock(ctx, tx, h.productID, h.variantID); err != nil {
+			return err
+		}
+		available, err := warehouseAvailable(ctx, tx, h.warehouseID, h.productID, h.variantID, orderID)
+		if err != nil {
+			return err
+		}
+		if available < h.qty {
+			return ErrNotEnoughStock
+		}
+		if err := changeStock(ctx, tx, h.warehouseID, h.productID, h.variantID, -h.qty, model.StockSale, &orderID, now); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE stock_reservations SET status='committed', updated_at=$1
+		WHERE order_id=$2 AND status IN ('active', 'expired')`, now, orderID)
+	return err
+}
+
+// returnReservations puts stock taken by a paid order back to its warehouses and records the returns.
+func returnReservations(ctx context.Context, tx pgx.Tx, orderID uuid.UUID, now time.Time) error {
+	holds, err := orderHolds(ctx, tx, orderID, "committed")
 	if err != nil {
 		return err
 	}
-	if cmd.RowsAffected() == 0 {
-		return ErrNotFound
+	for _, h := range holds {
+		if _, err := lockStock(ctx, tx, h.productID, h.variantID); err != nil {
+			return err
+		}
+		if err := changeStock(ctx, tx, h.warehouseID, h.productID, h.variantID, h.qty, model.StockReturn, &orderID, now); err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec(ctx, `UPDATE stock_reservations SET status='returned', updated_at=$1
+		WHERE order_id=$2 AND status='committed'`, now, orderID)
+	return err
+}
+
+type orderHold struct {
+	warehouseID uuid.UUID
+	productID   uuid.UUID
+	variantID   *uuid.UUID
+	qty         int
+}
+
+// orderHolds sums the order reservations in the given statuses per product, variant and warehouse,
+// in id order so that rows are locked in the same order by concurrent transactions.
+func orderHolds(ctx context.Context, tx pgx.Tx, orderID uuid.UUID, statuses ...string) ([]orderHold, error) {
+	rows, err := tx.Query(ctx, `SELECT warehouse_id, product_id, variant_id, SUM(quantity)::int FROM stock_reservations
+		WHERE order_id=$1 AND status = ANY($2)
+		GROUP BY product_id, variant_id, warehouse_id
+		ORDER BY product_id, variant_id NULLS FIRST, warehouse_id`, orderID, statuses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holds []orderHold
+	for rows.Next() {
+		var h orderHold
+		if err := rows.Scan(&h.warehouseID, &h.productID, &h.variantID, &h.qty); err != nil {
+			return nil, err
+		}
+		holds = append(holds, h)
 	}
-	return nil
+	return holds, rows.Err()
 }
 
+// Delete removes the order; stock taken by a paid order is returned, holds of an unpaid one are dropped.
 func (r *OrderRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	cmd, err := r.pool.Exec(ctx, `DELETE FROM orders WHERE id=$1`, id)
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return err
 	}
-	if cmd.RowsAffected() == 0 {
-		return ErrNotFound
+	defer tx.Rollback(ctx)
+
+	if err := tx.QueryRow(ctx, `SELECT id FROM orders WHERE id=$1 FOR UPDATE`, id).Scan(new(uuid.UUID)); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+	if err := returnReservations(ctx, tx, id, time.Now().UTC()); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM orders WHERE id=$1`, id); err != nil {
+		return err
 	}
-	return nil
+	return tx.Commit(ctx)
 }
 
 func (r *OrderRepository) Get(ctx context.Context, id uuid.UUID) (model.Order, error) {
-	var o model.Order
-
-	err := r.pool.QueryRow(ctx, `SELECT id, customer_id, total_price, status, created_at, updated_at FROM orders WHERE id=$1`, id).
-		Scan(&o.ID, &o.CustomerID, &o.TotalPrice, &o.Status, &o.CreatedAt, &o.UpdatedAt)
+	o, err := scanOrder(r.pool.QueryRow(ctx, `SELECT `+orderColumns+` FROM orders WHERE id=$1`, id))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return o, ErrNotFound
@@ -82,42 +206,70 @@ func (r *OrderRepository) Get(ctx context.Context, id uuid.UUID) (model.Order, e
 	return o, nil
 }
 
-func (r *OrderRepository) List(ctx context.Context, limit, offset int) ([]model.Order, error) {
-	rows, err := r.pool.Query(ctx, `SELECT id, customer_id, total_price, status, created_at, updated_at FROM orders ORDER BY created_at DESC LIMIT $1 OFFSET $2`, limit, offset)
+var orderKeyset = keyset{
+	columns: []string{"created_at", "id"},
+	types:   []string{"timestamptz", "uuid"},
+	desc:    true,
+}
+
+func (r *OrderRepository) List(ctx context.Context, p PageRequest) ([]model.Order, Page, error) {
+	var b queryBuilder
+	var total *int64
+	if p.WithTotal {
+		var err error
+		if total, err = countRows(ctx, r.pool, "orders", b); err != nil {
+			return nil, Page{}, err
+		}
+	}
+	tail, err := orderKeyset.paginate(&b, "", p)
 	if err != nil {
-		return nil, err
+		return nil, Page{}, err
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT `+orderColumns+` FROM orders`+b.whereClause()+tail, b.args...)
+	if err != nil {
+		return nil, Page{}, err
 	}
 	defer rows.Close()
 
 	var orders []model.Order
-	var ids []uuid.UUID
-
 	for rows.Next() {
-		var o model.Order
-		if err := rows.Scan(&o.ID, &o.CustomerID, &o.TotalPrice, &o.Status, &o.CreatedAt, &o.UpdatedAt); err != nil {
-			return nil, err
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, Page{}, err
 		}
 		orders = append(orders, o)
-		ids = append(ids, o.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Page{}, err
 	}
 
-	if len(ids) == 0 {
-		return orders, rows.Err()
+	orders, page := finishPage(orders, p.Limit, "", func(o model.Order) []string {
+		return []string{o.CreatedAt.Format(time.RFC3339Nano), o.ID.String()}
+	})
+	page.Total = total
+
+	if len(orders) == 0 {
+		return orders, page, nil
 	}
 
-	itemMap, err := r.fetchItemsForOrders(ctx, ids)
+	ids := make([]uuid.UUID, 0, len(orders))
+	for _, o := range orders {
+		ids = append(ids, o.ID)
+	}
+	itemMap, err := fetchItemsForOrders(ctx, r.pool, ids)
 	if err != nil {
-		return nil, err
+		return nil, Page{}, err
 	}
 
 	for i := range orders {
 		orders[i].Items = itemMap[orders[i].ID]
 	}
-	return orders, rows.Err()
+	return orders, page, nil
 }
 
 func (r *OrderRepository) fetchItems(ctx context.Context, orderID uuid.UUID) ([]model.OrderItem, error) {
-	rows, err := r.pool.Query(ctx, `SELECT id, order_id, product_id, quantity, sub_total, created_at, updated_at FROM order_items WHERE order_id=$1`, orderID)
+	rows, err := r.pool.Query(ctx, `SELECT `+orderItemColumns+` FROM order_items WHERE order_id=$1 ORDER BY created_at ASC, id ASC`, orderID)
 	if err != nil {
 		return nil, err
 	}
@@ -125,8 +277,8 @@ func (r *OrderRepository) fetchItems(ctx context.Context, orderID uuid.UUID) ([]
 
 	var items []model.OrderItem
 	for rows.Next() {
-		var it model.OrderItem
-		if err := rows.Scan(&it.ID, &it.OrderID, &it.ProductID, &it.Quantity, &it.SubTotal, &it.CreatedAt, &it.UpdatedAt); err != nil {
+		it, err := scanOrderItem(rows)
+		if err != nil {
 			return nil, err
 		}
 		items = append(items, it)
@@ -134,8 +286,27 @@ func (r *OrderRepository) fetchItems(ctx context.Context, orderID uuid.UUID) ([]
 	return items, rows.Err()
 }
 
-func (r *OrderRepository) fetchItemsForOrders(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]model.OrderItem, error) {
-	rows, err := r.pool.Query(ctx, `SELECT id, order_id, product_id, quantity, sub_total, created_at, updated_at FROM order_items WHERE order_id = ANY($1)`, ids)
+// Export streams all orders in list order with their items.
+func (r *OrderRepository) Export(ctx context.Context, fn func([]model.Order) error) error {
+	query := `SELECT ` + orderColumns + ` FROM orders ORDER BY ` + orderKeyset.orderBy()
+	return streamRows(ctx, r.pool, query, nil, scanOrder, func(tx pgx.Tx, orders []model.Order) error {
+		ids := make([]uuid.UUID, 0, len(orders))
+		for _, o := range orders {
+			ids = append(ids, o.ID)
+		}
+		itemMap, err := fetchItemsForOrders(ctx, tx, ids)
+		if err != nil {
+			return err
+		}
+		for i := range orders {
+			orders[i].Items = itemMap[orders[i].ID]
+		}
+		return fn(orders)
+	})
+}
+
+func fetchItemsForOrders(ctx context.Context, q querier, ids []uuid.UUID) (map[uuid.UUID][]model.OrderItem, error) {
+	rows, err := q.Query(ctx, `SELECT `+orderItemColumns+` FROM order_items WHERE order_id = ANY($1) ORDER BY created_at ASC, id ASC`, ids)
 	if err != nil {
 		return nil, err
 	}
@@ -143,8 +314,8 @@ func (r *OrderRepository) fetchItemsForOrders(ctx context.Context, ids []uuid.UU
 
 	result := make(map[uuid.UUID][]model.OrderItem)
 	for rows.Next() {
-		var it model.OrderItem
-		if err := rows.Scan(&it.ID, &it.OrderID, &it.ProductID, &it.Quantity, &it.SubTotal, &it.CreatedAt, &it.UpdatedAt); err != nil {
+		it, err := scanOrderItem(rows)
+		if err != nil {
 			return nil, err
 		}
 		result[it.OrderID] = append(result[it.OrderID], it)
@@ -152,8 +323,15 @@ func (r *OrderRepository) fetchItemsForOrders(ctx context.Context, ids []uuid.UU
 	return result, rows.Err()
 }
 
-// AddProductToOrder adds or increments a product inside the order with transactional guarantees.
-func (r *OrderRepository) AddProductToOrder(ctx context.Context, orderID, productID uuid.UUID, qty int) (model.OrderItem, error) {
+// AddProductToOrder adds a product to the order with transactional guarantees, snapshotting its
+// name and unit price; the line with the same price is incremented, a new price opens a new line.
+// Archived products cannot be added. When variantID is set, price and stock are taken from the variant; products with variants require one.
+// A price in another currency is converted to the order currency at the current exchange rate.
+// Stock is not decremented: the units are held for the order until holdUntil in one warehouse that
+// has them available (on-hand minus active holds), chosen by the allocation strategy; units from
+// different warehouses form separate lines. Items can be added to new orders only. Taking the
+// product stock down to its reorder point queues a low-stock alert.
+func (r *OrderRepository) AddProductToOrder(ctx context.Context, orderID, productID uuid.UUID, variantID *uuid.UUID, qty int, holdUntil time.Time, strategy string) (model.OrderItem, error) {
 	var item model.OrderItem
 	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
@@ -162,89 +340,118 @@ func (r *OrderRepository) AddProductToOrder(ctx context.Context, orderID, produc
 	defer tx.Rollback(ctx)
 
 	// Ensure order exists and lock it.
-	if err := tx.QueryRow(ctx, `SELECT id FROM orders WHERE id=$1 FOR UPDATE`, orderID).Scan(new(uuid.UUID)); err != nil {
+	var orderCurrency model.Currency
+	var status string
+	var lat, lon *float64
+	if err := tx.QueryRow(ctx, `SELECT currency, status, shipping_latitude, shipping_longitude FROM orders WHERE id=$1 FOR UPDATE`, orderID).
+		Scan(&orderCurrency, &status, &lat, &lon); err != nil {
 		if err == pgx.ErrNoRows {
 			return item, ErrNotFound
 		}
 		return item, err
 	}
+	if status != model.OrderStatusNew {
+		return item, ErrOrderNotOpen
+	}
 
+	var name string
 	var price decimal.Decimal
-	var stock int
-	if err := tx.QueryRow(ctx, `SELECT price, quantity FROM products WHERE id=$1 FOR UPDATE`, productID).Scan(&price, &stock); err != nil {
+	var currency model.Currency
+	var archived bool
+	err = tx.QueryRow(ctx, `SELECT name, price, currency, archived_at IS NOT NULL FROM products WHERE id=$1 FOR UPDATE`, productID).
+		Scan(&name, &price, &currency, &archived)
+	if err != nil {
 		if err == pgx.ErrNoRows {
 			return item, ErrNotFound
 		}
 		return item, err
 	}
-	if stock < qty {
-		return item, ErrNotEnoughStock
+	if archived {
+		return item, ErrProductArchived
 	}
 
-	var currentID uuid.UUID
-	var currentQty int
-	var currentSub decimal.Decimal
-	var currentCreated time.Time
-	exists := true
-	err = tx.QueryRow(ctx, `SELECT id, quantity, sub_total, created_at FROM order_items WHERE order_id=$1 AND product_id=$2 FOR UPDATE`, orderID, productID).
-		Scan(&currentID, &currentQty, &currentSub, &currentCreated)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			exists = false
-		} else {
+	if variantID != nil {
+		var override *decimal.Decimal
+		err := tx.QueryRow(ctx, `SELECT price FROM product_variants WHERE id=$1 AND product_id=$2 FOR UPDATE`, *variantID, productID).
+			Scan(&override)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return item, ErrNotFound
+			}
+			return item, err
+		}
+		if override != nil {
+			price = *override
+		}
+	} else {
+		var hasVariants bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM product_variants WHERE product_id=$1)`, productID).Scan(&hasVariants); err != nil {
 			return item, err
 		}
+		if hasVariants {
+			return item, ErrVariantRequired
+		}
 	}
-
-	newQty := qty
-	if exists {
-		newQty = currentQty + qty
+	warehouseID, err := allocateWarehouse(ctx, tx, strategy, productID, variantID, qty, lat, lon)
+	if err != nil {
+		return item, err
+	}
+	if currency != orderCurrency {
+		var rate *decimal.Decimal
+		if err := tx.QueryRow(ctx, `SELECT exchange_rate($1, $2, now())`, currency, orderCurrency).Scan(&rate); err != nil {
+			return item, err
+		}
+		if rate == nil {
+			return item, ErrRateNotFound
+		}
+		price = orderCurrency.Round(price.Mul(*rate))
 	}
-	newSub := price.Mul(decimal.NewFromInt(int64(newQty)))
 
+	// Units already in the order keep the price they were added at: the line is incremented only
+	// when the current price and the warehouse match, otherwise a new line is opened.
 	now := time.Now().UTC()
-	item.OrderID = orderID
-	item.ProductID = productID
-	item.Quantity = newQty
-	item.SubTotal = newSub
-	item.UpdatedAt = now
-
-	if exists {
-		item.ID = currentID
-		item.CreatedAt = currentCreated
-		_, err = tx.Exec(ctx, `UPDATE order_items SET quantity=$1, sub_total=$2, updated_at=$3 WHERE order_id=$4 AND product_id=$5`, newQty, newSub, now, orderID, productID)
-	} else {
-		item.ID = uuid.New()
-		_, err = tx.Exec(ctx, `INSERT INTO order_items (id, order_id, product_id, quantity, sub_total, created_at, updated_at) 
-			VALUES ($1, $2, $3, $4, $5, $6, $7)`, item.ID, orderID, productID, newQty, newSub, now, now)
-		item.CreatedAt = now
+	lineTotal := price.Mul(decimal.NewFromInt(int64(qty)))
+	item, err = scanOrderItem(tx.QueryRow(ctx, `UPDATE order_items
+		SET quantity = quantity + $5, sub_total = sub_total + $6, updated_at = $7
+		WHERE order_id=$1 AND product_id=$2 AND variant_id IS NOT DISTINCT FROM $3 AND unit_price=$4 AND warehouse_id=$8
+		RETURNING `+orderItemColumns, orderID, productID, variantID, price, qty, lineTotal, now, warehouseID))
+	if err == pgx.ErrNoRows {
+		item, err = scanOrderItem(tx.QueryRow(ctx, `INSERT INTO order_items (`+orderItemColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9, $10)
+			RETURNING `+orderItemColumns, uuid.New(), orderID, productID, variantID, name, qty, price, lineTotal, now, warehouseID))
 	}
 	if err != nil {
 		return item, err
 	}
 
-	delta := newSub
-	if exists {
-		delta = newSub.Sub(currentSub)
+	if _, err := tx.Exec(ctx, `UPDATE orders SET total_price = total_price + $1, updated_at=$2 WHERE id=$3`, lineTotal, now, orderID); err != nil {
+		return item, err
 	}
 
-	if _, err := tx.Exec(ctx, `UPDATE orders SET total_price = total_price + $1, updated_at=$2 WHERE id=$3`, delta, now, orderID); err != nil {
+	if _, err := tx.Exec(ctx, `INSERT INTO stock_reservations (id, order_id, warehouse_id, product_id, variant_id, quantity, status, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, 'active', $7, $8, $8)`, uuid.New(), orderID, warehouseID, productID, variantID, qty, holdUntil, now); err != nil {
 		return item, err
 	}
-
-	if _, err := tx.Exec(ctx, `UPDATE products SET quantity = quantity - $1, updated_at=$2 WHERE id=$3`, qty, now, productID); err != nil {
+	if err := checkReorderPoint(ctx, tx, orderID, productID, qty, now); err != nil {
 		return item, err
 	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return item, err
 	}
-
-	item.OrderID = orderID
-	item.ProductID = productID
-	item.Quantity = newQty
-	item.SubTotal = newSub
-	item.CreatedAt = now
-	item.UpdatedAt = now
 	return item, nil
 }
+
+func scanOrder(row pgx.Row) (model.Order, error) {
+	var o model.Order
+	err := row.Scan(&o.ID, &o.CustomerID, &o.TotalPrice, &o.Currency, &o.Status, &o.CreatedAt, &o.UpdatedAt,
+		&o.ShippingLatitude, &o.ShippingLongitude)
+	return o, err
+}
+
+func scanOrderItem(row pgx.Row) (model.OrderItem, error) {
+	var it model.OrderItem
+	err := row.Scan(&it.ID, &it.OrderID, &it.ProductID, &it.VariantID, &it.ProductName, &it.Quantity,
+		&it.UnitPrice, &it.SubTotal, &it.CreatedAt, &it.UpdatedAt, &it.WarehouseID)
+	return it, err
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PageRequest selects a page by opaque keyset cursor or, when Cursor is empty, by offset.
+// WithTotal additionally counts all rows matching the filters.
+type PageRequest struct {
+	Limit     int
+	Offset    int
+	Cursor    string
+	WithTotal bool
+}
+
+// Page describes the position after a returned page. NextCursor is empty on the last page.
+type Page struct {
+	NextCursor string
+	Total      *int64
+}
+
+// cursor is the serialized keyset position: sort key values of the last row on the page.
+type cursor struct {
+	Sort   string   `json:"s,omitempty"`
+	Values []string `json:"v"`
+}
+
+func encodeCursor(sort string, values []string) string {
+	raw, _ := json.Marshal(cursor{Sort: sort, Values: values})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s, sort string, n int) ([]string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != sort || len(c.Values) != n {
+		return nil, ErrInvalidCursor
+	}
+	return c.Values, nil
+}
+
+// keyset describes the ORDER BY used for cursor pagination. All columns share one
+// direction so the position can be compared as a row value; the last column must be unique.
+type keyset struct {
+	columns []string
+	types   []string
+	desc    bool
+}
+
+func (k keyset) orderBy() string {
+	dir := " ASC"
+	if k.desc {
+		dir = " DESC"
+	}
+	parts := make([]string, len(k.columns))
+	for i, col := range k.columns {
+		parts[i] = col + dir
+	}
+	return strings.Join(parts, ", ")
+}
+
+// paginate adds the cursor condition to b and returns the ORDER BY/LIMIT/OFFSET tail of the query.
+// One extra row is requested so that finishPage can tell whether a next page exists.
+func (k keyset) paginate(b *queryBuilder, sort string, p PageRequest) (string, error) {
+	offset := p.Offset
+	if p.Cursor != "" {
+		values, err := decodeCursor(p.Cursor, sort, len(k.columns))
+		if err != nil {
+			return "", err
+		}
+		placeholders := make([]string, len(values))
+		for i, v := range values {
+			placeholders[i] = b.arg(v) + "::" + k.types[i]
+		}
+		op := " > "
+		if k.desc {
+			op = " < "
+		}
+		b.where("(" + strings.Join(k.columns, ", ") + ")" + op + "(" + strings.Join(placeholders, ", ") + ")")
+		offset = 0
+	}
+	return " ORDER BY " + k.orderBy() + " LIMIT " + b.arg(p.Limit+1) + " OFFSET " + b.arg(offset), nil
+}
+
+// finishPage trims the extra row fetched by paginate and builds the next cursor from the last kept row.
+func finishPage[T any](items []T, limit int, sort string, values func(T) []string) ([]T, Page) {
+	var page Page
+	if len(items) > limit {
+		items = items[:limit]
+		page.NextCursor = encodeCursor(sort, values(items[len(items)-1]))
+	}
+	return items, page
+}
+
+// countRows counts rows of from matching the builder conditions; call it before paginate adds the cursor.
+func countRows(ctx context.Context, pool *pgxpool.Pool, from string, b queryBuilder) (*int64, error) {
+	var total int64
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM `+from+b.whereClause(), b.args...).Scan(&total); err != nil {
+		return nil, err
+	}
+	return &total, nil
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+
+	"store-service/internal/model"
+)
+
+const priceColumns = `id, product_id, price, currency, effective_from, effective_to, applied_at, created_at`
+
+type PriceRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPriceRepository(pool *pgxpool.Pool) *PriceRepository {
+	return &PriceRepository{pool: pool}
+}
+
+// History returns applied and scheduled prices of the product, latest first.
+func (r *PriceRepository) History(ctx context.Context, productID uuid.UUID) ([]model.ProductPrice, error) {
+	if err := r.pool.QueryRow(ctx, `SELECT id FROM products WHERE id=$1`, productID).Scan(new(uuid.UUID)); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT `+priceColumns+` FROM product_prices
+		WHERE product_id=$1 ORDER BY effective_from DESC, created_at DESC`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []model.ProductPrice
+	for rows.Next() {
+		p, err := scanPrice(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+	return result, rows.Err()
+}
+
+// Schedule stores a price change that the scheduler applies at EffectiveFrom.
+func (r *PriceRepository) Schedule(ctx context.Context, p *model.ProductPrice) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	p.CreatedAt = time.Now().UTC()
+	p.EffectiveTo = nil
+	p.AppliedAt = nil
+
+	_, err := r.pool.Exec(ctx, `INSERT INTO product_prices (`+priceColumns+`) VALUES ($1, $2, $3, $4, $5, NULL, NULL, $6)`,
+		p.ID, p.ProductID, p.Price, p.Currency, p.EffectiveFrom, p.CreatedAt)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// CancelScheduled removes a price change that has not been applied yet.
+func (r *PriceRepository) CancelScheduled(ctx context.Context, productID, id uuid.UUID) error {
+	var applied bool
+	err := r.pool.QueryRow(ctx, `SELECT applied_at IS NOT NULL FROM product_prices WHERE id=$1 AND product_id=$2`, id, productID).Scan(&applied)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+	if applied {
+		return ErrPriceApplied
+	}
+
+	cmd, err := r.pool.Exec(ctx, `DELETE FROM product_prices WHERE id=$1 AND product_id=$2 AND applied_at IS NULL`, id, productID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		// Applied by the scheduler in the meantime.
+		return ErrPriceApplied
+	}
+	return nil
+}
+
+// ApplyDue applies scheduled prices whose effective_from has passed, oldest first, and
+// returns how many were applied. Rows locked by a concurrent run are skipped.
+func (r *PriceRepository) ApplyDue(ctx context.Context, now time.Time) (int, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `SELECT `+priceColumns+` FROM product_prices
+		WHERE applied_at IS NULL AND effective_from <= $1
+		ORDER BY effective_from ASC, created_at ASC
+		FOR UPDATE SKIP LOCKED`, now)
+	if err != nil {
+		return 0, err
+	}
+	var due []model.ProductPrice
+	for rows.Next() {
+		p, err := scanPrice(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		due = append(due, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, p := range due {
+		if err := closePriceInterval(ctx, tx, p.ProductID, p.EffectiveFrom); err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE product_prices SET applied_at=$2 WHERE id=$1`, p.ID, now); err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE products SET price=$2, currency=$3, updated_at=$4 WHERE id=$1`,
+			p.ProductID, p.Price, p.Currency, now); err != nil {
+			return 0, err
+		}
+	}
+	return len(due), tx.Commit(ctx)
+}
+
+// recordPrice closes the current history interval of the product and opens a new one at the given time.
+func recordPrice(ctx context.Context, tx pgx.Tx, productID uuid.UUID, price decimal.Decimal, currency model.Currency, at time.Time) error {
+	if err := closePriceInterval(ctx, tx, productID, at); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, `INSERT INTO product_prices (`+priceColumns+`) VALUES ($1, $2, $3, $4, $5, NULL, $5, $5)`,
+		uuid.New(), productID, price, currency, at)
+	return err
+}
+
+func closePriceInterval(ctx context.Context, tx pgx.Tx, productID uuid.UUID, at time.Time) error {
+	_, err := tx.Exec(ctx, `UPDATE product_prices SET effective_to=$2
+		WHERE product_id=$1 AND applied_at IS NOT NULL AND effective_to IS NULL`, productID, at)
+	return err
+}
+
+func scanPrice(row pgx.Row) (model.ProductPrice, error) {
+	var p model.ProductPrice
+	err := row.Scan(&p.ID, &p.ProductID, &p.Price, &p.Currency, &p.EffectiveFrom, &p.EffectiveTo, &p.AppliedAt, &p.CreatedAt)
+	return p, err
+}
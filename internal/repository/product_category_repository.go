@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"store-service/internal/model"
+)
+
+type ProductCategoryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewProductCategoryRepository(pool *pgxpool.Pool) *ProductCategoryRepository {
+	return &ProductCategoryRepository{pool: pool}
+}
+
+// ReplaceForProduct replaces the whole set of product categories in one transaction.
+func (r *ProductCategoryRepository) ReplaceForProduct(ctx context.Context, productID uuid.UUID, categoryIDs []uuid.UUID) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Ensure product exists and lock it against concurrent replacements.
+	if err := tx.QueryRow(ctx, `SELECT id FROM products WHERE id=$1 FOR UPDATE`, productID).Scan(new(uuid.UUID)); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	ids := uniqueIDs(categoryIDs)
+	var found int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM categories WHERE id = ANY($1)`, ids).Scan(&found); err != nil {
+		return err
+	}
+	if found != len(ids) {
+		return ErrNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM product_catagories WHERE product_id=$1 AND NOT (catagory_id = ANY($2))`, productID, ids); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if _, err := tx.Exec(ctx, `INSERT INTO product_catagories (product_id, catagory_id, created_at, updated_at)
+		SELECT $1, cid, $3, $3 FROM unnest($2::uuid[]) AS cid
+		ON CONFLICT (product_id, catagory_id) DO NOTHING`, productID, ids, now); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ListCategoriesByProduct returns categories the product is assigned to.
+func (r *ProductCategoryRepository) ListCategoriesByProduct(ctx context.Context, productID uuid.UUID) ([]model.Category, error) {
+	if err := r.pool.QueryRow(ctx, `SELECT id FROM products WHERE id=$1`, productID).Scan(new(uuid.UUID)); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	query := `SELECT c.id, c.name, c.slug, c.parent_id, c.level, c.path, c.root_category_id, c.is_active, c.sort_order, c.created_at, c.updated_at
+		FROM categories c
+		JOIN product_catagories pc ON pc.catagory_id = c.id
+		WHERE pc.product_id = $1
+		ORDER BY c.level ASC, c.sort_order ASC`
+	rows, err := r.pool.Query(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []model.Category
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, c)
+	}
+	return result, rows.Err()
+}
+
+// ListProductsByCategory returns active products directly assigned to the category.
+func (r *ProductCategoryRepository) ListProductsByCategory(ctx context.Context, categoryID uuid.UUID, limit, offset int) ([]model.Product, error) {
+	if err := r.pool.QueryRow(ctx, `SELECT id FROM categories WHERE id=$1`, categoryID).Scan(new(uuid.UUID)); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	query := `SELECT ` + productColumns + `
+		FROM products p
+		JOIN product_catagories pc ON pc.product_id = p.id
+		WHERE pc.catagory_id = $1 AND p.archived_at IS NULL
+		ORDER BY p.created_at DESC LIMIT $2 OFFSET $3`
+	rows, err := r.pool.Query(ctx, query, categoryID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []model.Product
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+	return result, rows.Err()
+}
+
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]struct{}, len(ids))
+	result := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+	return result
+}
@@ -7,10 +7,13 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
 
 	"store-service/internal/model"
 )
 
+const productColumns = `p.id, p.slug, p.sku, p.external_id, p.name, p.price, p.currency, p.quantity, p.attributes, p.created_at, p.updated_at, p.archived_at, ` + productAvailable + `, ` + productIncoming
+
 type ProductRepository struct {
 	pool *pgxpool.Pool
 }
@@ -19,6 +22,9 @@ func NewProductRepository(pool *pgxpool.Pool) *ProductRepository {
 	return &ProductRepository{pool: pool}
 }
 
+// Create inserts the product and opens its price history; the initial stock is received into the
+// default warehouse.
+// A taken slug gets a numeric suffix.
 func (r *ProductRepository) Create(ctx context.Context, p *model.Product) error {
 	now := time.Now().UTC()
 	if p.ID == uuid.Nil {
@@ -26,18 +32,42 @@ func (r *ProductRepository) Create(ctx context.Context, p *model.Product) error
 	}
 	p.CreatedAt = now
 	p.UpdatedAt = now
-	query := `INSERT INTO products (id, name, price, quantity, created_at, updated_at)
-			  VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err := r.pool.Exec(ctx, query, p.ID, p.Name, p.Price, p.Quantity, p.CreatedAt, p.UpdatedAt)
-	return err
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if p.Slug, err = claimSlug(ctx, tx, productSlugs, p.ID, p.Slug); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO products (id, slug, sku, external_id, name, price, currency, quantity, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, 0, $8, $9)`
+	_, err = tx.Exec(ctx, query, p.ID, p.Slug, p.SKU, p.ExternalID, p.Name, p.Price, p.Currency, p.CreatedAt, p.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateProductKey
+		}
+		return err
+	}
+	if err := recordPrice(ctx, tx, p.ID, p.Price, p.Currency, now); err != nil {
+		return err
+	}
+	if err := adjustDefaultStock(ctx, tx, p.ID, nil, p.Quantity, model.StockReceipt, nil, now); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	p.Attributes = map[string]any{}
+	p.Available = p.Quantity
+	return nil
 }
 
 func (r *ProductRepository) Get(ctx context.Context, id uuid.UUID) (model.Product, error) {
-	var p model.Product
-	query := `SELECT id, name, price, quantity, created_at, updated_at FROM products WHERE id=$1`
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&p.ID, &p.Name, &p.Price, &p.Quantity, &p.CreatedAt, &p.UpdatedAt,
-	)
+	p, err := scanProduct(r.pool.QueryRow(ctx, `SELECT `+productColumns+` FROM products p WHERE p.id=$1`, id))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return p, ErrNotFound
@@ -47,21 +77,98 @@ func (r *ProductRepository) Get(ctx context.Context, id uuid.UUID) (model.Produc
 	return p, nil
 }
 
+// Update overwrites the product; a changed price or currency is recorded in the price history
+// and a changed quantity as a stock adjustment in the default warehouse.
+// An empty slug keeps the current one; after a slug change the old slug redirects to the product.
 func (r *ProductRepository) Update(ctx context.Context, p *model.Product) error {
 	p.UpdatedAt = time.Now().UTC()
-	query := `UPDATE products SET name=$1, price=$2, quantity=$3, updated_at=$4 WHERE id=$5`
-	cmd, err := r.pool.Exec(ctx, query, p.Name, p.Price, p.Quantity, p.UpdatedAt, p.ID)
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return err
 	}
-	if cmd.RowsAffected() == 0 {
-		return ErrNotFound
+	defer tx.Rollback(ctx)
+
+	var oldPrice decimal.Decimal
+	var oldCurrency model.Currency
+	var oldSlug string
+	var oldQuantity int
+	err = tx.QueryRow(ctx, `SELECT price, currency, slug, quantity FROM products WHERE id=$1 FOR UPDATE`, p.ID).
+		Scan(&oldPrice, &oldCurrency, &oldSlug, &oldQuantity)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
 	}
-	return nil
+	if p.Slug, err = setSlug(ctx, tx, productSlugs, p.ID, oldSlug, p.Slug); err != nil {
+		return err
+	}
+	if err := adjustDefaultStock(ctx, tx, p.ID, nil, p.Quantity-oldQuantity, model.StockAdjustment, nil, p.UpdatedAt); err != nil {
+		return err
+	}
+
+	query := `UPDATE products p SET slug=$1, sku=$2, external_id=$3, name=$4, price=$5, currency=$6, updated_at=$7 WHERE p.id=$8
+		RETURNING p.attributes, p.created_at, p.archived_at, ` + productAvailable + `, ` + productIncoming
+	err = tx.QueryRow(ctx, query, p.Slug, p.SKU, p.ExternalID, p.Name, p.Price, p.Currency, p.UpdatedAt, p.ID).
+		Scan(&p.Attributes, &p.CreatedAt, &p.ArchivedAt, &p.Available, &p.Incoming)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateProductKey
+		}
+		return err
+	}
+	if !oldPrice.Equal(p.Price) || oldCurrency != p.Currency {
+		if err := recordPrice(ctx, tx, p.ID, p.Price, p.Currency, p.UpdatedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
 }
 
-func (r *ProductRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	cmd, err := r.pool.Exec(ctx, `DELETE FROM products WHERE id=$1`, id)
+// GetBySlug finds the product by its current slug or, with moved set, by an old slug it was renamed from.
+func (r *ProductRepository) GetBySlug(ctx context.Context, slug string) (model.Product, bool, error) {
+	p, err := scanProduct(r.pool.QueryRow(ctx, `SELECT `+productColumns+` FROM products p WHERE p.slug=$1`, slug))
+	if err == nil {
+		return p, false, nil
+	}
+	if err != pgx.ErrNoRows {
+		return p, false, err
+	}
+	p, err = scanProduct(r.pool.QueryRow(ctx, `SELECT `+productColumns+`
+		FROM product_slug_redirects r
+		JOIN products p ON p.id = r.product_id
+		WHERE r.slug=$1`, slug))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return p, false, ErrNotFound
+		}
+		return p, false, err
+	}
+	return p, true, nil
+}
+
+// SetAttributes replaces attribute values of the product; values must be validated by the caller.
+func (r *ProductRepository) SetAttributes(ctx context.Context, id uuid.UUID, attrs map[string]any) (model.Product, error) {
+	if attrs == nil {
+		attrs = map[string]any{}
+	}
+	query := `UPDATE products p SET attributes=$1, updated_at=$2 WHERE p.id=$3 RETURNING ` + productColumns
+	p, err := scanProduct(r.pool.QueryRow(ctx, query, attrs, time.Now().UTC(), id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return p, ErrNotFound
+		}
+		return p, err
+	}
+	return p, nil
+}
+
+// Archive hides the product from listings and new orders; order history keeps referencing it.
+// ErrNotFound is returned for a missing or already archived product.
+func (r *ProductRepository) Archive(ctx context.Context, id uuid.UUID) error {
+	now := time.Now().UTC()
+	cmd, err := r.pool.Exec(ctx, `UPDATE products SET archived_at=$1, updated_at=$1 WHERE id=$2 AND archived_at IS NULL`, now, id)
 	if err != nil {
 		return err
 	}
@@ -71,20 +178,343 @@ func (r *ProductRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-func (r *ProductRepository) List(ctx context.Context, limit, offset int) ([]model.Product, error) {
-	rows, err := r.pool.Query(ctx, `SELECT id, name, price, quantity, created_at, updated_at FROM products ORDER BY created_at DESC LIMIT $1 OFFSET $2`, limit, offset)
+// Restore returns an archived product to the catalog; ErrNotFound is returned when it is not archived.
+func (r *ProductRepository) Restore(ctx context.Context, id uuid.UUID) (model.Product, error) {
+	query := `UPDATE products p SET archived_at=NULL, updated_at=$1 WHERE p.id=$2 AND p.archived_at IS NOT NULL RETURNING ` + productColumns
+	p, err := scanProduct(r.pool.QueryRow(ctx, query, time.Now().UTC(), id))
 	if err != nil {
-		return nil, err
+		if err == pgx.ErrNoRows {
+			return p, ErrNotFound
+		}
+		return p, err
+	}
+	return p, nil
+}
+
+// ProductListFilter narrows product listing. Nil or zero fields are not applied;
+// CategoryID includes the category's descendants. Sort is one of productSorts keys.
+// Archived switches the listing from active to archived products.
+type ProductListFilter struct {
+	Archived    bool
+	Attributes  []AttributeFilter
+	MinPrice    *decimal.Decimal
+	MaxPrice    *decimal.Decimal
+	InStock     bool
+	CategoryID  *uuid.UUID
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+	Sort        string
+	// BaseCurrency is the currency MinPrice and MaxPrice are given in and prices are sorted in.
+	BaseCurrency model.Currency
+	PageRequest
+}
+
+// AttributeFilter matches products by an attribute value: any of Values,
+// or a numeric range when Min/Max are set.
+type AttributeFilter struct {
+	Code   string
+	Values []string
+	Min    *decimal.Decimal
+	Max    *decimal.Decimal
+}
+
+type productSort struct {
+	keyset keyset
+	values func(p model.Product) []string
+	// byPrice sorts by the price converted to the base currency, see basePrice.
+	byPrice bool
+}
+
+// basePrice is the SQL expression of the product price converted to base at the current rate;
+// a price without a rate to base is taken as is.
+func basePrice(b *queryBuilder, base model.Currency) string {
+	return "COALESCE(p.price * exchange_rate(p.currency, " + b.arg(string(base)) + "::text, now()), p.price)"
+}
+
+// basePriceRow appends the base price column to the product columns when scanning a row.
+type basePriceRow struct {
+	pgx.Row
+	price *decimal.Decimal
+}
+
+func (r basePriceRow) Scan(dest ...any) error {
+	return r.Row.Scan(append(dest, r.price)...)
+}
+
+func productCreatedValues(p model.Product) []string {
+	return []string{p.CreatedAt.Format(time.RFC3339Nano), p.ID.String()}
+}
+
+func productNameValues(p model.Product) []string {
+	return []string{p.Name, p.ID.String()}
+}
+
+// productSorts maps public sort keys to keysets; id keeps the order stable.
+var productSorts = map[string]productSort{
+	"":            {keyset{[]string{"p.created_at", "p.id"}, []string{"timestamptz", "uuid"}, true}, productCreatedValues, false},
+	"created_at":  {keyset{[]string{"p.created_at", "p.id"}, []string{"timestamptz", "uuid"}, false}, productCreatedValues, false},
+	"-created_at": {keyset{[]string{"p.created_at", "p.id"}, []string{"timestamptz", "uuid"}, true}, productCreatedValues, false},
+	"price":       {keyset{[]string{"p.price", "p.id"}, []string{"numeric", "uuid"}, false}, nil, true},
+	"-price":      {keyset{[]string{"p.price", "p.id"}, []string{"numeric", "uuid"}, true}, nil, true},
+	"name":        {keyset{[]string{"p.name", "p.id"}, []string{"text", "uuid"}, false}, productNameValues, false},
+	"-name":       {keyset{[]string{"p.name", "p.id"}, []string{"text", "uuid"}, true}, productNameValues, false},
+}
+
+func (r *ProductRepository) List(ctx context.Context, f ProductListFilter) ([]model.Product, Page, error) {
+	sort, ok := productSorts[f.Sort]
+	if !ok {
+		return nil, Page{}, ErrInvalidSort
+	}
+
+	var b queryBuilder
+	productFilterConditions(&b, f)
+
+	var total *int64
+	if f.WithTotal {
+		var err error
+		if total, err = countRows(ctx, r.pool, "products p", b); err != nil {
+			return nil, Page{}, err
+		}
+	}
+	// Price sorts order by the base price; it is selected too, as the cursor is built from it.
+	columns := productColumns
+	values := sort.values
+	prices := map[uuid.UUID]decimal.Decimal{}
+	if sort.byPrice {
+		price := basePrice(&b, f.BaseCurrency)
+		sort.keyset.columns = []string{price, "p.id"}
+		columns += ", " + price
+		values = func(p model.Product) []string {
+			return []string{prices[p.ID].String(), p.ID.String()}
+		}
+	}
+	tail, err := sort.keyset.paginate(&b, f.Sort, f.PageRequest)
+	if err != nil {
+		return nil, Page{}, err
+	}
+
+	query := `SELECT ` + columns + ` FROM products p` + b.whereClause() + tail
+	rows, err := r.pool.Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, Page{}, err
 	}
 	defer rows.Close()
 
 	var result []model.Product
 	for rows.Next() {
-		var p model.Product
-		if err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Quantity, &p.CreatedAt, &p.UpdatedAt); err != nil {
-			return nil, err
+		var row pgx.Row = rows
+		var price decimal.Decimal
+		if sort.byPrice {
+			row = basePriceRow{Row: rows, price: &price}
+		}
+		p, err := scanProduct(row)
+		if err != nil {
+			return nil, Page{}, err
+		}
+		if sort.byPrice {
+			prices[p.ID] = price
 		}
 		result = append(result, p)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, Page{}, err
+	}
+
+	result, page := finishPage(result, f.Limit, f.Sort, values)
+	page.Total = total
+	return result, page, nil
+}
+
+// Export streams all products matching the filter in its sort order; paging fields are ignored.
+func (r *ProductRepository) Export(ctx context.Context, f ProductListFilter, fn func([]model.Product) error) error {
+	sort, ok := productSorts[f.Sort]
+	if !ok {
+		return ErrInvalidSort
+	}
+
+	var b queryBuilder
+	productFilterConditions(&b, f)
+	if sort.byPrice {
+		sort.keyset.columns = []string{basePrice(&b, f.BaseCurrency), "p.id"}
+	}
+	query := `SELECT ` + productColumns + ` FROM products p` + b.whereClause() + ` ORDER BY ` + sort.keyset.orderBy()
+	return streamRows(ctx, r.pool, query, b.args, scanProduct, func(_ pgx.Tx, batch []model.Product) error {
+		return fn(batch)
+	})
+}
+
+// productFilterConditions adds the ProductListFilter conditions shared by List, Export and Facets.
+func productFilterConditions(b *queryBuilder, f ProductListFilter) {
+	if f.Archived {
+		b.where("p.archived_at IS NOT NULL")
+	} else {
+		b.where("p.archived_at IS NULL")
+	}
+	if f.MinPrice != nil {
+		b.where(basePrice(b, f.BaseCurrency) + " >= " + b.arg(*f.MinPrice))
+	}
+	if f.MaxPrice != nil {
+		b.where(basePrice(b, f.BaseCurrency) + " <= " + b.arg(*f.MaxPrice))
+	}
+	if f.InStock {
+		b.where(productStockLevel + " > 0")
+	}
+	if f.CategoryID != nil {
+		b.where(`EXISTS (
+			SELECT 1
+			FROM product_catagories pc
+			JOIN categories c ON c.id = pc.catagory_id
+			JOIN categories f ON f.id = ` + b.arg(*f.CategoryID) + `
+			WHERE pc.product_id = p.id AND (c.id = f.id OR c.path LIKE f.path || '/%'))`)
+	}
+	if f.CreatedFrom != nil {
+		b.where("p.created_at >= " + b.arg(*f.CreatedFrom))
+	}
+	if f.CreatedTo != nil {
+		b.where("p.created_at < " + b.arg(*f.CreatedTo))
+	}
+	if f.UpdatedFrom != nil {
+		b.where("p.updated_at >= " + b.arg(*f.UpdatedFrom))
+	}
+	if f.UpdatedTo != nil {
+		b.where("p.updated_at < " + b.arg(*f.UpdatedTo))
+	}
+	for _, a := range f.Attributes {
+		key := b.arg(a.Code) + "::text"
+		if len(a.Values) > 0 {
+			b.where("p.attributes->>" + key + " = ANY(" + b.arg(a.Values) + ")")
+		}
+		// CASE guards the cast against non-numeric values stored under the same key.
+		num := "CASE WHEN jsonb_typeof(p.attributes->" + key + ") = 'number' THEN (p.attributes->>" + key + ")::numeric END"
+		if a.Min != nil {
+			b.where(num + " >= " + b.arg(*a.Min))
+		}
+		if a.Max != nil {
+			b.where(num + " <= " + b.arg(*a.Max))
+		}
+	}
+}
+
+// Facets counts attribute values over products matching the filter; number attributes
+// get a min/max range instead. With a category filter only attributes attached to the
+// category or its ancestors are returned.
+func (r *ProductRepository) Facets(ctx context.Context, f ProductListFilter) ([]model.AttributeFacet, error) {
+	var b queryBuilder
+	productFilterConditions(&b, f)
+
+	scope := ""
+	if f.CategoryID != nil {
+		scope = ` WHERE a.id IN (
+			SELECT ca.attribute_id
+			FROM category_attributes ca
+			JOIN categories c ON c.id = ca.category_id
+			JOIN categories f ON f.id = ` + b.arg(*f.CategoryID) + `
+			WHERE c.id = f.id OR f.path LIKE c.path || '/%')`
+	}
+
+	query := `
+WITH filtered AS (
+    SELECT p.attributes FROM products p` + b.whereClause() + `
+)
+SELECT a.code, a.name, a.type, kv.value, COUNT(*)
+FROM filtered p
+CROSS JOIN LATERAL jsonb_each_text(p.attributes) AS kv
+JOIN attributes a ON a.code = kv.key` + scope + `
+GROUP BY a.code, a.name, a.type, kv.value
+ORDER BY a.code ASC, COUNT(*) DESC, kv.value ASC`
+	rows, err := r.pool.Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []model.AttributeFacet
+	for rows.Next() {
+		var (
+			code, name, value string
+			typ               model.AttributeType
+			count             int
+		)
+		if err := rows.Scan(&code, &name, &typ, &value, &count); err != nil {
+			return nil, err
+		}
+		if len(result) == 0 || result[len(result)-1].Code != code {
+			result = append(result, model.AttributeFacet{Code: code, Name: name, Type: typ})
+		}
+		facet := &result[len(result)-1]
+		if typ != model.AttributeNumber {
+			facet.Values = append(facet.Values, model.FacetValue{Value: value, Count: count})
+			continue
+		}
+		d, err := decimal.NewFromString(value)
+		if err != nil {
+			continue
+		}
+		if facet.Min == nil || d.LessThan(*facet.Min) {
+			facet.Min = &d
+		}
+		if facet.Max == nil || d.GreaterThan(*facet.Max) {
+			facet.Max = &d
+		}
+	}
+	return result, rows.Err()
+}
+
+// ProductSearchFilter narrows full-text search; CategoryID includes the category's descendants.
+type ProductSearchFilter struct {
+	Query      string
+	CategoryID *uuid.UUID
+	Limit      int
+	Offset     int
+}
+
+// escapedProductName is the product name with HTML special characters escaped, so the search
+// snippet can be rendered as HTML with only the <b></b> added by ts_headline.
+const escapedProductName = `replace(replace(replace(replace(replace(p.name,
+        '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+
+// Search matches products by name using russian morphology and exact (simple) word forms, best rank first.
+func (r *ProductRepository) Search(ctx context.Context, f ProductSearchFilter) ([]model.ProductSearchHit, error) {
+	const q = `
+WITH query AS (
+    SELECT websearch_to_tsquery('russian', $1) || websearch_to_tsquery('simple', $1) AS tsq
+)
+SELECT ` + productColumns + `,
+    ts_rank_cd(p.search_vector, query.tsq) AS rank,
+    ts_headline('russian', ` + escapedProductName + `, query.tsq, 'StartSel=<b>, StopSel=</b>, HighlightAll=true') AS snippet
+FROM products p, query
+WHERE p.search_vector @@ query.tsq
+  AND p.archived_at IS NULL
+  AND ($2::uuid IS NULL OR EXISTS (
+        SELECT 1
+        FROM product_catagories pc
+        JOIN categories c ON c.id = pc.catagory_id
+        JOIN categories f ON f.id = $2
+        WHERE pc.product_id = p.id AND (c.id = f.id OR c.path LIKE f.path || '/%')
+  ))
+ORDER BY rank DESC, p.created_at DESC
+LIMIT $3 OFFSET $4`
+	rows, err := r.pool.Query(ctx, q, f.Query, f.CategoryID, f.Limit, f.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []model.ProductSearchHit
+	for rows.Next() {
+		var h model.ProductSearchHit
+		if err := rows.Scan(&h.ID, &h.Slug, &h.SKU, &h.ExternalID, &h.Name, &h.Price, &h.Currency, &h.Quantity, &h.Attributes, &h.CreatedAt, &h.UpdatedAt, &h.ArchivedAt, &h.Available, &h.Incoming, &h.Rank, &h.Snippet); err != nil {
+			return nil, err
+		}
+		result = append(result, h)
+	}
 	return result, rows.Err()
 }
+
+func scanProduct(row pgx.Row) (model.Product, error) {
+	var p model.Product
+	err := row.Scan(&p.ID, &p.Slug, &p.SKU, &p.ExternalID, &p.Name, &p.Price, &p.Currency, &p.Quantity, &p.Attributes, &p.CreatedAt, &p.UpdatedAt, &p.ArchivedAt, &p.Available, &p.Incoming)
+	return p, err
+}
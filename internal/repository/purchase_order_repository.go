@@ -0,0 +1,434 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+
+	"store-service/internal/actor"
+	"store-service/internal/model"
+)
+
+const purchaseOrderColumns = `id, supplier_id, warehouse_id, status, currency, expected_at, note, created_at, updated_at`
+
+const purchaseReceiptColumns = `id, purchase_order_id, extra_cost, note, actor, received_at`
+
+// openPurchaseLines sums quantities still expected on lines of open purchase orders; callers
+// append the line condition.
+const openPurchaseLines = `SELECT SUM(pol.quantity_ordered - pol.quantity_received) FROM purchase_order_lines pol
+	JOIN purchase_orders po ON po.id = pol.purchase_order_id
+	WHERE po.status IN ('` + model.PurchaseOrderOpen + `', '` + model.PurchaseOrderPartiallyReceived + `') AND `
+
+// productIncoming is product quantity ordered from suppliers and not received yet; like
+// productAvailable it leaves out lines of variants.
+const productIncoming = `COALESCE((` + openPurchaseLines + `pol.product_id = p.id AND pol.variant_id IS NULL), 0)`
+
+const variantIncoming = `COALESCE((` + openPurchaseLines + `pol.variant_id = product_variants.id), 0)`
+
+// productStockIncoming is the incoming quantity of the product together with its variants.
+const productStockIncoming = `COALESCE((` + openPurchaseLines + `pol.product_id = p.id), 0)`
+
+// landedCostScale is the number of decimal places of landed unit costs.
+const landedCostScale = 4
+
+// PurchaseOrderFilter narrows purchase order lists; empty fields match everything.
+type PurchaseOrderFilter struct {
+	Status      string
+	SupplierID  *uuid.UUID
+	WarehouseID *uuid.UUID
+}
+
+type PurchaseOrderRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPurchaseOrderRepository(pool *pgxpool.Pool) *PurchaseOrderRepository {
+	return &PurchaseOrderRepository{pool: pool}
+}
+
+// Create inserts an open purchase order with its lines. A variant must belong to the line product,
+// and products with variants are ordered by variant.
+func (r *PurchaseOrderRepository) Create(ctx context.Context, po *model.PurchaseOrder) error {
+	now := time.Now().UTC()
+	po.ID = uuid.New()
+	po.Status = model.PurchaseOrderOpen
+	po.CreatedAt = now
+	po.UpdatedAt = now
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `INSERT INTO purchase_orders (`+purchaseOrderColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		po.ID, po.SupplierID, po.WarehouseID, po.Status, po.Currency, po.ExpectedAt, po.Note, po.CreatedAt, po.UpdatedAt)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	for i := range po.Lines {
+		l := &po.Lines[i]
+		if err := checkStockItem(ctx, tx, l.ProductID, l.VariantID); err != nil {
+			return err
+		}
+		l.ID = uuid.New()
+		l.QuantityReceived = 0
+		_, err := tx.Exec(ctx, `INSERT INTO purchase_order_lines (id, purchase_order_id, product_id, variant_id, quantity_ordered, unit_cost, position)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`, l.ID, po.ID, l.ProductID, l.VariantID, l.QuantityOrdered, l.UnitCost, i)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// Get returns the purchase order with its lines and receipts.
+func (r *PurchaseOrderRepository) Get(ctx context.Context, id uuid.UUID) (model.PurchaseOrder, error) {
+	po, err := scanPurchaseOrder(r.pool.QueryRow(ctx, `SELECT `+purchaseOrderColumns+` FROM purchase_orders WHERE id=$1`, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return po, ErrNotFound
+		}
+		return po, err
+	}
+	list := []model.PurchaseOrder{po}
+	if err := r.loadLines(ctx, list); err != nil {
+		return po, err
+	}
+	po = list[0]
+	if po.Receipts, err = r.receipts(ctx, id); err != nil {
+		return po, err
+	}
+	return po, nil
+}
+
+var purchaseOrderKeyset = keyset{
+	columns: []string{"created_at", "id"},
+	types:   []string{"timestamptz", "uuid"},
+	desc:    true,
+}
+
+// List returns purchase orders newest first with their lines.
+func (r *PurchaseOrderRepository) List(ctx context.Context, f PurchaseOrderFilter, p PageRequest) ([]model.PurchaseOrder, Page, error) {
+	var b queryBuilder
+	if f.Status != "" {
+		b.where("status = " + b.arg(f.Status))
+	}
+	if f.SupplierID != nil {
+		b.where("supplier_id = " + b.arg(*f.SupplierID))
+	}
+	if f.WarehouseID != nil {
+		b.where("warehouse_id = " + b.arg(*f.WarehouseID))
+	}
+	var total *int64
+	if p.WithTotal {
+		var err error
+		if total, err = countRows(ctx, r.pool, "purchase_orders", b); err != nil {
+			return nil, Page{}, err
+		}
+	}
+	tail, err := purchaseOrderKeyset.paginate(&b, "", p)
+	if err != nil {
+		return nil, Page{}, err
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT `+purchaseOrderColumns+` FROM purchase_orders`+b.whereClause()+tail, b.args...)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	defer rows.Close()
+
+	var result []model.PurchaseOrder
+	for rows.Next() {
+		po, err := scanPurchaseOrder(rows)
+		if err != nil {
+			return nil, Page{}, err
+		}
+		result = append(result, po)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Page{}, err
+	}
+
+	result, page := finishPage(result, p.Limit, "", func(po model.PurchaseOrder) []string {
+		return []string{po.CreatedAt.Format(time.RFC3339Nano), po.ID.String()}
+	})
+	page.Total = total
+	if err := r.loadLines(ctx, result); err != nil {
+		return nil, Page{}, err
+	}
+	return result, page, nil
+}
+
+// Cancel closes an open or partially received purchase order; what is not received yet stops
+// being incoming stock. Received goods stay in stock.
+func (r *PurchaseOrderRepository) Cancel(ctx context.Context, id uuid.UUID) error {
+	cmd, err := r.pool.Exec(ctx, `UPDATE purchase_orders SET status=$1, updated_at=$2 WHERE id=$3 AND status IN ($4, $5)`,
+		model.PurchaseOrderCancelled, time.Now().UTC(), id, model.PurchaseOrderOpen, model.PurchaseOrderPartiallyReceived)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() > 0 {
+		return nil
+	}
+	var exists bool
+	if err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM purchase_orders WHERE id=$1)`, id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	return ErrPurchaseOrderClosed
+}
+
+// Receive books a delivery against the purchase order in one transaction: each line adds stock to
+// the order warehouse as a receipt movement referencing the receipt, and its received quantity
+// grows. Lines may be received partially over several receipts but never beyond the ordered
+// quantity. The receipt extra cost is spread over its lines by value (by quantity when all unit
+// costs are zero) into their landed unit costs.
+func (r *PurchaseOrderRepository) Receive(ctx context.Context, rc *model.PurchaseReceipt) error {
+	rc.ID = uuid.New()
+	rc.Actor = actor.FromContext(ctx)
+	rc.ReceivedAt = time.Now().UTC()
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	var warehouseID uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT status, warehouse_id FROM purchase_orders WHERE id=$1 FOR UPDATE`, rc.PurchaseOrderID).
+		Scan(&status, &warehouseID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+	if !model.PurchaseOrderPending(status) {
+		return ErrPurchaseOrderClosed
+	}
+
+	rows, err := tx.Query(ctx, `SELECT id, product_id, variant_id, quantity_ordered, quantity_received, unit_cost
+		FROM purchase_order_lines WHERE purchase_order_id=$1 ORDER BY position`, rc.PurchaseOrderID)
+	if err != nil {
+		return err
+	}
+	orderLines := make(map[uuid.UUID]*model.PurchaseOrderLine)
+	for rows.Next() {
+		var l model.PurchaseOrderLine
+		if err := rows.Scan(&l.ID, &l.ProductID, &l.VariantID, &l.QuantityOrdered, &l.QuantityReceived, &l.UnitCost); err != nil {
+			rows.Close()
+			return err
+		}
+		orderLines[l.ID] = &l
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Several receipt lines for one order line are booked together.
+	merged := make([]model.PurchaseReceiptLine, 0, len(rc.Lines))
+	index := make(map[uuid.UUID]int, len(rc.Lines))
+	for _, in := range rc.Lines {
+		ol, ok := orderLines[in.LineID]
+		if !ok {
+			return ErrNotFound
+		}
+		if i, ok := index[in.LineID]; ok {
+			merged[i].Quantity += in.Quantity
+			continue
+		}
+		index[in.LineID] = len(merged)
+		merged = append(merged, model.PurchaseReceiptLine{
+			LineID:    ol.ID,
+			ProductID: ol.ProductID,
+			VariantID: ol.VariantID,
+			Quantity:  in.Quantity,
+			UnitCost:  ol.UnitCost,
+		})
+	}
+	for _, l := range merged {
+		ol := orderLines[l.LineID]
+		if l.Quantity > ol.QuantityOrdered-ol.QuantityReceived {
+			return ErrOverReceipt
+		}
+	}
+	allocateLandedCost(merged, rc.ExtraCost)
+	rc.Lines = merged
+
+	if _, err := tx.Exec(ctx, `INSERT INTO purchase_receipts (`+purchaseReceiptColumns+`) VALUES ($1, $2, $3, $4, $5, $6)`,
+		rc.ID, rc.PurchaseOrderID, rc.ExtraCost, rc.Note, rc.Actor, rc.ReceivedAt); err != nil {
+		return err
+	}
+
+	// Rows are locked in product, variant order, the same as payments take their holds.
+	lines := make([]*model.PurchaseReceiptLine, 0, len(rc.Lines))
+	for i := range rc.Lines {
+		lines = append(lines, &rc.Lines[i])
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		if lines[i].ProductID != lines[j].ProductID {
+			return lines[i].ProductID.String() < lines[j].ProductID.String()
+		}
+		return variantKey(lines[i].VariantID) < variantKey(lines[j].VariantID)
+	})
+	for _, l := range lines {
+		if _, err := lockStock(ctx, tx, l.ProductID, l.VariantID); err != nil {
+			if err == pgx.ErrNoRows {
+				return ErrNotFound
+			}
+			return err
+		}
+		if err := changeStock(ctx, tx, warehouseID, l.ProductID, l.VariantID, l.Quantity, model.StockReceipt, &rc.ID, rc.ReceivedAt); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE purchase_order_lines SET quantity_received = quantity_received + $1 WHERE id=$2`,
+			l.Quantity, l.LineID); err != nil {
+			return err
+		}
+		orderLines[l.LineID].QuantityReceived += l.Quantity
+		l.ID = uuid.New()
+		if _, err := tx.Exec(ctx, `INSERT INTO purchase_receipt_lines (id, receipt_id, line_id, quantity, unit_cost, landed_unit_cost)
+			VALUES ($1, $2, $3, $4, $5, $6)`, l.ID, rc.ID, l.LineID, l.Quantity, l.UnitCost, l.LandedUnitCost); err != nil {
+			return err
+		}
+	}
+
+	status = model.PurchaseOrderReceived
+	for _, ol := range orderLines {
+		if ol.QuantityReceived < ol.QuantityOrdered {
+			status = model.PurchaseOrderPartiallyReceived
+			break
+		}
+	}
+	if _, err := tx.Exec(ctx, `UPDATE purchase_orders SET status=$1, updated_at=$2 WHERE id=$3`,
+		status, rc.ReceivedAt, rc.PurchaseOrderID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// allocateLandedCost sets the landed unit cost of each line: its unit cost plus its share of the
+// extra cost per unit. Shares follow line value, or quantity when the lines have no value.
+func allocateLandedCost(lines []model.PurchaseReceiptLine, extra decimal.Decimal) {
+	total := decimal.Zero
+	units := decimal.Zero
+	for _, l := range lines {
+		q := decimal.NewFromInt(int64(l.Quantity))
+		total = total.Add(l.UnitCost.Mul(q))
+		units = units.Add(q)
+	}
+	for i := range lines {
+		l := &lines[i]
+		q := decimal.NewFromInt(int64(l.Quantity))
+		var share decimal.Decimal
+		switch {
+		case extra.IsZero():
+			share = decimal.Zero
+		case total.IsPositive():
+			share = extra.Mul(l.UnitCost.Mul(q)).Div(total)
+		default:
+			share = extra.Mul(q).Div(units)
+		}
+		l.LandedUnitCost = l.UnitCost.Add(share.Div(q)).Round(landedCostScale)
+	}
+}
+
+func (r *PurchaseOrderRepository) loadLines(ctx context.Context, list []model.PurchaseOrder) error {
+	if len(list) == 0 {
+		return nil
+	}
+	index := make(map[uuid.UUID]int, len(list))
+	ids := make([]uuid.UUID, 0, len(list))
+	for i, po := range list {
+		index[po.ID] = i
+		ids = append(ids, po.ID)
+		list[i].Lines = make([]model.PurchaseOrderLine, 0)
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT purchase_order_id, id, product_id, variant_id, quantity_ordered, quantity_received, unit_cost
+		FROM purchase_order_lines WHERE purchase_order_id = ANY($1) ORDER BY position`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderID uuid.UUID
+		var l model.PurchaseOrderLine
+		if err := rows.Scan(&orderID, &l.ID, &l.ProductID, &l.VariantID, &l.QuantityOrdered, &l.QuantityReceived, &l.UnitCost); err != nil {
+			return err
+		}
+		i := index[orderID]
+		list[i].Lines = append(list[i].Lines, l)
+	}
+	return rows.Err()
+}
+
+// receipts returns receipts of the purchase order oldest first with their lines.
+func (r *PurchaseOrderRepository) receipts(ctx context.Context, orderID uuid.UUID) ([]model.PurchaseReceipt, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+purchaseReceiptColumns+` FROM purchase_receipts
+		WHERE purchase_order_id=$1 ORDER BY received_at, id`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]model.PurchaseReceipt, 0)
+	index := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var rc model.PurchaseReceipt
+		if err := rows.Scan(&rc.ID, &rc.PurchaseOrderID, &rc.ExtraCost, &rc.Note, &rc.Actor, &rc.ReceivedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		rc.Lines = make([]model.PurchaseReceiptLine, 0)
+		index[rc.ID] = len(result)
+		result = append(result, rc)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return result, nil
+	}
+
+	rows, err = r.pool.Query(ctx, `SELECT rl.receipt_id, rl.id, rl.line_id, l.product_id, l.variant_id, rl.quantity, rl.unit_cost, rl.landed_unit_cost
+		FROM purchase_receipt_lines rl
+		JOIN purchase_order_lines l ON l.id = rl.line_id
+		WHERE l.purchase_order_id=$1
+		ORDER BY l.position`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var receiptID uuid.UUID
+		var l model.PurchaseReceiptLine
+		if err := rows.Scan(&receiptID, &l.ID, &l.LineID, &l.ProductID, &l.VariantID, &l.Quantity, &l.UnitCost, &l.LandedUnitCost); err != nil {
+			return nil, err
+		}
+		i := index[receiptID]
+		result[i].Lines = append(result[i].Lines, l)
+	}
+	return result, rows.Err()
+}
+
+func scanPurchaseOrder(row pgx.Row) (model.PurchaseOrder, error) {
+	var po model.PurchaseOrder
+	err := row.Scan(&po.ID, &po.SupplierID, &po.WarehouseID, &po.Status, &po.Currency, &po.ExpectedAt, &po.Note, &po.CreatedAt, &po.UpdatedAt)
+	return po, err
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+)
+
+// queryBuilder collects WHERE conditions with positional arguments so that
+// user input never ends up in SQL text: only placeholders returned by arg are concatenated.
+type queryBuilder struct {
+	conds []string
+	args  []any
+}
+
+// arg registers a value and returns its placeholder ($1, $2, ...).
+func (b *queryBuilder) arg(v any) string {
+	b.args = append(b.args, v)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+// where adds a condition joined with AND.
+func (b *queryBuilder) where(cond string) {
+	b.conds = append(b.conds, cond)
+}
+
+// whereClause renders " WHERE ..." or an empty string when no conditions were added.
+func (b *queryBuilder) whereClause() string {
+	if len(b.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(b.conds, " AND ")
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"store-service/internal/model"
+)
+
+type RecommendationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRecommendationRepository(pool *pgxpool.Pool) *RecommendationRepository {
+	return &RecommendationRepository{pool: pool}
+}
+
+// Recompute replaces product associations with pairs bought together in at least minOrders orders
+// and returns the number of stored pairs. Each order counts a product once, whatever the quantity.
+func (r *RecommendationRepository) Recompute(ctx context.Context, minOrders int) (int, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Several service instances may run the worker; they recompute one after another.
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('product_associations'))`); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM product_associations`); err != nil {
+		return 0, err
+	}
+	cmd, err := tx.Exec(ctx, `
+WITH baskets AS (
+	SELECT DISTINCT order_id, product_id FROM order_items
+),
+total AS (
+	SELECT COUNT(DISTINCT order_id)::numeric AS orders FROM baskets
+),
+product_orders AS (
+	SELECT product_id, COUNT(*)::numeric AS orders FROM baskets GROUP BY product_id
+),
+pairs AS (
+	SELECT a.product_id, b.product_id AS related_id, COUNT(*) AS together
+	FROM baskets a
+	JOIN baskets b ON b.order_id = a.order_id AND b.product_id <> a.product_id
+	GROUP BY a.product_id, b.product_id
+	HAVING COUNT(*) >= $1
+)
+INSERT INTO product_associations (product_id, related_id, orders_together, support, confidence, lift, computed_at)
+SELECT pr.product_id, pr.related_id, pr.together,
+	pr.together / t.orders,
+	pr.together / pa.orders,
+	pr.together * t.orders / (pa.orders * pb.orders),
+	$2
+FROM pairs pr
+CROSS JOIN total t
+JOIN product_orders pa ON pa.product_id = pr.product_id
+JOIN product_orders pb ON pb.product_id = pr.related_id`, minOrders, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return int(cmd.RowsAffected()), tx.Commit(ctx)
+}
+
+// Related returns products most often bought with the product, best lift first.
+// Archived and out-of-stock products are skipped.
+func (r *RecommendationRepository) Related(ctx context.Context, productID uuid.UUID, limit int) ([]model.ProductRecommendation, error) {
+	if err := r.exists(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE id=$1)`, productID); err != nil {
+		return nil, err
+	}
+	return r.query(ctx, `
+SELECT `+productColumns+`, a.orders_together, a.confidence::float8, a.lift::float8
+FROM product_associations a
+JOIN products p ON p.id = a.related_id
+WHERE a.product_id = $1 AND p.archived_at IS NULL AND `+productStockLevel+` > 0
+ORDER BY a.lift DESC, a.confidence DESC, a.orders_together DESC, p.id
+LIMIT $2`, productID, limit)
+}
+
+// Suggestions returns products bought together with any product of the order and not in it yet.
+// Scores of a candidate related to several order products are combined: the best lift and
+// confidence, and orders_together summed.
+func (r *RecommendationRepository) Suggestions(ctx context.Context, orderID uuid.UUID, limit int) ([]model.ProductRecommendation, error) {
+	if err := r.exists(ctx, `SELECT EXISTS (SELECT 1 FROM orders WHERE id=$1)`, orderID); err != nil {
+		return nil, err
+	}
+	return r.query(ctx, `
+WITH basket AS (
+	SELECT DISTINCT product_id FROM order_items WHERE order_id = $1
+)
+SELECT `+productColumns+`, SUM(a.orders_together)::int, MAX(a.confidence)::float8, MAX(a.lift)::float8
+FROM product_associations a
+JOIN products p ON p.id = a.related_id
+WHERE a.product_id IN (SELECT product_id FROM basket)
+	AND a.related_id NOT IN (SELECT product_id FROM basket)
+	AND p.archived_at IS NULL AND `+productStockLevel+` > 0
+GROUP BY p.id
+ORDER BY MAX(a.lift) DESC, MAX(a.confidence) DESC, SUM(a.orders_together) DESC, p.id
+LIMIT $2`, orderID, limit)
+}
+
+func (r *RecommendationRepository) exists(ctx context.Context, query string, id uuid.UUID) error {
+	var ok bool
+	if err := r.pool.QueryRow(ctx, query, id).Scan(&ok); err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *RecommendationRepository) query(ctx context.Context, query string, args ...any) ([]model.ProductRecommendation, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]model.ProductRecommendation, 0)
+	for rows.Next() {
+		var rec model.ProductRecommendation
+		if err := rows.Scan(&rec.ID, &rec.Slug, &rec.SKU, &rec.ExternalID, &rec.Name, &rec.Price, &rec.Currency, &rec.Quantity,
+			&rec.Attributes, &rec.CreatedAt, &rec.UpdatedAt, &rec.ArchivedAt, &rec.Available, &rec.Incoming, &rec.OrdersTogether, &rec.Confidence, &rec.Lift); err != nil {
+			return nil, err
+		}
+		result = append(result, rec)
+	}
+	return result, rows.Err()
+}
@@ -6,11 +6,14 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/shopspring/decimal"
+
+	"store-service/internal/model"
 )
 
 type CustomerTotal struct {
 	CustomerName string          `json:"customer_name"`
 	TotalAmount  decimal.Decimal `json:"total_amount"`
+	Currency     model.Currency  `json:"currency"`
 }
 
 type CategoryChildrenCount struct {
@@ -25,6 +28,19 @@ type TopProduct struct {
 	TotalQuantity  int    `json:"total_quantity"`
 }
 
+// LowStockProduct is a product whose available stock is at or below its reorder point; quantity,
+// available and incoming include its variants.
+type LowStockProduct struct {
+	ProductID    uuid.UUID `json:"product_id"`
+	Name         string    `json:"name"`
+	SKU          *string   `json:"sku"`
+	Quantity     int       `json:"quantity"`
+	Available    int       `json:"available"`
+	Incoming     int       `json:"incoming"`
+	ReorderPoint int       `json:"reorder_point"`
+	Source       string    `json:"source"`
+}
+
 type ReportRepository struct {
 	pool *pgxpool.Pool
 }
@@ -33,16 +49,29 @@ func NewReportRepository(pool *pgxpool.Pool) *ReportRepository {
 	return &ReportRepository{pool: pool}
 }
 
-func (r *ReportRepository) CustomerTotals(ctx context.Context) ([]CustomerTotal, error) {
+// CustomerTotals sums order items per customer in the given currency. Each order is converted
+// at the rate effective when it was created; ErrRateNotFound is returned when a rate is missing.
+func (r *ReportRepository) CustomerTotals(ctx context.Context, currency model.Currency) ([]CustomerTotal, error) {
+	var missing bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (
+		SELECT 1 FROM orders WHERE exchange_rate(currency, $1, created_at) IS NULL)`, currency).Scan(&missing)
+	if err != nil {
+		return nil, err
+	}
+	if missing {
+		return nil, ErrRateNotFound
+	}
+
 	const q = `
-SELECT c.name AS customer_name, COALESCE(SUM(oi.sub_total), 0) AS total_amount
+SELECT c.name AS customer_name,
+       COALESCE(SUM(oi.sub_total * exchange_rate(o.currency, $1, o.created_at)), 0) AS total_amount
 FROM customers c
 LEFT JOIN orders o ON o.customer_id = c.id
 LEFT JOIN order_items oi ON oi.order_id = o.id
 GROUP BY c.name
 ORDER BY c.name;
 `
-	rows, err := r.pool.Query(ctx, q)
+	rows, err := r.pool.Query(ctx, q, currency)
 	if err != nil {
 		return nil, err
 	}
@@ -50,7 +79,7 @@ ORDER BY c.name;
 
 	var res []CustomerTotal
 	for rows.Next() {
-		var row CustomerTotal
+		row := CustomerTotal{Currency: currency}
 		if err := rows.Scan(&row.CustomerName, &row.TotalAmount); err != nil {
 			return nil, err
 		}
@@ -85,28 +114,21 @@ ORDER BY parent.name;
 }
 
 func (r *ReportRepository) TopProductsLastMonth(ctx context.Context) ([]TopProduct, error) {
+	// root_category_id is maintained on every category, so no recursion over the tree is needed.
 	const q = `
-WITH RECURSIVE cat_path AS (
-    SELECT id, parent_id, name AS root_name, id AS root_id
-    FROM categories
-    WHERE parent_id IS NULL
-    UNION ALL
-    SELECT c.id, c.parent_id, cp.root_name, cp.root_id
-    FROM categories c
-    JOIN cat_path cp ON c.parent_id = cp.id
-)
 SELECT
     p.name AS product_name,
-    cp.root_name AS category_level_1,
+    root.name AS category_level_1,
     SUM(oi.quantity) AS total_quantity
 FROM order_items oi
 JOIN orders o ON o.id = oi.order_id
 JOIN products p ON p.id = oi.product_id
 LEFT JOIN product_catagories pc ON pc.product_id = p.id
-LEFT JOIN cat_path cp ON cp.id = pc.catagory_id
+LEFT JOIN categories cat ON cat.id = pc.catagory_id
+LEFT JOIN categories root ON root.id = cat.root_category_id
 WHERE o.created_at >= date_trunc('month', now()) - INTERVAL '1 month'
   AND o.created_at <  date_trunc('month', now())
-GROUP BY p.name, cp.root_name
+GROUP BY p.name, root.name
 ORDER BY total_quantity DESC
 LIMIT 5;
 `
@@ -126,3 +148,39 @@ LIMIT 5;
 	}
 	return res, rows.Err()
 }
+
+// LowStock lists active products with available stock at or below their reorder point, the
+// furthest below first.
+func (r *ReportRepository) LowStock(ctx context.Context) ([]LowStockProduct, error) {
+	q := `
+SELECT id, name, sku, quantity, available, incoming, COALESCE(own_point, category_point),
+       CASE WHEN own_point IS NOT NULL THEN '` + model.ReorderPointProduct + `' ELSE '` + model.ReorderPointCategory + `' END
+FROM (
+    SELECT p.id, p.name, p.sku,
+           p.quantity + COALESCE((SELECT SUM(quantity) FROM product_variants WHERE product_id = p.id), 0) AS quantity,
+           ` + productStockLevel + ` AS available,
+           ` + productStockIncoming + ` AS incoming,
+           p.reorder_point AS own_point,
+           ` + categoryReorderPoint + ` AS category_point
+    FROM products p
+    WHERE p.archived_at IS NULL
+) s
+WHERE available <= COALESCE(own_point, category_point)
+ORDER BY available - COALESCE(own_point, category_point), name;
+`
+	rows, err := r.pool.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]LowStockProduct, 0)
+	for rows.Next() {
+		var row LowStockProduct
+		if err := rows.Scan(&row.ProductID, &row.Name, &row.SKU, &row.Quantity, &row.Available, &row.Incoming, &row.ReorderPoint, &row.Source); err != nil {
+			return nil, err
+		}
+		res = append(res, row)
+	}
+	return res, rows.Err()
+}
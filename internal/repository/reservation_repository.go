@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"store-service/internal/model"
+)
+
+// activeReservation matches holds of unpaid orders that still count against stock; holds past
+// expires_at stop counting right away, the sweeper only marks them expired.
+const activeReservation = `sr.status = 'active' AND sr.expires_at > now()`
+
+// productAvailable is on-hand product quantity minus active holds; holds of variants are
+// counted against the variant stock only.
+const productAvailable = `p.quantity - COALESCE((SELECT SUM(sr.quantity) FROM stock_reservations sr
+	WHERE sr.product_id = p.id AND sr.variant_id IS NULL AND ` + activeReservation + `), 0)`
+
+const variantAvailable = `product_variants.quantity - COALESCE((SELECT SUM(sr.quantity) FROM stock_reservations sr
+	WHERE sr.variant_id = product_variants.id AND ` + activeReservation + `), 0)`
+
+type ReservationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewReservationRepository(pool *pgxpool.Pool) *ReservationRepository {
+	return &ReservationRepository{pool: pool}
+}
+
+// ExpireStale marks active holds past their expiry as expired and returns how many were released.
+func (r *ReservationRepository) ExpireStale(ctx context.Context) (int, error) {
+	cmd, err := r.pool.Exec(ctx, `UPDATE stock_reservations SET status='expired', updated_at=$1
+		WHERE status='active' AND expires_at <= $1`, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return int(cmd.RowsAffected()), nil
+}
+
+// warehouseAvailable is the stock of the product (or its variant) in the warehouse minus active
+// holds of orders other than exceptOrder. Callers lock the product or variant row first so that
+// neither can change meanwhile.
+func warehouseAvailable(ctx context.Context, tx pgx.Tx, warehouseID, productID uuid.UUID, variantID *uuid.UUID, exceptOrder uuid.UUID) (int, error) {
+	var available int
+	err := tx.QueryRow(ctx, `SELECT COALESCE((SELECT quantity FROM warehouse_stock
+			WHERE warehouse_id = $1 AND product_id = $2 AND variant_id IS NOT DISTINCT FROM $3), 0)
+		- COALESCE((SELECT SUM(sr.quantity) FROM stock_reservations sr
+			WHERE sr.warehouse_id = $1 AND sr.product_id = $2 AND sr.variant_id IS NOT DISTINCT FROM $3
+				AND sr.order_id <> $4 AND `+activeReservation+`), 0)`,
+		warehouseID, productID, variantID, exceptOrder).Scan(&available)
+	return available, err
+}
+
+// warehouseDistance is the great-circle distance in km from the point ($4, $5) to the warehouse;
+// NULL when either has no coordinates.
+const warehouseDistance = `2 * 6371 * asin(sqrt(
+	power(sin(radians(w.latitude - $4) / 2), 2) +
+	cos(radians($4)) * cos(radians(w.latitude)) * power(sin(radians(w.longitude - $5) / 2), 2)))`
+
+// allocationOrder ranks warehouses for each allocation strategy.
+var allocationOrder = map[string]string{
+	model.AllocationPriority: `w.priority, w.code`,
+	model.AllocationNearest:  warehouseDistance + ` NULLS LAST, w.priority, w.code`,
+}
+
+// allocateWarehouse picks the active warehouse that can supply qty units by itself, ranked by the
+// strategy; the nearest strategy measures from the order delivery point. Callers lock the product
+// or variant row first.
+func allocateWarehouse(ctx context.Context, tx pgx.Tx, strategy string, productID uuid.UUID, variantID *uuid.UUID, qty int, lat, lon *float64) (uuid.UUID, error) {
+	order, ok := allocationOrder[strategy]
+	if !ok {
+		order = allocationOrder[model.AllocationPriority]
+	}
+	args := []any{productID, variantID, qty}
+	if strategy == model.AllocationNearest {
+		args = append(args, lat, lon)
+	}
+
+	var id uuid.UUID
+	err := tx.QueryRow(ctx, `SELECT w.id
+		FROM warehouses w
+		JOIN warehouse_stock ws ON ws.warehouse_id = w.id AND ws.product_id = $1 AND ws.variant_id IS NOT DISTINCT FROM $2
+		WHERE w.active AND ws.quantity - COALESCE((SELECT SUM(sr.quantity) FROM stock_reservations sr
+			WHERE sr.warehouse_id = w.id AND sr.product_id = $1 AND sr.variant_id IS NOT DISTINCT FROM $2
+				AND `+activeReservation+`), 0) >= $3
+		ORDER BY `+order+`
+		LIMIT 1`, args...).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return id, ErrNotEnoughStock
+	}
+	return id, err
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// slugTable names a table with a unique slug column and the table keeping its old slugs.
+type slugTable struct {
+	table     string
+	redirects string
+	ref       string
+}
+
+var (
+	productSlugs  = slugTable{table: "products", redirects: "product_slug_redirects", ref: "product_id"}
+	categorySlugs = slugTable{table: "categories", redirects: "category_slug_redirects", ref: "category_id"}
+)
+
+// claimSlug returns base or, when it is taken, base with the first free "-N" suffix. Current slugs
+// and old slugs of other records count as taken, so links to renamed records keep working;
+// an old slug of the record itself is reclaimed.
+func claimSlug(ctx context.Context, tx pgx.Tx, t slugTable, id uuid.UUID, base string) (string, error) {
+	// Serializes slug assignment in the table so that concurrent writers do not pick the same suffix.
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, t.table+".slug"); err != nil {
+		return "", err
+	}
+	rows, err := tx.Query(ctx, `SELECT slug FROM `+t.table+` WHERE id <> $1 AND (slug = $2 OR slug LIKE $2 || '-%')
+		UNION ALL
+		SELECT slug FROM `+t.redirects+` WHERE `+t.ref+` <> $1 AND (slug = $2 OR slug LIKE $2 || '-%')`, id, base)
+	if err != nil {
+		return "", err
+	}
+	taken, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return "", err
+	}
+	used := make(map[string]bool, len(taken))
+	for _, s := range taken {
+		used[s] = true
+	}
+
+	slug := base
+	for n := 2; used[slug]; n++ {
+		slug = base + "-" + strconv.Itoa(n)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM `+t.redirects+` WHERE slug=$1`, slug); err != nil {
+		return "", err
+	}
+	return slug, nil
+}
+
+// keepOldSlug makes the previous slug of a renamed record redirect to it.
+func keepOldSlug(ctx context.Context, tx pgx.Tx, t slugTable, id uuid.UUID, old string) error {
+	_, err := tx.Exec(ctx, `INSERT INTO `+t.redirects+` (slug, `+t.ref+`, created_at) VALUES ($1, $2, $3)
+		ON CONFLICT (slug) DO UPDATE SET `+t.ref+` = EXCLUDED.`+t.ref+`, created_at = EXCLUDED.created_at`,
+		old, id, time.Now().UTC())
+	return err
+}
+
+// setSlug assigns the slug on update: an empty slug keeps the current one, a new one is
+// claimed and the current one starts redirecting to the record.
+func setSlug(ctx context.Context, tx pgx.Tx, t slugTable, id uuid.UUID, current, slug string) (string, error) {
+	if slug == "" || slug == current {
+		return current, nil
+	}
+	slug, err := claimSlug(ctx, tx, t, id, slug)
+	if err != nil {
+		return "", err
+	}
+	if slug == current {
+		return slug, nil
+	}
+	return slug, keepOldSlug(ctx, tx, t, id, current)
+}
@@ -0,0 +1,451 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"store-service/internal/actor"
+	"store-service/internal/model"
+)
+
+const stockMovementColumns = `id, warehouse_id, product_id, variant_id, delta, reason, reference_id, actor, request_id, created_at`
+
+// StockMovementFilter narrows the stock ledger of a product.
+type StockMovementFilter struct {
+	WarehouseID *uuid.UUID
+	VariantID   *uuid.UUID
+	Reason      string
+}
+
+type StockRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewStockRepository(pool *pgxpool.Pool) *StockRepository {
+	return &StockRepository{pool: pool}
+}
+
+var stockMovementKeyset = keyset{
+	columns: []string{"created_at", "id"},
+	types:   []string{"timestamptz", "uuid"},
+	desc:    true,
+}
+
+// Movements returns the stock ledger of the product, newest first.
+func (r *StockRepository) Movements(ctx context.Context, productID uuid.UUID, f StockMovementFilter, p PageRequest) ([]model.StockMovement, Page, error) {
+	if err := r.pool.QueryRow(ctx, `SELECT id FROM products WHERE id=$1`, productID).Scan(new(uuid.UUID)); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, Page{}, ErrNotFound
+		}
+		return nil, Page{}, err
+	}
+
+	var b queryBuilder
+	b.where("product_id = " + b.arg(productID))
+	if f.WarehouseID != nil {
+		b.where("warehouse_id = " + b.arg(*f.WarehouseID))
+	}
+	if f.VariantID != nil {
+		b.where("variant_id = " + b.arg(*f.VariantID))
+	}
+	if f.Reason != "" {
+		b.where("reason = " + b.arg(f.Reason))
+	}
+	var total *int64
+	if p.WithTotal {
+		var err error
+		if total, err = countRows(ctx, r.pool, "stock_movements", b); err != nil {
+			return nil, Page{}, err
+		}
+	}
+	tail, err := stockMovementKeyset.paginate(&b, "", p)
+	if err != nil {
+		return nil, Page{}, err
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT `+stockMovementColumns+` FROM stock_movements`+b.whereClause()+tail, b.args...)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	defer rows.Close()
+
+	var result []model.StockMovement
+	for rows.Next() {
+		m, err := scanStockMovement(rows)
+		if err != nil {
+			return nil, Page{}, err
+		}
+		result = append(result, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Page{}, err
+	}
+
+	result, page := finishPage(result, p.Limit, "", func(m model.StockMovement) []string {
+		return []string{m.CreatedAt.Format(time.RFC3339Nano), m.ID.String()}
+	})
+	page.Total = total
+	return result, page, nil
+}
+
+// stockDiscrepancyQuery compares cached totals of products and variants and warehouse stock
+// levels with the sums of their movements.
+const stockDiscrepancyQuery = `
+WITH ledger AS (
+	SELECT warehouse_id, product_id, COALESCE(variant_id, '` + nilUUID + `') AS variant_key, SUM(delta)::int AS quantity
+	FROM stock_movements
+	GROUP BY 1, 2, 3
+)
+SELECT NULL::uuid, p.id, NULL::uuid, p.quantity, COALESCE(SUM(l.quantity), 0)::int
+FROM products p
+LEFT JOIN ledger l ON l.product_id = p.id AND l.variant_key = '` + nilUUID + `'
+GROUP BY p.id
+HAVING p.quantity <> COALESCE(SUM(l.quantity), 0)
+UNION ALL
+SELECT NULL::uuid, v.product_id, v.id, v.quantity, COALESCE(SUM(l.quantity), 0)::int
+FROM product_variants v
+LEFT JOIN ledger l ON l.variant_key = v.id
+GROUP BY v.id
+HAVING v.quantity <> COALESCE(SUM(l.quantity), 0)
+UNION ALL
+SELECT COALESCE(ws.warehouse_id, l.warehouse_id), COALESCE(ws.product_id, l.product_id),
+	COALESCE(ws.variant_id, NULLIF(l.variant_key, '` + nilUUID + `')),
+	COALESCE(ws.quantity, 0), COALESCE(l.quantity, 0)
+FROM warehouse_stock ws
+FULL JOIN ledger l ON l.warehouse_id = ws.warehouse_id AND l.product_id = ws.product_id
+	AND l.variant_key = COALESCE(ws.variant_id, '` + nilUUID + `')
+WHERE COALESCE(ws.quantity, 0) <> COALESCE(l.quantity, 0)
+ORDER BY 2, 3 NULLS FIRST, 1 NULLS FIRST`
+
+const nilUUID = "00000000-0000-0000-0000-000000000000"
+
+// Discrepancies returns products, variants and warehouse stock levels whose cached quantity
+// differs from the sum of their movements.
+func (r *StockRepository) Discrepancies(ctx context.Context) ([]model.StockDiscrepancy, error) {
+	rows, err := r.pool.Query(ctx, stockDiscrepancyQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]model.StockDiscrepancy, 0)
+	for rows.Next() {
+		var d model.StockDiscrepancy
+		if err := rows.Scan(&d.WarehouseID, &d.ProductID, &d.VariantID, &d.Quantity, &d.LedgerQuantity); err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+	return result, rows.Err()
+}
+
+// Reconcile resets cached quantities to the ledger sums and returns the corrected discrepancies.
+// The product or variant row is locked before its ledger is summed again, so a movement
+// committed meanwhile is not overwritten.
+func (r *StockRepository) Reconcile(ctx context.Context) ([]model.StockDiscrepancy, error) {
+	found, err := r.Discrepancies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now().UTC()
+	result := make([]model.StockDiscrepancy, 0, len(found))
+	for _, d := range found {
+		if d.Quantity, err = lockStock(ctx, tx, d.ProductID, d.VariantID); err != nil {
+			if err == pgx.ErrNoRows {
+				continue
+			}
+			return nil, err
+		}
+		if d.WarehouseID != nil {
+			err = tx.QueryRow(ctx, `SELECT COALESCE((SELECT quantity FROM warehouse_stock
+				WHERE warehouse_id=$1 AND product_id=$2 AND variant_id IS NOT DISTINCT FROM $3), 0)`,
+				*d.WarehouseID, d.ProductID, d.VariantID).Scan(&d.Quantity)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := tx.QueryRow(ctx, `SELECT COALESCE(SUM(delta), 0)::int FROM stock_movements
+			WHERE product_id=$1 AND variant_id IS NOT DISTINCT FROM $2 AND ($3::uuid IS NULL OR warehouse_id = $3)`,
+			d.ProductID, d.VariantID, d.WarehouseID).Scan(&d.LedgerQuantity); err != nil {
+			return nil, err
+		}
+		if d.Quantity == d.LedgerQuantity {
+			continue
+		}
+		switch {
+		case d.WarehouseID != nil:
+			_, err = tx.Exec(ctx, `INSERT INTO warehouse_stock (warehouse_id, product_id, variant_id, quantity, updated_at)
+				VALUES ($1, $2, $3, $4, $5)
+				ON CONFLICT ON CONSTRAINT warehouse_stock_key DO UPDATE SET quantity = EXCLUDED.quantity, updated_at = EXCLUDED.updated_at`,
+				*d.WarehouseID, d.ProductID, d.VariantID, d.LedgerQuantity, now)
+		case d.VariantID != nil:
+			_, err = tx.Exec(ctx, `UPDATE product_variants SET quantity=$1, updated_at=$2 WHERE id=$3`, d.LedgerQuantity, now, *d.VariantID)
+		default:
+			_, err = tx.Exec(ctx, `UPDATE products SET quantity=$1, updated_at=$2 WHERE id=$3`, d.LedgerQuantity, now, d.ProductID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+	return result, tx.Commit(ctx)
+}
+
+// productStockLevel is the available quantity of the product and its variants together.
+const productStockLevel = `(` + productAvailable + ` + COALESCE((SELECT SUM(` + variantAvailable + `)
+	FROM product_variants WHERE product_variants.product_id = p.id), 0))`
+
+// categoryReorderPoint is the default reorder point of the product from its categories: for each
+// category the point of the nearest category up the tree that has one, the highest of them.
+const categoryReorderPoint = `(SELECT MAX(rp.reorder_point) FROM product_catagories pc
+	JOIN categories c ON c.id = pc.catagory_id
+	CROSS JOIN LATERAL (SELECT a.reorder_point FROM categories a
+		WHERE a.reorder_point IS NOT NULL AND (a.id = c.id OR c.path LIKE a.path || '/%')
+		ORDER BY a.level DESC LIMIT 1) rp
+	WHERE pc.product_id = p.id)`
+
+// ReorderPoint returns the own and the category reorder points of the product.
+func (r *StockRepository) ReorderPoint(ctx context.Context, productID uuid.UUID) (model.ReorderPoint, error) {
+	rp := model.ReorderPoint{ProductID: productID}
+	err := r.pool.QueryRow(ctx, `SELECT p.reorder_point, `+categoryReorderPoint+` FROM products p WHERE p.id=$1`, productID).
+		Scan(&rp.ReorderPoint, &rp.CategoryReorderPoint)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return rp, ErrNotFound
+		}
+		return rp, err
+	}
+	rp.Effective = rp.ReorderPoint
+	if rp.Effective == nil {
+		rp.Effective = rp.CategoryReorderPoint
+	}
+	return rp, nil
+}
+
+// SetProductReorderPoint sets the own reorder point of the product; nil falls back to the categories.
+func (r *StockRepository) SetProductReorderPoint(ctx context.Context, productID uuid.UUID, point *int) error {
+	cmd, err := r.pool.Exec(ctx, `UPDATE products SET reorder_point=$1 WHERE id=$2`, point, productID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SetCategoryReorderPoint sets the default reorder point of products in the category and its
+// subcategories that have no closer one; nil removes it.
+func (r *StockRepository) SetCategoryReorderPoint(ctx context.Context, categoryID uuid.UUID, point *int) error {
+	cmd, err := r.pool.Exec(ctx, `UPDATE categories SET reorder_point=$1 WHERE id=$2`, point, categoryID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// maxAlertAttempts is how many times delivery of an alert is tried before it is left undelivered.
+const maxAlertAttempts = 10
+
+// alertClaimTimeout is how long a claimed alert stays with its dispatcher; after that it is
+// considered abandoned and claimed again. A run stops sending after half of it has passed.
+const alertClaimTimeout = 10 * time.Minute
+
+// DispatchAlerts passes up to limit undelivered alerts, oldest first, to send and marks the ones
+// it accepted as sent; failed ones are retried on the next run. Alerts are claimed and the claim
+// committed before sending, so no transaction is held open while send runs and concurrent
+// dispatchers skip them. The first delivery error is returned with the sent count.
+func (r *StockRepository) DispatchAlerts(ctx context.Context, limit int, send func(model.StockAlert) error) (int, error) {
+	claimedAt := time.Now().UTC().Truncate(time.Microsecond)
+	rows, err := r.pool.Query(ctx, `UPDATE stock_alerts a SET claimed_at=$1
+		FROM products p
+		WHERE p.id = a.product_id AND a.id IN (
+			SELECT id FROM stock_alerts
+			WHERE sent_at IS NULL AND attempts < $2 AND (claimed_at IS NULL OR claimed_at < $3)
+			ORDER BY created_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED)
+		RETURNING a.id, a.product_id, p.name, a.order_id, a.reorder_point, a.available, a.created_at`,
+		claimedAt, maxAlertAttempts, claimedAt.Add(-alertClaimTimeout), limit)
+	if err != nil {
+		return 0, err
+	}
+	alerts, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (model.StockAlert, error) {
+		var a model.StockAlert
+		err := row.Scan(&a.ID, &a.ProductID, &a.ProductName, &a.OrderID, &a.ReorderPoint, &a.Available, &a.CreatedAt)
+		return a, err
+	})
+	if err != nil {
+		return 0, err
+	}
+	slices.SortFunc(alerts, func(a, b model.StockAlert) int { return a.CreatedAt.Compare(b.CreatedAt) })
+
+	// Updates only touch alerts still under this run's claim, a claim taken over is left alone.
+	sent := 0
+	var firstErr error
+	for i, a := range alerts {
+		if time.Since(claimedAt) > alertClaimTimeout/2 {
+			ids := make([]uuid.UUID, 0, len(alerts)-i)
+			for _, rest := range alerts[i:] {
+				ids = append(ids, rest.ID)
+			}
+			if _, err := r.pool.Exec(ctx, `UPDATE stock_alerts SET claimed_at=NULL WHERE id = ANY($1) AND claimed_at=$2`,
+				ids, claimedAt); err != nil {
+				return sent, err
+			}
+			break
+		}
+		if err := send(a); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			if _, err := r.pool.Exec(ctx, `UPDATE stock_alerts SET attempts = attempts + 1, last_error=$1, claimed_at=NULL
+				WHERE id=$2 AND claimed_at=$3`, err.Error(), a.ID, claimedAt); err != nil {
+				return sent, err
+			}
+			continue
+		}
+		if _, err := r.pool.Exec(ctx, `UPDATE stock_alerts SET attempts = attempts + 1, last_error=NULL, sent_at=$1, claimed_at=NULL
+			WHERE id=$2 AND claimed_at=$3`, time.Now().UTC(), a.ID, claimedAt); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, firstErr
+}
+
+// checkReorderPoint queues a low-stock alert when taking qty units of the product for the order
+// brought its available stock from above the reorder point to or below it. Callers lock the
+// product row first.
+func checkReorderPoint(ctx context.Context, tx pgx.Tx, orderID, productID uuid.UUID, qty int, at time.Time) error {
+	var level int
+	var point *int
+	err := tx.QueryRow(ctx, `SELECT `+productStockLevel+`, COALESCE(p.reorder_point, `+categoryReorderPoint+`)
+		FROM products p WHERE p.id=$1`, productID).Scan(&level, &point)
+	if err != nil || point == nil || level > *point || level+qty <= *point {
+		return err
+	}
+	_, err = tx.Exec(ctx, `INSERT INTO stock_alerts (id, product_id, order_id, reorder_point, available, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`, uuid.New(), productID, orderID, *point, level, at)
+	return err
+}
+
+// lockStock locks the product or variant row and returns its cached quantity; a variant of
+// another product is not found.
+func lockStock(ctx context.Context, tx pgx.Tx, productID uuid.UUID, variantID *uuid.UUID) (int, error) {
+	var quantity int
+	var err error
+	if variantID != nil {
+		err = tx.QueryRow(ctx, `SELECT quantity FROM product_variants WHERE id=$1 AND product_id=$2 FOR UPDATE`, *variantID, productID).Scan(&quantity)
+	} else {
+		err = tx.QueryRow(ctx, `SELECT quantity FROM products WHERE id=$1 FOR UPDATE`, productID).Scan(&quantity)
+	}
+	return quantity, err
+}
+
+// checkStockItem checks that the variant belongs to the product, or that a product without
+// variant has no variants.
+func checkStockItem(ctx context.Context, tx pgx.Tx, productID uuid.UUID, variantID *uuid.UUID) error {
+	var found, hasVariants bool
+	err := tx.QueryRow(ctx, `SELECT TRUE, EXISTS (SELECT 1 FROM product_variants WHERE product_id = p.id)
+		FROM products p WHERE p.id=$1`, productID).Scan(&found, &hasVariants)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+	if variantID == nil {
+		if hasVariants {
+			return ErrVariantRequired
+		}
+		return nil
+	}
+	var ok bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM product_variants WHERE id=$1 AND product_id=$2)`, *variantID, productID).Scan(&ok); err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// changeStock moves the product (or variant) stock in the warehouse by delta, keeps the cached
+// total of the product or variant and records the movement. Warehouse stock cannot go below zero.
+// Callers lock the product or variant row first.
+func changeStock(ctx context.Context, tx pgx.Tx, warehouseID, productID uuid.UUID, variantID *uuid.UUID, delta int, reason string, referenceID *uuid.UUID, at time.Time) error {
+	if delta == 0 {
+		return nil
+	}
+	var left int
+	err := tx.QueryRow(ctx, `INSERT INTO warehouse_stock (warehouse_id, product_id, variant_id, quantity, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT ON CONSTRAINT warehouse_stock_key
+		DO UPDATE SET quantity = warehouse_stock.quantity + EXCLUDED.quantity, updated_at = EXCLUDED.updated_at
+		RETURNING quantity`, warehouseID, productID, variantID, delta, at).Scan(&left)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if left < 0 {
+		return ErrNotEnoughStock
+	}
+
+	if variantID != nil {
+		_, err = tx.Exec(ctx, `UPDATE product_variants SET quantity = quantity + $1, updated_at=$2 WHERE id=$3`, delta, at, *variantID)
+	} else {
+		_, err = tx.Exec(ctx, `UPDATE products SET quantity = quantity + $1, updated_at=$2 WHERE id=$3`, delta, at, productID)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `INSERT INTO stock_movements (`+stockMovementColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		uuid.New(), warehouseID, productID, variantID, delta, reason, referenceID, actor.FromContext(ctx), actor.RequestID(ctx), at)
+	return err
+}
+
+// adjustDefaultStock changes stock in the default warehouse, for changes that name no warehouse.
+func adjustDefaultStock(ctx context.Context, tx pgx.Tx, productID uuid.UUID, variantID *uuid.UUID, delta int, reason string, referenceID *uuid.UUID, at time.Time) error {
+	if delta == 0 {
+		return nil
+	}
+	warehouseID, err := defaultWarehouse(ctx, tx)
+	if err != nil {
+		return err
+	}
+	return changeStock(ctx, tx, warehouseID, productID, variantID, delta, reason, referenceID, at)
+}
+
+// defaultWarehouse returns the warehouse that takes stock changes made without a warehouse.
+func defaultWarehouse(ctx context.Context, tx pgx.Tx) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.QueryRow(ctx, `SELECT id FROM warehouses WHERE is_default`).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return id, ErrNoDefaultWarehouse
+	}
+	return id, err
+}
+
+func scanStockMovement(row pgx.Row) (model.StockMovement, error) {
+	var m model.StockMovement
+	err := row.Scan(&m.ID, &m.WarehouseID, &m.ProductID, &m.VariantID, &m.Delta, &m.Reason, &m.ReferenceID, &m.Actor, &m.RequestID, &m.CreatedAt)
+	return m, err
+}
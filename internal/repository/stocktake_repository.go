@@ -0,0 +1,343 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"store-service/internal/actor"
+	"store-service/internal/model"
+)
+
+const stocktakeColumns = `s.id, s.warehouse_id, s.status, s.note, s.actor,
+	(SELECT COUNT(*) FROM stocktake_counts sc WHERE sc.stocktake_id = s.id)::int, s.started_at, s.closed_at`
+
+// movedSinceCount sums ledger movements of the counted item in the stocktake warehouse after it was
+// counted; the stock at the count is the current one minus them.
+const movedSinceCount = `COALESCE((SELECT SUM(m.delta) FROM stock_movements m
+	WHERE m.warehouse_id = s.warehouse_id AND m.product_id = sc.product_id
+	  AND m.variant_id IS NOT DISTINCT FROM sc.variant_id AND m.created_at > sc.counted_at), 0)`
+
+// StocktakeFilter narrows stocktake lists; empty fields match everything.
+type StocktakeFilter struct {
+	Status      string
+	WarehouseID *uuid.UUID
+}
+
+type StocktakeRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewStocktakeRepository(pool *pgxpool.Pool) *StocktakeRepository {
+	return &StocktakeRepository{pool: pool}
+}
+
+// Create opens a stocktake of the warehouse, or of the default one when none is given. A warehouse
+// is counted by one stocktake at a time.
+func (r *StocktakeRepository) Create(ctx context.Context, st *model.Stocktake) error {
+	st.ID = uuid.New()
+	st.Status = model.StocktakeOpen
+	st.Actor = actor.FromContext(ctx)
+	st.StartedAt = time.Now().UTC()
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if st.WarehouseID == uuid.Nil {
+		if st.WarehouseID, err = defaultWarehouse(ctx, tx); err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec(ctx, `INSERT INTO stocktakes (id, warehouse_id, status, note, actor, started_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+		st.ID, st.WarehouseID, st.Status, st.Note, st.Actor, st.StartedAt)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return ErrStocktakeInProgress
+		}
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *StocktakeRepository) Get(ctx context.Context, id uuid.UUID) (model.Stocktake, error) {
+	st, err := scanStocktake(r.pool.QueryRow(ctx, `SELECT `+stocktakeColumns+` FROM stocktakes s WHERE s.id=$1`, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return st, ErrNotFound
+		}
+		return st, err
+	}
+	return st, nil
+}
+
+var stocktakeKeyset = keyset{
+	columns: []string{"s.started_at", "s.id"},
+	types:   []string{"timestamptz", "uuid"},
+	desc:    true,
+}
+
+// List returns stocktakes newest first.
+func (r *StocktakeRepository) List(ctx context.Context, f StocktakeFilter, p PageRequest) ([]model.Stocktake, Page, error) {
+	var b queryBuilder
+	if f.Status != "" {
+		b.where("s.status = " + b.arg(f.Status))
+	}
+	if f.WarehouseID != nil {
+		b.where("s.warehouse_id = " + b.arg(*f.WarehouseID))
+	}
+	var total *int64
+	if p.WithTotal {
+		var err error
+		if total, err = countRows(ctx, r.pool, "stocktakes s", b); err != nil {
+			return nil, Page{}, err
+		}
+	}
+	tail, err := stocktakeKeyset.paginate(&b, "", p)
+	if err != nil {
+		return nil, Page{}, err
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT `+stocktakeColumns+` FROM stocktakes s`+b.whereClause()+tail, b.args...)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	defer rows.Close()
+
+	var result []model.Stocktake
+	for rows.Next() {
+		st, err := scanStocktake(rows)
+		if err != nil {
+			return nil, Page{}, err
+		}
+		result = append(result, st)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Page{}, err
+	}
+
+	result, page := finishPage(result, p.Limit, "", func(st model.Stocktake) []string {
+		return []string{st.StartedAt.Format(time.RFC3339Nano), st.ID.String()}
+	})
+	page.Total = total
+	return result, page, nil
+}
+
+// Count records counted quantities of an open stocktake. Batches from several scanners may arrive
+// at once; a repeated count of a product or variant replaces the previous one. Counts without a
+// time are taken now.
+func (r *StocktakeRepository) Count(ctx context.Context, id uuid.UUID, counts []model.StocktakeCount) error {
+	now := time.Now().UTC()
+	who := actor.FromContext(ctx)
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Batches share the lock; commit and cancel wait for them to finish.
+	var status string
+	var startedAt time.Time
+	err = tx.QueryRow(ctx, `SELECT status, started_at FROM stocktakes WHERE id=$1 FOR SHARE`, id).Scan(&status, &startedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+	if status != model.StocktakeOpen {
+		return ErrStocktakeClosed
+	}
+
+	for i := range counts {
+		c := &counts[i]
+		if c.CountedAt.IsZero() {
+			c.CountedAt = now
+		}
+		c.CountedAt = c.CountedAt.UTC()
+		if c.CountedAt.Before(startedAt) || c.CountedAt.After(now) {
+			return ErrInvalidStocktakeCount
+		}
+		if err := checkStockItem(ctx, tx, c.ProductID, c.VariantID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `INSERT INTO stocktake_counts (stocktake_id, product_id, variant_id, quantity, counted_at, actor)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT ON CONSTRAINT stocktake_counts_key
+			DO UPDATE SET quantity = EXCLUDED.quantity, counted_at = EXCLUDED.counted_at, actor = EXCLUDED.actor`,
+			id, c.ProductID, c.VariantID, c.Quantity, c.CountedAt, who)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// Discrepancies compares the counts with the warehouse stock the ledger had when each item was
+// counted, so sales made during the count are not taken for shortages. Only items that differ are
+// returned; after commit the recorded expectations and adjustments are shown.
+func (r *StocktakeRepository) Discrepancies(ctx context.Context, id uuid.UUID) ([]model.StocktakeDiscrepancy, error) {
+	var exists bool
+	if err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM stocktakes WHERE id=$1)`, id).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	rows, err := r.pool.Query(ctx, `
+SELECT sc.product_id, sc.variant_id, sc.quantity, sc.counted_at, COALESCE(ws.quantity, 0),
+       COALESCE(sc.expected, COALESCE(ws.quantity, 0) - `+movedSinceCount+`), sc.adjustment
+FROM stocktake_counts sc
+JOIN stocktakes s ON s.id = sc.stocktake_id
+LEFT JOIN warehouse_stock ws ON ws.warehouse_id = s.warehouse_id AND ws.product_id = sc.product_id
+     AND ws.variant_id IS NOT DISTINCT FROM sc.variant_id
+WHERE sc.stocktake_id = $1
+ORDER BY sc.product_id, sc.variant_id NULLS FIRST`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]model.StocktakeDiscrepancy, 0)
+	for rows.Next() {
+		var d model.StocktakeDiscrepancy
+		var adjustment *int
+		if err := rows.Scan(&d.ProductID, &d.VariantID, &d.Counted, &d.CountedAt, &d.Quantity, &d.Expected, &adjustment); err != nil {
+			return nil, err
+		}
+		d.Difference = d.Counted - d.Expected
+		if adjustment != nil {
+			d.Adjustment = *adjustment
+		} else {
+			d.Adjustment = stocktakeAdjustment(d.Difference, d.Quantity)
+		}
+		if d.Difference != 0 {
+			result = append(result, d)
+		}
+	}
+	return result, rows.Err()
+}
+
+// Commit applies the counts in one transaction. Each item's stock is moved by the difference
+// between its count and the ledger stock at the count time, as an adjustment movement referencing
+// the stocktake; movements after the count, such as sales during it, stay in effect. Items that
+// were not counted keep their stock.
+func (r *StocktakeRepository) Commit(ctx context.Context, id uuid.UUID) error {
+	now := time.Now().UTC()
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var warehouseID uuid.UUID
+	var status string
+	err = tx.QueryRow(ctx, `SELECT warehouse_id, status FROM stocktakes WHERE id=$1 FOR UPDATE`, id).Scan(&warehouseID, &status)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+	if status != model.StocktakeOpen {
+		return ErrStocktakeClosed
+	}
+
+	// Rows are locked in product, variant order, the same as payments take their holds.
+	rows, err := tx.Query(ctx, `SELECT product_id, variant_id, quantity, counted_at FROM stocktake_counts
+		WHERE stocktake_id=$1 ORDER BY product_id, variant_id NULLS FIRST`, id)
+	if err != nil {
+		return err
+	}
+	var counts []model.StocktakeCount
+	for rows.Next() {
+		var c model.StocktakeCount
+		if err := rows.Scan(&c.ProductID, &c.VariantID, &c.Quantity, &c.CountedAt); err != nil {
+			rows.Close()
+			return err
+		}
+		counts = append(counts, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, c := range counts {
+		if _, err := lockStock(ctx, tx, c.ProductID, c.VariantID); err != nil {
+			return err
+		}
+		var current, expected int
+		err := tx.QueryRow(ctx, `
+SELECT COALESCE(ws.quantity, 0), COALESCE(ws.quantity, 0) - `+movedSinceCount+`
+FROM stocktake_counts sc
+JOIN stocktakes s ON s.id = sc.stocktake_id
+LEFT JOIN warehouse_stock ws ON ws.warehouse_id = s.warehouse_id AND ws.product_id = sc.product_id
+     AND ws.variant_id IS NOT DISTINCT FROM sc.variant_id
+WHERE sc.stocktake_id = $1 AND sc.product_id = $2 AND sc.variant_id IS NOT DISTINCT FROM $3`,
+			id, c.ProductID, c.VariantID).Scan(&current, &expected)
+		if err != nil {
+			return err
+		}
+		adjustment := stocktakeAdjustment(c.Quantity-expected, current)
+		if err := changeStock(ctx, tx, warehouseID, c.ProductID, c.VariantID, adjustment, model.StockAdjustment, &id, now); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE stocktake_counts SET expected=$1, adjustment=$2
+			WHERE stocktake_id=$3 AND product_id=$4 AND variant_id IS NOT DISTINCT FROM $5`,
+			expected, adjustment, id, c.ProductID, c.VariantID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE stocktakes SET status=$1, closed_at=$2 WHERE id=$3`, model.StocktakeCommitted, now, id); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Cancel closes an open stocktake without touching stock.
+func (r *StocktakeRepository) Cancel(ctx context.Context, id uuid.UUID) error {
+	cmd, err := r.pool.Exec(ctx, `UPDATE stocktakes SET status=$1, closed_at=$2 WHERE id=$3 AND status=$4`,
+		model.StocktakeCancelled, time.Now().UTC(), id, model.StocktakeOpen)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() > 0 {
+		return nil
+	}
+	var exists bool
+	if err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM stocktakes WHERE id=$1)`, id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	return ErrStocktakeClosed
+}
+
+// stocktakeAdjustment is the stock change for a count difference. When more was sold since the
+// count than was counted, the stock is brought to zero rather than below it.
+func stocktakeAdjustment(difference, current int) int {
+	if current+difference < 0 {
+		return -current
+	}
+	return difference
+}
+
+func scanStocktake(row pgx.Row) (model.Stocktake, error) {
+	var st model.Stocktake
+	err := row.Scan(&st.ID, &st.WarehouseID, &st.Status, &st.Note, &st.Actor, &st.Counted, &st.StartedAt, &st.ClosedAt)
+	return st, err
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"store-service/internal/model"
+)
+
+const supplierColumns = `id, name, email, phone, address, created_at, updated_at`
+
+type SupplierRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSupplierRepository(pool *pgxpool.Pool) *SupplierRepository {
+	return &SupplierRepository{pool: pool}
+}
+
+func (r *SupplierRepository) Create(ctx context.Context, s *model.Supplier) error {
+	now := time.Now().UTC()
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	s.CreatedAt = now
+	s.UpdatedAt = now
+
+	_, err := r.pool.Exec(ctx, `INSERT INTO suppliers (`+supplierColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		s.ID, s.Name, s.Email, s.Phone, s.Address, s.CreatedAt, s.UpdatedAt)
+	return err
+}
+
+func (r *SupplierRepository) Get(ctx context.Context, id uuid.UUID) (model.Supplier, error) {
+	s, err := scanSupplier(r.pool.QueryRow(ctx, `SELECT `+supplierColumns+` FROM suppliers WHERE id=$1`, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return s, ErrNotFound
+		}
+		return s, err
+	}
+	return s, nil
+}
+
+func (r *SupplierRepository) Update(ctx context.Context, s *model.Supplier) error {
+	s.UpdatedAt = time.Now().UTC()
+	query := `UPDATE suppliers SET name=$1, email=$2, phone=$3, address=$4, updated_at=$5 WHERE id=$6 RETURNING created_at`
+	err := r.pool.QueryRow(ctx, query, s.Name, s.Email, s.Phone, s.Address, s.UpdatedAt, s.ID).Scan(&s.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Delete removes the supplier; suppliers with purchase orders are kept for their history.
+func (r *SupplierRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	cmd, err := r.pool.Exec(ctx, `DELETE FROM suppliers WHERE id=$1`, id)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrSupplierInUse
+		}
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+var supplierKeyset = keyset{
+	columns: []string{"created_at", "id"},
+	types:   []string{"timestamptz", "uuid"},
+	desc:    true,
+}
+
+func (r *SupplierRepository) List(ctx context.Context, p PageRequest) ([]model.Supplier, Page, error) {
+	var b queryBuilder
+	var total *int64
+	if p.WithTotal {
+		var err error
+		if total, err = countRows(ctx, r.pool, "suppliers", b); err != nil {
+			return nil, Page{}, err
+		}
+	}
+	tail, err := supplierKeyset.paginate(&b, "", p)
+	if err != nil {
+		return nil, Page{}, err
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT `+supplierColumns+` FROM suppliers`+b.whereClause()+tail, b.args...)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	defer rows.Close()
+
+	var result []model.Supplier
+	for rows.Next() {
+		s, err := scanSupplier(rows)
+		if err != nil {
+			return nil, Page{}, err
+		}
+		result = append(result, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Page{}, err
+	}
+
+	result, page := finishPage(result, p.Limit, "", func(s model.Supplier) []string {
+		return []string{s.CreatedAt.Format(time.RFC3339Nano), s.ID.String()}
+	})
+	page.Total = total
+	return result, page, nil
+}
+
+func scanSupplier(row pgx.Row) (model.Supplier, error) {
+	var s model.Supplier
+	err := row.Scan(&s.ID, &s.Name, &s.Email, &s.Phone, &s.Address, &s.CreatedAt, &s.UpdatedAt)
+	return s, err
+}
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"store-service/internal/model"
+)
+
+const variantColumns = `id, product_id, sku, options, price, quantity, created_at, updated_at, ` + variantAvailable + `, ` + variantIncoming
+
+type VariantRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewVariantRepository(pool *pgxpool.Pool) *VariantRepository {
+	return &VariantRepository{pool: pool}
+}
+
+// ReplaceOptions replaces option definitions of the product; positions follow the slice order.
+// check is called with the options of every existing variant while the product row is locked,
+// so variants cannot change between the check and the replacement.
+func (r *VariantRepository) ReplaceOptions(ctx context.Context, productID uuid.UUID, options []model.ProductOption, check func(values map[string]string) error) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.QueryRow(ctx, `SELECT id FROM products WHERE id=$1 FOR UPDATE`, productID).Scan(new(uuid.UUID)); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	rows, err := tx.Query(ctx, `SELECT options FROM product_variants WHERE product_id=$1`, productID)
+	if err != nil {
+		return err
+	}
+	values, err := pgx.CollectRows(rows, pgx.RowTo[map[string]string])
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		if err := check(v); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM product_options WHERE product_id=$1`, productID); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for i := range options {
+		o := &options[i]
+		o.ID = uuid.New()
+		o.ProductID = productID
+		o.Position = i
+		o.CreatedAt = now
+		o.UpdatedAt = now
+		_, err := tx.Exec(ctx, `INSERT INTO product_options (id, product_id, name, "values", position, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`, o.ID, o.ProductID, o.Name, o.Values, o.Position, o.CreatedAt, o.UpdatedAt)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return ErrInvalidVariantOptions
+			}
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *VariantRepository) ListOptions(ctx context.Context, productID uuid.UUID) ([]model.ProductOption, error) {
+	return listOptions(ctx, r.pool, productID)
+}
+
+func listOptions(ctx context.Context, q querier, productID uuid.UUID) ([]model.ProductOption, error) {
+	rows, err := q.Query(ctx, `SELECT id, product_id, name, "values", position, created_at, updated_at
+		FROM product_options WHERE product_id=$1 ORDER BY position ASC`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []model.ProductOption
+	for rows.Next() {
+		var o model.ProductOption
+		if err := rows.Scan(&o.ID, &o.ProductID, &o.Name, &o.Values, &o.Position, &o.CreatedAt, &o.UpdatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, o)
+	}
+	return result, rows.Err()
+}
+
+// lockOptions locks the product, so its options cannot be replaced until the transaction ends,
+// and passes the product currency and option definitions to check.
+func lockOptions(ctx context.Context, tx pgx.Tx, productID uuid.UUID, check func(model.Currency, []model.ProductOption) error) error {
+	var currency model.Currency
+	if err := tx.QueryRow(ctx, `SELECT currency FROM products WHERE id=$1 FOR UPDATE`, productID).Scan(&currency); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+	defs, err := listOptions(ctx, tx, productID)
+	if err != nil {
+		return err
+	}
+	return check(currency, defs)
+}
+
+// Create inserts the variant; its initial stock is received into the default warehouse. check is
+// called with the product currency and options while the product is locked.
+func (r *VariantRepository) Create(ctx context.Context, v *model.ProductVariant, check func(model.Currency, []model.ProductOption) error) error {
+	now := time.Now().UTC()
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	v.CreatedAt = now
+	v.UpdatedAt = now
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockOptions(ctx, tx, v.ProductID, check); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO product_variants (id, product_id, sku, options, price, quantity, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, 0, $6, $7)`
+	_, err = tx.Exec(ctx, query, v.ID, v.ProductID, v.SKU, v.Options, v.Price, v.CreatedAt, v.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateSKU
+		}
+		if isForeignKeyViolation(err) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if err := adjustDefaultStock(ctx, tx, v.ProductID, &v.ID, v.Quantity, model.StockReceipt, nil, now); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	v.Available = v.Quantity
+	return nil
+}
+
+func (r *VariantRepository) Get(ctx context.Context, productID, id uuid.UUID) (model.ProductVariant, error) {
+	v, err := scanVariant(r.pool.QueryRow(ctx, `SELECT `+variantColumns+` FROM product_variants WHERE id=$1 AND product_id=$2`, id, productID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return v, ErrNotFound
+		}
+		return v, err
+	}
+	return v, nil
+}
+
+// Update overwrites the variant; a changed quantity is recorded as a stock adjustment in the default
+// warehouse. check is called with the product currency and options while the product is locked.
+func (r *VariantRepository) Update(ctx context.Context, v *model.ProductVariant, check func(model.Currency, []model.ProductOption) error) error {
+	v.UpdatedAt = time.Now().UTC()
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockOptions(ctx, tx, v.ProductID, check); err != nil {
+		return err
+	}
+
+	var oldQuantity int
+	err = tx.QueryRow(ctx, `SELECT quantity FROM product_variants WHERE id=$1 AND product_id=$2 FOR UPDATE`, v.ID, v.ProductID).Scan(&oldQuantity)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+	if err := adjustDefaultStock(ctx, tx, v.ProductID, &v.ID, v.Quantity-oldQuantity, model.StockAdjustment, nil, v.UpdatedAt); err != nil {
+		return err
+	}
+
+	query := `UPDATE product_variants SET sku=$1, options=$2, price=$3, updated_at=$4 WHERE id=$5
+		RETURNING ` + variantAvailable + `, ` + variantIncoming
+	err = tx.QueryRow(ctx, query, v.SKU, v.Options, v.Price, v.UpdatedAt, v.ID).Scan(&v.Available, &v.Incoming)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateSKU
+		}
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *VariantRepository) Delete(ctx context.Context, productID, id uuid.UUID) error {
+	cmd, err := r.pool.Exec(ctx, `DELETE FROM product_variants WHERE id=$1 AND product_id=$2`, id, productID)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrVariantInUse
+		}
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *VariantRepository) List(ctx context.Context, productID uuid.UUID) ([]model.ProductVariant, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+variantColumns+` FROM product_variants WHERE product_id=$1 ORDER BY created_at ASC, id ASC`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []model.ProductVariant
+	for rows.Next() {
+		v, err := scanVariant(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	return result, rows.Err()
+}
+
+func scanVariant(row pgx.Row) (model.ProductVariant, error) {
+	var v model.ProductVariant
+	err := row.Scan(&v.ID, &v.ProductID, &v.SKU, &v.Options, &v.Price, &v.Quantity, &v.CreatedAt, &v.UpdatedAt, &v.Available, &v.Incoming)
+	return v, err
+}
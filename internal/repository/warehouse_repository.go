@@ -0,0 +1,430 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"store-service/internal/actor"
+	"store-service/internal/model"
+)
+
+const warehouseColumns = `id, code, name, priority, latitude, longitude, is_default, active, created_at, updated_at`
+
+const warehouseTransferColumns = `id, from_warehouse_id, to_warehouse_id, note, actor, created_at`
+
+// WarehouseStockFilter selects stock levels of one warehouse or one product.
+type WarehouseStockFilter struct {
+	WarehouseID *uuid.UUID
+	ProductID   *uuid.UUID
+}
+
+type WarehouseRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewWarehouseRepository(pool *pgxpool.Pool) *WarehouseRepository {
+	return &WarehouseRepository{pool: pool}
+}
+
+// Create inserts the warehouse; a new default warehouse takes the flag over from the previous one.
+func (r *WarehouseRepository) Create(ctx context.Context, w *model.Warehouse) error {
+	now := time.Now().UTC()
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	w.CreatedAt = now
+	w.UpdatedAt = now
+	if w.IsDefault && !w.Active {
+		return ErrDefaultWarehouse
+	}
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if w.IsDefault {
+		if err := dropDefaultWarehouse(ctx, tx, w.ID, now); err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec(ctx, `INSERT INTO warehouses (`+warehouseColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		w.ID, w.Code, w.Name, w.Priority, w.Latitude, w.Longitude, w.IsDefault, w.Active, w.CreatedAt, w.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateWarehouseCode
+		}
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *WarehouseRepository) Get(ctx context.Context, id uuid.UUID) (model.Warehouse, error) {
+	w, err := scanWarehouse(r.pool.QueryRow(ctx, `SELECT `+warehouseColumns+` FROM warehouses WHERE id=$1`, id))
+	if err == pgx.ErrNoRows {
+		return w, ErrNotFound
+	}
+	return w, err
+}
+
+// List returns all warehouses in allocation order.
+func (r *WarehouseRepository) List(ctx context.Context) ([]model.Warehouse, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+warehouseColumns+` FROM warehouses ORDER BY priority, code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]model.Warehouse, 0)
+	for rows.Next() {
+		w, err := scanWarehouse(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, w)
+	}
+	return result, rows.Err()
+}
+
+// Update saves the warehouse. The default warehouse can only stop being default by making another
+// one default, and cannot be deactivated.
+func (r *WarehouseRepository) Update(ctx context.Context, w *model.Warehouse) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var wasDefault bool
+	var createdAt time.Time
+	err = tx.QueryRow(ctx, `SELECT is_default, created_at FROM warehouses WHERE id=$1 FOR UPDATE`, w.ID).Scan(&wasDefault, &createdAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+	if (wasDefault && !w.IsDefault) || (w.IsDefault && !w.Active) {
+		return ErrDefaultWarehouse
+	}
+
+	w.CreatedAt = createdAt
+	w.UpdatedAt = time.Now().UTC()
+	if w.IsDefault && !wasDefault {
+		if err := dropDefaultWarehouse(ctx, tx, w.ID, w.UpdatedAt); err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec(ctx, `UPDATE warehouses
+		SET code=$1, name=$2, priority=$3, latitude=$4, longitude=$5, is_default=$6, active=$7, updated_at=$8
+		WHERE id=$9`, w.Code, w.Name, w.Priority, w.Latitude, w.Longitude, w.IsDefault, w.Active, w.UpdatedAt, w.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateWarehouseCode
+		}
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Delete removes a warehouse that never held stock; warehouses with history are deactivated instead.
+func (r *WarehouseRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	cmd, err := r.pool.Exec(ctx, `DELETE FROM warehouses WHERE id=$1 AND NOT is_default`, id)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrWarehouseInUse
+		}
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		if _, err := r.Get(ctx, id); err != nil {
+			return err
+		}
+		return ErrDefaultWarehouse
+	}
+	return nil
+}
+
+// Stock returns stock levels of the warehouse or of the product across warehouses, with the
+// quantity still available after active holds.
+func (r *WarehouseRepository) Stock(ctx context.Context, f WarehouseStockFilter) ([]model.WarehouseStock, error) {
+	var b queryBuilder
+	if f.WarehouseID != nil {
+		if _, err := r.Get(ctx, *f.WarehouseID); err != nil {
+			return nil, err
+		}
+		b.where("ws.warehouse_id = " + b.arg(*f.WarehouseID))
+	}
+	if f.ProductID != nil {
+		if err := r.pool.QueryRow(ctx, `SELECT id FROM products WHERE id=$1`, *f.ProductID).Scan(new(uuid.UUID)); err != nil {
+			if err == pgx.ErrNoRows {
+				return nil, ErrNotFound
+			}
+			return nil, err
+		}
+		b.where("ws.product_id = " + b.arg(*f.ProductID))
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT ws.warehouse_id, ws.product_id, ws.variant_id, ws.quantity,
+			ws.quantity - COALESCE((SELECT SUM(sr.quantity) FROM stock_reservations sr
+				WHERE sr.warehouse_id = ws.warehouse_id AND sr.product_id = ws.product_id
+					AND sr.variant_id IS NOT DISTINCT FROM ws.variant_id AND `+activeReservation+`), 0),
+			ws.updated_at
+		FROM warehouse_stock ws
+		JOIN warehouses w ON w.id = ws.warehouse_id`+b.whereClause()+`
+		ORDER BY ws.product_id, ws.variant_id NULLS FIRST, w.priority, w.code`, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]model.WarehouseStock, 0)
+	for rows.Next() {
+		var st model.WarehouseStock
+		if err := rows.Scan(&st.WarehouseID, &st.ProductID, &st.VariantID, &st.Quantity, &st.Available, &st.UpdatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, st)
+	}
+	return result, rows.Err()
+}
+
+// SetStock sets the stock level of the product (or variant) in the warehouse from a count; the
+// difference is recorded as an adjustment.
+func (r *WarehouseRepository) SetStock(ctx context.Context, st *model.WarehouseStock) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.QueryRow(ctx, `SELECT id FROM warehouses WHERE id=$1`, st.WarehouseID).Scan(new(uuid.UUID)); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+	if _, err := lockStock(ctx, tx, st.ProductID, st.VariantID); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+	var current int
+	err = tx.QueryRow(ctx, `SELECT COALESCE((SELECT quantity FROM warehouse_stock
+		WHERE warehouse_id=$1 AND product_id=$2 AND variant_id IS NOT DISTINCT FROM $3), 0)`,
+		st.WarehouseID, st.ProductID, st.VariantID).Scan(&current)
+	if err != nil {
+		return err
+	}
+	st.UpdatedAt = time.Now().UTC()
+	if err := changeStock(ctx, tx, st.WarehouseID, st.ProductID, st.VariantID, st.Quantity-current, model.StockAdjustment, nil, st.UpdatedAt); err != nil {
+		return err
+	}
+	if st.Available, err = warehouseAvailable(ctx, tx, st.WarehouseID, st.ProductID, st.VariantID, uuid.Nil); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// CreateTransfer moves the lines from one warehouse to another at once. Only stock not held by
+// orders can leave the source; each line is written to the ledger as a pair of transfer movements
+// referencing the transfer.
+func (r *WarehouseRepository) CreateTransfer(ctx context.Context, t *model.WarehouseTransfer) error {
+	t.ID = uuid.New()
+	t.Actor = actor.FromContext(ctx)
+	t.CreatedAt = time.Now().UTC()
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var found, active int
+	err = tx.QueryRow(ctx, `SELECT COUNT(*), COUNT(*) FILTER (WHERE active AND id = $2) FROM warehouses WHERE id IN ($1, $2)`,
+		t.FromWarehouseID, t.ToWarehouseID).Scan(&found, &active)
+	if err != nil {
+		return err
+	}
+	if found != 2 {
+		return ErrNotFound
+	}
+	if active == 0 {
+		return ErrInvalidTransfer
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO warehouse_transfers (`+warehouseTransferColumns+`) VALUES ($1, $2, $3, $4, $5, $6)`,
+		t.ID, t.FromWarehouseID, t.ToWarehouseID, t.Note, t.Actor, t.CreatedAt); err != nil {
+		return err
+	}
+
+	// Rows are locked in product, variant order, the same as payments take their holds.
+	lines := make([]*model.WarehouseTransferLine, 0, len(t.Lines))
+	for i := range t.Lines {
+		lines = append(lines, &t.Lines[i])
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		if lines[i].ProductID != lines[j].ProductID {
+			return lines[i].ProductID.String() < lines[j].ProductID.String()
+		}
+		return variantKey(lines[i].VariantID) < variantKey(lines[j].VariantID)
+	})
+	for _, l := range lines {
+		if _, err := lockStock(ctx, tx, l.ProductID, l.VariantID); err != nil {
+			if err == pgx.ErrNoRows {
+				return ErrNotFound
+			}
+			return err
+		}
+		available, err := warehouseAvailable(ctx, tx, t.FromWarehouseID, l.ProductID, l.VariantID, uuid.Nil)
+		if err != nil {
+			return err
+		}
+		if available < l.Quantity {
+			return ErrNotEnoughStock
+		}
+		if err := changeStock(ctx, tx, t.FromWarehouseID, l.ProductID, l.VariantID, -l.Quantity, model.StockTransfer, &t.ID, t.CreatedAt); err != nil {
+			return err
+		}
+		if err := changeStock(ctx, tx, t.ToWarehouseID, l.ProductID, l.VariantID, l.Quantity, model.StockTransfer, &t.ID, t.CreatedAt); err != nil {
+			return err
+		}
+		l.ID = uuid.New()
+		if _, err := tx.Exec(ctx, `INSERT INTO warehouse_transfer_lines (id, transfer_id, product_id, variant_id, quantity)
+			VALUES ($1, $2, $3, $4, $5)`, l.ID, t.ID, l.ProductID, l.VariantID, l.Quantity); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *WarehouseRepository) GetTransfer(ctx context.Context, id uuid.UUID) (model.WarehouseTransfer, error) {
+	t, err := scanWarehouseTransfer(r.pool.QueryRow(ctx, `SELECT `+warehouseTransferColumns+` FROM warehouse_transfers WHERE id=$1`, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return t, ErrNotFound
+		}
+		return t, err
+	}
+	list := []model.WarehouseTransfer{t}
+	if err := r.loadTransferLines(ctx, list); err != nil {
+		return t, err
+	}
+	return list[0], nil
+}
+
+var warehouseTransferKeyset = keyset{
+	columns: []string{"created_at", "id"},
+	types:   []string{"timestamptz", "uuid"},
+	desc:    true,
+}
+
+// ListTransfers returns transfers newest first; with a warehouse, only ones from or to it.
+func (r *WarehouseRepository) ListTransfers(ctx context.Context, warehouseID *uuid.UUID, p PageRequest) ([]model.WarehouseTransfer, Page, error) {
+	var b queryBuilder
+	if warehouseID != nil {
+		arg := b.arg(*warehouseID)
+		b.where("(from_warehouse_id = " + arg + " OR to_warehouse_id = " + arg + ")")
+	}
+	var total *int64
+	if p.WithTotal {
+		var err error
+		if total, err = countRows(ctx, r.pool, "warehouse_transfers", b); err != nil {
+			return nil, Page{}, err
+		}
+	}
+	tail, err := warehouseTransferKeyset.paginate(&b, "", p)
+	if err != nil {
+		return nil, Page{}, err
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT `+warehouseTransferColumns+` FROM warehouse_transfers`+b.whereClause()+tail, b.args...)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	defer rows.Close()
+
+	var result []model.WarehouseTransfer
+	for rows.Next() {
+		t, err := scanWarehouseTransfer(rows)
+		if err != nil {
+			return nil, Page{}, err
+		}
+		result = append(result, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Page{}, err
+	}
+
+	result, page := finishPage(result, p.Limit, "", func(t model.WarehouseTransfer) []string {
+		return []string{t.CreatedAt.Format(time.RFC3339Nano), t.ID.String()}
+	})
+	page.Total = total
+	if err := r.loadTransferLines(ctx, result); err != nil {
+		return nil, Page{}, err
+	}
+	return result, page, nil
+}
+
+func (r *WarehouseRepository) loadTransferLines(ctx context.Context, list []model.WarehouseTransfer) error {
+	if len(list) == 0 {
+		return nil
+	}
+	index := make(map[uuid.UUID]int, len(list))
+	ids := make([]uuid.UUID, 0, len(list))
+	for i, t := range list {
+		index[t.ID] = i
+		ids = append(ids, t.ID)
+		list[i].Lines = make([]model.WarehouseTransferLine, 0)
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT transfer_id, id, product_id, variant_id, quantity
+		FROM warehouse_transfer_lines WHERE transfer_id = ANY($1) ORDER BY product_id, variant_id NULLS FIRST`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var transferID uuid.UUID
+		var l model.WarehouseTransferLine
+		if err := rows.Scan(&transferID, &l.ID, &l.ProductID, &l.VariantID, &l.Quantity); err != nil {
+			return err
+		}
+		i := index[transferID]
+		list[i].Lines = append(list[i].Lines, l)
+	}
+	return rows.Err()
+}
+
+// variantKey orders lines without a variant first.
+func variantKey(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+// dropDefaultWarehouse clears the flag of the current default warehouse other than id.
+func dropDefaultWarehouse(ctx context.Context, tx pgx.Tx, id uuid.UUID, at time.Time) error {
+	_, err := tx.Exec(ctx, `UPDATE warehouses SET is_default=FALSE, updated_at=$1 WHERE is_default AND id<>$2`, at, id)
+	return err
+}
+
+func scanWarehouse(row pgx.Row) (model.Warehouse, error) {
+	var w model.Warehouse
+	err := row.Scan(&w.ID, &w.Code, &w.Name, &w.Priority, &w.Latitude, &w.Longitude, &w.IsDefault, &w.Active, &w.CreatedAt, &w.UpdatedAt)
+	return w, err
+}
+
+func scanWarehouseTransfer(row pgx.Row) (model.WarehouseTransfer, error) {
+	var t model.WarehouseTransfer
+	err := row.Scan(&t.ID, &t.FromWarehouseID, &t.ToWarehouseID, &t.Note, &t.Actor, &t.CreatedAt)
+	return t, err
+}
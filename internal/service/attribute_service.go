@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"store-service/internal/model"
+	"store-service/internal/repository"
+	"store-service/internal/storage"
+)
+
+var attributeCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+type AttributeService struct {
+	repo     *repository.AttributeRepository
+	products *repository.ProductRepository
+	media    mediaLoader
+}
+
+func NewAttributeService(repo *repository.AttributeRepository, products *repository.ProductRepository, mediaRepo *repository.MediaRepository, store storage.Storage) *AttributeService {
+	return &AttributeService{repo: repo, products: products, media: mediaLoader{repo: mediaRepo, store: store}}
+}
+
+func (s *AttributeService) Create(ctx context.Context, a *model.Attribute) error {
+	if err := validateAttribute(*a); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, a)
+}
+
+func (s *AttributeService) Get(ctx context.Context, id uuid.UUID) (model.Attribute, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// Update changes name, type and options; the stored code is kept.
+func (s *AttributeService) Update(ctx context.Context, a *model.Attribute) error {
+	current, err := s.repo.Get(ctx, a.ID)
+	if err != nil {
+		return err
+	}
+	a.Code = current.Code
+	if err := validateAttribute(*a); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, a)
+}
+
+func (s *AttributeService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *AttributeService) List(ctx context.Context) ([]model.Attribute, error) {
+	return s.repo.List(ctx)
+}
+
+// ReplaceForCategory attaches the attributes to the category and returns the effective set,
+// including attributes inherited from ancestors.
+func (s *AttributeService) ReplaceForCategory(ctx context.Context, categoryID uuid.UUID, attributeIDs []uuid.UUID) ([]model.Attribute, error) {
+	if err := s.repo.ReplaceForCategory(ctx, categoryID, attributeIDs); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByCategory(ctx, categoryID)
+}
+
+func (s *AttributeService) ListByCategory(ctx context.Context, categoryID uuid.UUID) ([]model.Attribute, error) {
+	return s.repo.ListByCategory(ctx, categoryID)
+}
+
+// SetProductAttributes validates values against attribute definitions and replaces them on the product.
+// Null values are dropped.
+func (s *AttributeService) SetProductAttributes(ctx context.Context, productID uuid.UUID, values map[string]any) (model.Product, error) {
+	codes := make([]string, 0, len(values))
+	for code, v := range values {
+		if v == nil {
+			delete(values, code)
+			continue
+		}
+		codes = append(codes, code)
+	}
+
+	defs, err := s.repo.ListByCodes(ctx, codes)
+	if err != nil {
+		return model.Product{}, err
+	}
+	if len(defs) != len(codes) {
+		return model.Product{}, repository.ErrInvalidAttributeValue
+	}
+	for _, d := range defs {
+		if !validAttributeValue(d, values[d.Code]) {
+			return model.Product{}, repository.ErrInvalidAttributeValue
+		}
+	}
+	p, err := s.products.SetAttributes(ctx, productID, values)
+	if err != nil {
+		return p, err
+	}
+	return p, s.media.attach(ctx, &p)
+}
+
+func validateAttribute(a model.Attribute) error {
+	if !attributeCodePattern.MatchString(a.Code) || a.Name == "" {
+		return repository.ErrInvalidAttribute
+	}
+	switch a.Type {
+	case model.AttributeEnum:
+		if len(a.Options) == 0 || hasDuplicates(a.Options) {
+			return repository.ErrInvalidAttribute
+		}
+	case model.AttributeString, model.AttributeNumber, model.AttributeBoolean:
+		if len(a.Options) != 0 {
+			return repository.ErrInvalidAttribute
+		}
+	default:
+		return repository.ErrInvalidAttribute
+	}
+	return nil
+}
+
+// validAttributeValue checks a decoded JSON value against the attribute type.
+func validAttributeValue(a model.Attribute, v any) bool {
+	switch a.Type {
+	case model.AttributeString:
+		_, ok := v.(string)
+		return ok
+	case model.AttributeNumber:
+		_, ok := v.(float64)
+		return ok
+	case model.AttributeBoolean:
+		_, ok := v.(bool)
+		return ok
+	case model.AttributeEnum:
+		s, ok := v.(string)
+		return ok && slices.Contains(a.Options, s)
+	}
+	return false
+}
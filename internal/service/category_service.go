@@ -17,7 +17,13 @@ func NewCategoryService(repo *repository.CategoryRepository) *CategoryService {
 	return &CategoryService{repo: repo}
 }
 
+// Create stores the category; without a slug one is generated from the name.
 func (s *CategoryService) Create(ctx context.Context, c *model.Category) error {
+	slug, err := resolveSlug(c.Slug, c.Name, "category")
+	if err != nil {
+		return err
+	}
+	c.Slug = slug
 	return s.repo.Create(ctx, c)
 }
 
@@ -25,14 +31,95 @@ func (s *CategoryService) Get(ctx context.Context, id uuid.UUID) (model.Category
 	return s.repo.Get(ctx, id)
 }
 
+// GetBySlug returns the category by slug; moved is set when the slug is an old one.
+func (s *CategoryService) GetBySlug(ctx context.Context, slug string) (model.Category, bool, error) {
+	return s.repo.GetBySlug(ctx, slug)
+}
+
+// Update saves the category; an empty slug keeps the current one.
 func (s *CategoryService) Update(ctx context.Context, c *model.Category) error {
+	if c.Slug != "" {
+		slug, err := resolveSlug(c.Slug, "", "")
+		if err != nil {
+			return err
+		}
+		c.Slug = slug
+	}
 	return s.repo.Update(ctx, c)
 }
 
-func (s *CategoryService) Delete(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+func (s *CategoryService) Delete(ctx context.Context, id uuid.UUID, strategy model.CategoryDeleteStrategy) error {
+	return s.repo.Delete(ctx, id, strategy)
+}
+
+func (s *CategoryService) List(ctx context.Context, p repository.PageRequest) ([]model.Category, repository.Page, error) {
+	return s.repo.List(ctx, p)
+}
+
+// Tree returns the whole category hierarchy as a forest of root nodes.
+func (s *CategoryService) Tree(ctx context.Context, activeOnly bool) ([]*model.CategoryNode, error) {
+	list, err := s.repo.Tree(ctx, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	return buildCategoryTree(list), nil
+}
+
+// Subtree returns the category with all its descendants.
+func (s *CategoryService) Subtree(ctx context.Context, id uuid.UUID, activeOnly bool) (*model.CategoryNode, error) {
+	if _, err := s.repo.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	list, err := s.repo.Subtree(ctx, id, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	roots := buildCategoryTree(list)
+	if len(roots) == 0 {
+		// The category itself is inactive and filtered out.
+		return nil, repository.ErrNotFound
+	}
+	return roots[0], nil
+}
+
+// Ancestors returns the path from the root down to the category's parent.
+// With includeSelf the category itself closes the path, which is what breadcrumbs need.
+func (s *CategoryService) Ancestors(ctx context.Context, id uuid.UUID, includeSelf bool) ([]model.Category, error) {
+	chain, err := s.repo.Ancestors(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !includeSelf {
+		chain = chain[:len(chain)-1]
+	}
+	return chain, nil
+}
+
+// buildCategoryTree links a depth-ordered flat list into nodes. Categories whose
+// parent is absent from the list become roots, so input order is preserved for siblings.
+func buildCategoryTree(list []model.Category) []*model.CategoryNode {
+	nodes := make(map[uuid.UUID]*model.CategoryNode, len(list))
+	roots := make([]*model.CategoryNode, 0)
+	for _, c := range list {
+		node := &model.CategoryNode{Category: c, Children: make([]*model.CategoryNode, 0)}
+		nodes[c.ID] = node
+		if c.ParentID != nil {
+			if parent, ok := nodes[*c.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// Move places the category under a new parent (nil for root) at the given sibling position.
+func (s *CategoryService) Move(ctx context.Context, id uuid.UUID, parentID *uuid.UUID, position *int) (model.Category, error) {
+	return s.repo.Move(ctx, id, parentID, position)
 }
 
-func (s *CategoryService) List(ctx context.Context, limit, offset int) ([]model.Category, error) {
-	return s.repo.List(ctx, limit, offset)
+// ReorderChildren sets the order of the parent's children; a nil parent reorders the root categories.
+func (s *CategoryService) ReorderChildren(ctx context.Context, parentID *uuid.UUID, ids []uuid.UUID) ([]model.Category, error) {
+	return s.repo.ReorderChildren(ctx, parentID, ids)
 }
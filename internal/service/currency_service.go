@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"store-service/internal/model"
+	"store-service/internal/repository"
+)
+
+type CurrencyService struct {
+	repo *repository.ExchangeRateRepository
+	base model.Currency
+}
+
+func NewCurrencyService(repo *repository.ExchangeRateRepository, base model.Currency) *CurrencyService {
+	return &CurrencyService{repo: repo, base: base}
+}
+
+// Base returns the currency used for new records and reports when none is given.
+func (s *CurrencyService) Base() model.Currency {
+	return s.base
+}
+
+func (s *CurrencyService) CreateRate(ctx context.Context, rate *model.ExchangeRate) error {
+	if !rate.Base.Valid() || !rate.Quote.Valid() {
+		return repository.ErrInvalidCurrency
+	}
+	if rate.Base == rate.Quote || !rate.Rate.IsPositive() {
+		return repository.ErrInvalidRate
+	}
+	rate.EffectiveAt = rate.EffectiveAt.UTC()
+	return s.repo.Create(ctx, rate)
+}
+
+func (s *CurrencyService) ListRates(ctx context.Context, base, quote model.Currency) ([]model.ExchangeRate, error) {
+	if (base != "" && !base.Valid()) || (quote != "" && !quote.Valid()) {
+		return nil, repository.ErrInvalidCurrency
+	}
+	return s.repo.List(ctx, base, quote)
+}
+
+func (s *CurrencyService) DeleteRate(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// ConvertProducts converts product prices to the target currency at the current rates,
+// rounding to the target minor unit. An empty target leaves prices as stored.
+func (s *CurrencyService) ConvertProducts(ctx context.Context, target model.Currency, products ...*model.Product) error {
+	if target == "" {
+		return nil
+	}
+	from := make([]model.Currency, 0, len(products))
+	for _, p := range products {
+		from = append(from, p.Currency)
+	}
+	rates, err := s.rates(ctx, from, target)
+	if err != nil {
+		return err
+	}
+	for _, p := range products {
+		p.Price = target.Round(p.Price.Mul(rates[p.Currency]))
+		p.Currency = target
+	}
+	return nil
+}
+
+// ConvertOrders converts order totals and items to the target currency at the current rates.
+func (s *CurrencyService) ConvertOrders(ctx context.Context, target model.Currency, orders ...*model.Order) error {
+	if target == "" {
+		return nil
+	}
+	from := make([]model.Currency, 0, len(orders))
+	for _, o := range orders {
+		from = append(from, o.Currency)
+	}
+	rates, err := s.rates(ctx, from, target)
+	if err != nil {
+		return err
+	}
+	for _, o := range orders {
+		rate := rates[o.Currency]
+		total := decimal.Zero
+		for i := range o.Items {
+			it := &o.Items[i]
+			it.UnitPrice = target.Round(it.UnitPrice.Mul(rate))
+			it.SubTotal = target.Round(it.SubTotal.Mul(rate))
+			total = total.Add(it.SubTotal)
+		}
+		// The total is the sum of rounded lines so that it matches the displayed items.
+		if len(o.Items) == 0 {
+			total = target.Round(o.TotalPrice.Mul(rate))
+		}
+		o.TotalPrice = total
+		o.Currency = target
+	}
+	return nil
+}
+
+// rates returns conversion rates to the target for every distinct source currency.
+func (s *CurrencyService) rates(ctx context.Context, from []model.Currency, target model.Currency) (map[model.Currency]decimal.Decimal, error) {
+	if !target.Valid() {
+		return nil, repository.ErrInvalidCurrency
+	}
+	result := map[model.Currency]decimal.Decimal{target: decimal.NewFromInt(1)}
+	var missing []model.Currency
+	for _, c := range from {
+		if _, ok := result[c]; !ok {
+			result[c] = decimal.Zero
+			missing = append(missing, c)
+		}
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	found, err := s.repo.Rates(ctx, missing, target, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range missing {
+		rate, ok := found[c]
+		if !ok {
+			return nil, repository.ErrRateNotFound
+		}
+		result[c] = rate
+	}
+	return result, nil
+}
@@ -33,6 +33,10 @@ func (s *CustomerService) Delete(ctx context.Context, id uuid.UUID) error {
 	return s.repo.Delete(ctx, id)
 }
 
-func (s *CustomerService) List(ctx context.Context, limit, offset int) ([]model.Customer, error) {
-	return s.repo.List(ctx, limit, offset)
+func (s *CustomerService) List(ctx context.Context, p repository.PageRequest) ([]model.Customer, repository.Page, error) {
+	return s.repo.List(ctx, p)
+}
+
+func (s *CustomerService) Export(ctx context.Context, fn func([]model.Customer) error) error {
+	return s.repo.Export(ctx, fn)
 }
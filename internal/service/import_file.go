@@ -0,0 +1,306 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"store-service/internal/model"
+	"store-service/internal/repository"
+)
+
+// importTable is a parsed import file: the header row and the data rows with their line numbers.
+type importTable struct {
+	header []string
+	rows   []importRecord
+}
+
+type importRecord struct {
+	line  int
+	cells []string
+}
+
+// detectImportFormat picks the format by file extension, falling back to the zip signature of XLSX.
+func detectImportFormat(filename string, data []byte) model.ImportFormat {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".xlsx":
+		return model.ImportXLSX
+	case ".csv":
+		return model.ImportCSV
+	}
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return model.ImportXLSX
+	}
+	return model.ImportCSV
+}
+
+func readImportFile(format model.ImportFormat, data []byte) (importTable, error) {
+	var (
+		records []importRecord
+		err     error
+	)
+	if format == model.ImportXLSX {
+		records, err = readXLSX(data)
+	} else {
+		records, err = readCSV(data)
+	}
+	if err != nil {
+		return importTable{}, fmt.Errorf("%w: %v", repository.ErrInvalidImportFile, err)
+	}
+
+	var t importTable
+	for _, rec := range records {
+		if isBlankRecord(rec.cells) {
+			continue
+		}
+		if t.header == nil {
+			t.header = rec.cells
+			continue
+		}
+		t.rows = append(t.rows, rec)
+	}
+	if t.header == nil {
+		return t, fmt.Errorf("%w: header row is missing", repository.ErrInvalidImportFile)
+	}
+	return t, nil
+}
+
+// readCSV accepts comma or semicolon separated files; the separator is taken from the header line.
+func readCSV(data []byte) ([]importRecord, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		r.Comma = ';'
+	}
+
+	var records []importRecord
+	for {
+		cells, err := r.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+		records = append(records, importRecord{line: line, cells: cells})
+	}
+}
+
+func isBlankRecord(cells []string) bool {
+	for _, c := range cells {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// XLSX is read without a third-party library: only the first worksheet and cell values
+// (shared strings, inline strings, numbers and booleans) are needed for the import.
+
+const (
+	// xlsxMaxColumns is the last column Excel supports (XFD).
+	xlsxMaxColumns = 16384
+	// xlsxMaxCells caps the cells held for the whole sheet, a few far-right cell references
+	// on many rows would otherwise allocate rows of xlsxMaxColumns each.
+	xlsxMaxCells = 4 << 20
+	// xlsxMaxPartSize caps the unpacked size of a single part of the archive.
+	xlsxMaxPartSize = 128 << 20
+)
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+// xlsxText is a plain (<t>) or rich text (<r><t>) value.
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readXLSX(data []byte) ([]importRecord, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.New("not an xlsx file")
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeZipXML(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	sheetFile, ok := files[firstSheetPath(files)]
+	if !ok {
+		return nil, errors.New("xlsx file has no worksheets")
+	}
+	var sheet xlsxSheet
+	if err := decodeZipXML(sheetFile, &sheet); err != nil {
+		return nil, err
+	}
+
+	records := make([]importRecord, 0, len(sheet.Rows))
+	total := 0
+	for i, row := range sheet.Rows {
+		line := row.R
+		if line == 0 {
+			line = i + 1
+		}
+		if len(row.Cells) > xlsxMaxColumns {
+			return nil, fmt.Errorf("row %d has more than %d cells", line, xlsxMaxColumns)
+		}
+		var cells []string
+		for j, c := range row.Cells {
+			col := j
+			if c.Ref != "" {
+				if col, err = xlsxColumn(c.Ref); err != nil {
+					return nil, err
+				}
+			}
+			var v string
+			switch c.Type {
+			case "s":
+				idx, err := strconv.Atoi(c.Value)
+				if err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, fmt.Errorf("cell %s refers to a missing shared string", c.Ref)
+				}
+				v = shared.Items[idx].String()
+			case "inlineStr":
+				v = c.Inline.String()
+			case "b":
+				v = strconv.FormatBool(c.Value == "1")
+			default:
+				v = c.Value
+			}
+			if col >= len(cells) && total+col+1-len(cells) > xlsxMaxCells {
+				return nil, fmt.Errorf("sheet has more than %d cells", xlsxMaxCells)
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+			cells[col] = v
+		}
+		total += len(cells)
+		records = append(records, importRecord{line: line, cells: cells})
+	}
+	return records, nil
+}
+
+// firstSheetPath resolves the first sheet of the workbook through its relationships.
+func firstSheetPath(files map[string]*zip.File) string {
+	const fallback = "xl/worksheets/sheet1.xml"
+	var wb xlsxWorkbook
+	var rels xlsxRelationships
+	wbFile, ok1 := files["xl/workbook.xml"]
+	relsFile, ok2 := files["xl/_rels/workbook.xml.rels"]
+	if !ok1 || !ok2 || decodeZipXML(wbFile, &wb) != nil || decodeZipXML(relsFile, &rels) != nil || len(wb.Sheets) == 0 {
+		return fallback
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != wb.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return path.Join("xl", rel.Target)
+	}
+	return fallback
+}
+
+// decodeZipXML decodes a part of the archive; parts that unpack to more than xlsxMaxPartSize
+// are rejected whatever size the archive declares for them.
+func decodeZipXML(f *zip.File, v any) error {
+	if f.UncompressedSize64 > xlsxMaxPartSize {
+		return fmt.Errorf("%s is larger than %d bytes unpacked", f.Name, xlsxMaxPartSize)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	lr := io.LimitReader(rc, xlsxMaxPartSize+1).(*io.LimitedReader)
+	err = xml.NewDecoder(lr).Decode(v)
+	if lr.N == 0 {
+		return fmt.Errorf("%s is larger than %d bytes unpacked", f.Name, xlsxMaxPartSize)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", f.Name, err)
+	}
+	return nil
+}
+
+// xlsxColumn converts the letters of a cell reference ("BC12") to a zero-based column index;
+// columns past XFD are rejected.
+func xlsxColumn(ref string) (int, error) {
+	col := 0
+	n := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+		if col > xlsxMaxColumns {
+			return 0, fmt.Errorf("cell reference %q is past the last column XFD", ref)
+		}
+		n++
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("invalid cell reference %q", ref)
+	}
+	return col - 1, nil
+}
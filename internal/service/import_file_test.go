@@ -0,0 +1,335 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"store-service/internal/model"
+	"store-service/internal/repository"
+)
+
+// buildXLSX packs the given parts into an in-memory xlsx archive.
+func buildXLSX(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func sheetXML(rows string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + rows + `</sheetData></worksheet>`
+}
+
+const sharedStringsXML = `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>name</t></si>
+<si><t>price</t></si>
+<si><r><t>Чай </t></r><r><t>зеленый</t></r></si>
+</sst>`
+
+func TestXLSXColumn(t *testing.T) {
+	tests := []struct {
+		ref     string
+		want    int
+		wantErr bool
+	}{
+		{ref: "A1", want: 0},
+		{ref: "Z7", want: 25},
+		{ref: "AA1", want: 26},
+		{ref: "BC12", want: 54},
+		{ref: "XFD1", want: 16383},
+		{ref: "XFE1", wantErr: true},
+		{ref: "ZZZZZZZZ1", wantErr: true},
+		{ref: "12", wantErr: true},
+		{ref: "a1", wantErr: true},
+		{ref: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := xlsxColumn(tt.ref)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("xlsxColumn(%q) = %d, want error", tt.ref, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("xlsxColumn(%q): %v", tt.ref, err)
+			}
+			if got != tt.want {
+				t.Fatalf("xlsxColumn(%q) = %d, want %d", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	tests := []struct {
+		name    string
+		parts   map[string]string
+		want    []importRecord
+		wantErr string
+	}{
+		{
+			name: "shared strings",
+			parts: map[string]string{
+				"xl/sharedStrings.xml":     sharedStringsXML,
+				"xl/worksheets/sheet1.xml": sheetXML(`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row><row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2"><v>99.5</v></c></row>`),
+			},
+			want: []importRecord{
+				{line: 1, cells: []string{"name", "price"}},
+				{line: 2, cells: []string{"Чай зеленый", "99.5"}},
+			},
+		},
+		{
+			name: "inline strings and booleans",
+			parts: map[string]string{
+				"xl/worksheets/sheet1.xml": sheetXML(`<row r="3"><c r="A3" t="inlineStr"><is><t>Кофе</t></is></c><c r="B3" t="b"><v>1</v></c><c r="C3" t="b"><v>0</v></c></row>`),
+			},
+			want: []importRecord{
+				{line: 3, cells: []string{"Кофе", "true", "false"}},
+			},
+		},
+		{
+			name: "missing cells are left empty",
+			parts: map[string]string{
+				"xl/worksheets/sheet1.xml": sheetXML(`<row r="1"><c r="B1"><v>1</v></c><c r="D1"><v>2</v></c></row><row r="2"></row>`),
+			},
+			want: []importRecord{
+				{line: 1, cells: []string{"", "1", "", "2"}},
+				{line: 2},
+			},
+		},
+		{
+			name: "cells and rows without references",
+			parts: map[string]string{
+				"xl/worksheets/sheet1.xml": sheetXML(`<row><c><v>a</v></c><c/><c><v>c</v></c></row>`),
+			},
+			want: []importRecord{
+				{line: 1, cells: []string{"a", "", "c"}},
+			},
+		},
+		{
+			name: "first sheet through workbook relationships",
+			parts: map[string]string{
+				"xl/workbook.xml":            `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Товары" sheetId="1" r:id="rId7"/></sheets></workbook>`,
+				"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId7" Target="worksheets/products.xml"/></Relationships>`,
+				"xl/worksheets/products.xml": sheetXML(`<row r="1"><c r="A1" t="inlineStr"><is><t>name</t></is></c></row>`),
+			},
+			want: []importRecord{
+				{line: 1, cells: []string{"name"}},
+			},
+		},
+		{
+			name: "missing shared string",
+			parts: map[string]string{
+				"xl/sharedStrings.xml":     sharedStringsXML,
+				"xl/worksheets/sheet1.xml": sheetXML(`<row r="1"><c r="A1" t="s"><v>3</v></c></row>`),
+			},
+			wantErr: "missing shared string",
+		},
+		{
+			name: "column past XFD",
+			parts: map[string]string{
+				"xl/worksheets/sheet1.xml": sheetXML(`<row r="1"><c r="ZZZZZZZZ1"><v>1</v></c></row>`),
+			},
+			wantErr: "past the last column",
+		},
+		{
+			name:    "no worksheets",
+			parts:   map[string]string{"xl/styles.xml": `<styleSheet/>`},
+			wantErr: "no worksheets",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readXLSX(buildXLSX(t, tt.parts))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("readXLSX error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readXLSX: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("readXLSX = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadXLSXTooManyCells(t *testing.T) {
+	var rows strings.Builder
+	for i := 1; i*xlsxMaxColumns <= xlsxMaxCells+xlsxMaxColumns; i++ {
+		rows.WriteString(`<row><c r="XFD1"><v>1</v></c></row>`)
+	}
+	_, err := readXLSX(buildXLSX(t, map[string]string{"xl/worksheets/sheet1.xml": sheetXML(rows.String())}))
+	if err == nil || !strings.Contains(err.Error(), "cells") {
+		t.Fatalf("readXLSX error = %v, want too many cells", err)
+	}
+}
+
+func TestReadXLSXPartTooLarge(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	body := []byte(sheetXML(""))
+	w, err := zw.CreateRaw(&zip.FileHeader{
+		Name:               "xl/worksheets/sheet1.xml",
+		Method:             zip.Store,
+		CompressedSize64:   uint64(len(body)),
+		UncompressedSize64: xlsxMaxPartSize + 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(body); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_, err = readXLSX(buf.Bytes())
+	if err == nil || !strings.Contains(err.Error(), "unpacked") {
+		t.Fatalf("readXLSX error = %v, want part too large", err)
+	}
+}
+
+func TestReadXLSXNotAnArchive(t *testing.T) {
+	if _, err := readXLSX([]byte("name,price\n")); err == nil {
+		t.Fatal("readXLSX accepted a csv file")
+	}
+}
+
+func TestReadImportFile(t *testing.T) {
+	xlsx := buildXLSX(t, map[string]string{
+		"xl/worksheets/sheet1.xml": sheetXML(`<row r="1"/><row r="2"><c r="A2" t="inlineStr"><is><t>name</t></is></c><c r="B2" t="inlineStr"><is><t>price</t></is></c></row><row r="3"><c r="A3" t="inlineStr"><is><t>Чай</t></is></c><c r="B3"><v>10</v></c></row>`),
+	})
+	tests := []struct {
+		name       string
+		format     model.ImportFormat
+		data       []byte
+		wantHeader []string
+		wantRows   []importRecord
+		wantErr    bool
+	}{
+		{
+			name:       "xlsx skips blank rows before the header",
+			format:     model.ImportXLSX,
+			data:       xlsx,
+			wantHeader: []string{"name", "price"},
+			wantRows:   []importRecord{{line: 3, cells: []string{"Чай", "10"}}},
+		},
+		{
+			name:       "csv with semicolons",
+			format:     model.ImportCSV,
+			data:       []byte("\xef\xbb\xbfname;price\nЧай;10\n;\n"),
+			wantHeader: []string{"name", "price"},
+			wantRows:   []importRecord{{line: 2, cells: []string{"Чай", "10"}}},
+		},
+		{
+			name:    "empty file",
+			format:  model.ImportCSV,
+			data:    []byte("\n\n"),
+			wantErr: true,
+		},
+		{
+			name:    "broken xlsx",
+			format:  model.ImportXLSX,
+			data:    []byte("PK\x03\x04broken"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readImportFile(tt.format, tt.data)
+			if tt.wantErr {
+				if !errors.Is(err, repository.ErrInvalidImportFile) {
+					t.Fatalf("readImportFile error = %v, want ErrInvalidImportFile", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readImportFile: %v", err)
+			}
+			if !reflect.DeepEqual(got.header, tt.wantHeader) {
+				t.Fatalf("header = %q, want %q", got.header, tt.wantHeader)
+			}
+			if !reflect.DeepEqual(got.rows, tt.wantRows) {
+				t.Fatalf("rows = %#v, want %#v", got.rows, tt.wantRows)
+			}
+		})
+	}
+}
+
+func TestImportColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  []string
+		want    map[string]int
+		wantErr string
+	}{
+		{
+			name:   "known columns in any case and order",
+			header: []string{" Price ", "NAME", "sku", "quantity"},
+			want:   map[string]int{"price": 0, "name": 1, "sku": 2, "quantity": 3},
+		},
+		{
+			name:    "unknown column",
+			header:  []string{"name", "price", "colour"},
+			wantErr: "unknown column",
+		},
+		{
+			name:    "duplicate column",
+			header:  []string{"name", "price", "Name"},
+			wantErr: "duplicate column",
+		},
+		{
+			name:    "duplicate attribute column",
+			header:  []string{"name", "price", "attr.color", "ATTR.color"},
+			wantErr: "duplicate column",
+		},
+		{
+			name:    "required column missing",
+			header:  []string{"name", "sku"},
+			wantErr: `column "price" is required`,
+		},
+	}
+	s := &ImportService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.columns(context.Background(), tt.header)
+			if tt.wantErr != "" {
+				if !errors.Is(err, repository.ErrInvalidImportFile) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("columns error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("columns: %v", err)
+			}
+			if !reflect.DeepEqual(got.index, tt.want) {
+				t.Fatalf("columns = %v, want %v", got.index, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"store-service/internal/model"
+	"store-service/internal/repository"
+)
+
+const (
+	// importProgressEvery is how often (in rows) a running job reports progress.
+	importProgressEvery = 100
+	// importStaleAfter is how long a running job may go without progress before another run picks it up.
+	importStaleAfter = 10 * time.Minute
+)
+
+// importColumnNames lists the supported columns besides attribute columns named attr.<code>.
+var importColumnNames = []string{"sku", "external_id", "name", "price", "currency", "quantity", "categories"}
+
+type ImportService struct {
+	repo         *repository.ImportRepository
+	attributes   *repository.AttributeRepository
+	baseCurrency model.Currency
+	syncMaxRows  int
+	maxFileSize  int64
+}
+
+func NewImportService(repo *repository.ImportRepository, attributes *repository.AttributeRepository, baseCurrency model.Currency,
+	syncMaxRows int, maxFileSize int64) *ImportService {
+	return &ImportService{repo: repo, attributes: attributes, baseCurrency: baseCurrency, syncMaxRows: syncMaxRows, maxFileSize: maxFileSize}
+}
+
+// MaxFileSize is the largest accepted import file in bytes.
+func (s *ImportService) MaxFileSize() int64 {
+	return s.maxFileSize
+}
+
+// ImportProducts validates the file header and creates an import job. Files with up to
+// syncMaxRows rows are processed right away; larger ones are left pending for the import worker.
+func (s *ImportService) ImportProducts(ctx context.Context, filename string, data []byte, dryRun bool) (model.ImportJob, error) {
+	format := detectImportFormat(filename, data)
+	table, err := readImportFile(format, data)
+	if err != nil {
+		return model.ImportJob{}, err
+	}
+	if _, err := s.columns(ctx, table.header); err != nil {
+		return model.ImportJob{}, err
+	}
+
+	job := model.ImportJob{
+		Filename:  filename,
+		Format:    format,
+		DryRun:    dryRun,
+		Status:    model.ImportPending,
+		TotalRows: len(table.rows),
+	}
+	if len(table.rows) > s.syncMaxRows {
+		return job, s.repo.CreateJob(ctx, &job, data)
+	}
+
+	job.Status = model.ImportRunning
+	if err := s.repo.CreateJob(ctx, &job, nil); err != nil {
+		return job, err
+	}
+	// The job is finished even if the client goes away.
+	return job, s.run(context.WithoutCancel(ctx), &job, table)
+}
+
+func (s *ImportService) Get(ctx context.Context, id uuid.UUID) (model.ImportJob, error) {
+	return s.repo.GetJob(ctx, id)
+}
+
+// RunPending processes queued jobs one by one until none are left; it is run by the import worker.
+func (s *ImportService) RunPending(ctx context.Context) (int, error) {
+	done := 0
+	for ctx.Err() == nil {
+		job, payload, err := s.repo.ClaimJob(ctx, time.Now().UTC().Add(-importStaleAfter))
+		if err == repository.ErrNotFound {
+			return done, nil
+		}
+		if err != nil {
+			return done, err
+		}
+		table, err := readImportFile(job.Format, payload)
+		if err != nil {
+			job.Status = model.ImportFailed
+			job.Error = err.Error()
+			if err := s.repo.FinishJob(ctx, &job); err != nil {
+				return done, err
+			}
+			continue
+		}
+		if err := s.run(ctx, &job, table); err != nil {
+			return done, err
+		}
+		done++
+	}
+	return done, ctx.Err()
+}
+
+// importColumns holds cell indexes of known columns and the attribute columns in header order.
+type importColumns struct {
+	index      map[string]int
+	attributes []importAttributeColumn
+}
+
+type importAttributeColumn struct {
+	index     int
+	attribute model.Attribute
+}
+
+func (c importColumns) cell(rec importRecord, name string) (string, bool) {
+	i, ok := c.index[name]
+	if !ok || i >= len(rec.cells) {
+		return "", ok
+	}
+	return strings.TrimSpace(rec.cells[i]), true
+}
+
+// columns validates the header: name and price are required, unknown columns and
+// attributes are rejected so that a typo does not silently skip data.
+func (s *ImportService) columns(ctx context.Context, header []string) (importColumns, error) {
+	cols := importColumns{index: map[string]int{}}
+	var attrCodes []string
+	attrIndex := map[string]int{}
+	for i, h := range header {
+		name := strings.ToLower(strings.TrimSpace(h))
+		if code, ok := strings.CutPrefix(name, "attr."); ok {
+			if _, dup := attrIndex[code]; dup {
+				return cols, fmt.Errorf("%w: duplicate column %q", repository.ErrInvalidImportFile, h)
+			}
+			attrIndex[code] = i
+			attrCodes = append(attrCodes, code)
+			continue
+		}
+		if !slices.Contains(importColumnNames, name) {
+			return cols, fmt.Errorf("%w: unknown column %q", repository.ErrInvalidImportFile, h)
+		}
+		if _, dup := cols.index[name]; dup {
+			return cols, fmt.Errorf("%w: duplicate column %q", repository.ErrInvalidImportFile, h)
+		}
+		cols.index[name] = i
+	}
+	for _, required := range []string{"name", "price"} {
+		if _, ok := cols.index[required]; !ok {
+			return cols, fmt.Errorf("%w: column %q is required", repository.ErrInvalidImportFile, required)
+		}
+	}
+
+	if len(attrCodes) == 0 {
+		return cols, nil
+	}
+	defs, err := s.attributes.ListByCodes(ctx, attrCodes)
+	if err != nil {
+		return cols, err
+	}
+	byCode := make(map[string]model.Attribute, len(defs))
+	for _, d := range defs {
+		byCode[d.Code] = d
+	}
+	for _, code := range attrCodes {
+		d, ok := byCode[code]
+		if !ok {
+			return cols, fmt.Errorf("%w: unknown attribute %q", repository.ErrInvalidImportFile, code)
+		}
+		cols.attributes = append(cols.attributes, importAttributeColumn{index: attrIndex[code], attribute: d})
+	}
+	return cols, nil
+}
+
+// run processes all rows of the job and stores the report. Rows are applied one by one, so a
+// failure in the middle leaves the earlier rows applied; the job is then marked as failed.
+func (s *ImportService) run(ctx context.Context, job *model.ImportJob, table importTable) error {
+	cols, err := s.columns(ctx, table.header)
+	if err != nil {
+		job.Status = model.ImportFailed
+		job.Error = err.Error()
+		return s.repo.FinishJob(ctx, job)
+	}
+	slugs, err := s.categorySlugs(ctx, cols, table.rows)
+	if err != nil {
+		return s.fail(ctx, job, err)
+	}
+
+	job.TotalRows = len(table.rows)
+	job.Report = make([]model.ImportRowResult, 0, len(table.rows))
+	// In a dry run rows repeating a key of an earlier row would update the product it creates.
+	seen := map[string]bool{}
+	for i, rec := range table.rows {
+		res := model.ImportRowResult{Row: rec.line}
+		p, errs := parseImportRow(cols, rec, slugs)
+		switch {
+		case len(errs) > 0:
+			res.Errors = errs
+		case job.DryRun:
+			res.Action = model.ImportActionCreate
+			if seen[importKey(p)] {
+				res.Action = model.ImportActionUpdate
+			} else if id, err := s.repo.FindProduct(ctx, p.SKU, p.ExternalID); err == nil {
+				res.Action = model.ImportActionUpdate
+				res.ProductID = &id
+			} else if err != repository.ErrNotFound {
+				return s.fail(ctx, job, err)
+			}
+			if key := importKey(p); key != "" {
+				seen[key] = true
+			}
+		default:
+			id, created, err := s.repo.UpsertProduct(ctx, job.ID, p, s.baseCurrency)
+			if err == repository.ErrDuplicateProductKey {
+				res.Errors = []string{err.Error()}
+				break
+			}
+			if err == repository.ErrNotEnoughStock {
+				res.Errors = []string{"quantity change exceeds the stock of the default warehouse"}
+				break
+			}
+			if err != nil {
+				return s.fail(ctx, job, err)
+			}
+			res.ProductID = &id
+			res.Action = model.ImportActionUpdate
+			if created {
+				res.Action = model.ImportActionCreate
+			}
+		}
+
+		if len(res.Errors) > 0 {
+			res.Action = model.ImportActionError
+			job.Failed++
+		} else if res.Action == model.ImportActionCreate {
+			job.Created++
+		} else {
+			job.Updated++
+		}
+		job.Report = append(job.Report, res)
+		job.ProcessedRows = i + 1
+		if job.ProcessedRows%importProgressEvery == 0 {
+			if err := s.repo.SaveProgress(ctx, job); err != nil {
+				return err
+			}
+		}
+	}
+
+	job.Status = model.ImportCompleted
+	return s.repo.FinishJob(ctx, job)
+}
+
+// fail stores the processed part of the report and marks the job as failed; err is returned to the caller.
+func (s *ImportService) fail(ctx context.Context, job *model.ImportJob, err error) error {
+	job.Status = model.ImportFailed
+	job.Error = "internal error while processing the file"
+	if ferr := s.repo.FinishJob(ctx, job); ferr != nil {
+		return ferr
+	}
+	return err
+}
+
+func (s *ImportService) categorySlugs(ctx context.Context, cols importColumns, rows []importRecord) (map[string]uuid.UUID, error) {
+	if _, ok := cols.index["categories"]; !ok {
+		return nil, nil
+	}
+	var slugs []string
+	for _, rec := range rows {
+		v, _ := cols.cell(rec, "categories")
+		slugs = append(slugs, splitImportList(v)...)
+	}
+	if len(slugs) == 0 {
+		return nil, nil
+	}
+	return s.repo.CategoryIDsBySlugs(ctx, slugs)
+}
+
+// parseImportRow converts a row into a product import; an empty cell keeps the current value.
+func parseImportRow(cols importColumns, rec importRecord, slugs map[string]uuid.UUID) (repository.ProductImport, []string) {
+	var p repository.ProductImport
+	var errs []string
+
+	if v, _ := cols.cell(rec, "sku"); v != "" {
+		p.SKU = &v
+	}
+	if v, _ := cols.cell(rec, "external_id"); v != "" {
+		p.ExternalID = &v
+	}
+	if p.Name, _ = cols.cell(rec, "name"); p.Name == "" {
+		errs = append(errs, "name is required")
+	}
+	p.Slug, _ = resolveSlug("", p.Name, "product")
+	if v, _ := cols.cell(rec, "price"); v == "" {
+		errs = append(errs, "price is required")
+	} else if d, err := decimal.NewFromString(strings.ReplaceAll(v, ",", ".")); err != nil || d.IsNegative() {
+		errs = append(errs, "price must be a non-negative number")
+	} else {
+		p.Price = d
+	}
+	if v, _ := cols.cell(rec, "currency"); v != "" {
+		p.Currency = model.Currency(strings.ToUpper(v))
+		if !p.Currency.Valid() {
+			errs = append(errs, repository.ErrInvalidCurrency.Error())
+		}
+	}
+	if v, _ := cols.cell(rec, "quantity"); v != "" {
+		if n, err := strconv.Atoi(v); err != nil || n < 0 {
+			errs = append(errs, "quantity must be a non-negative integer")
+		} else {
+			p.Quantity = &n
+		}
+	}
+	if v, _ := cols.cell(rec, "categories"); v != "" {
+		p.CategoryIDs = []uuid.UUID{}
+		for _, slug := range splitImportList(v) {
+			id, ok := slugs[slug]
+			if !ok {
+				errs = append(errs, fmt.Sprintf("unknown category %q", slug))
+				continue
+			}
+			p.CategoryIDs = append(p.CategoryIDs, id)
+		}
+	}
+	for _, col := range cols.attributes {
+		a := col.attribute
+		if col.index >= len(rec.cells) || strings.TrimSpace(rec.cells[col.index]) == "" {
+			continue
+		}
+		v, ok := parseAttributeCell(a, strings.TrimSpace(rec.cells[col.index]))
+		if !ok {
+			errs = append(errs, fmt.Sprintf("invalid value for attribute %q", a.Code))
+			continue
+		}
+		if p.Attributes == nil {
+			p.Attributes = map[string]any{}
+		}
+		p.Attributes[a.Code] = v
+	}
+	return p, errs
+}
+
+// parseAttributeCell converts the cell text to the JSON value stored for the attribute type.
+func parseAttributeCell(a model.Attribute, s string) (any, bool) {
+	var v any = s
+	switch a.Type {
+	case model.AttributeNumber:
+		f, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", "."), 64)
+		if err != nil {
+			return nil, false
+		}
+		v = f
+	case model.AttributeBoolean:
+		b, err := strconv.ParseBool(strings.ToLower(s))
+		if err != nil {
+			return nil, false
+		}
+		v = b
+	}
+	return v, validAttributeValue(a, v)
+}
+
+// splitImportList splits a list cell such as "phones|accessories".
+func splitImportList(s string) []string {
+	var result []string
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '|' || r == ',' }) {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+// importKey identifies the product a row refers to; rows without keys always create products.
+func importKey(p repository.ProductImport) string {
+	switch {
+	case p.ExternalID != nil:
+		return "external_id:" + *p.ExternalID
+	case p.SKU != nil:
+		return "sku:" + *p.SKU
+	}
+	return ""
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"store-service/internal/model"
+	"store-service/internal/repository"
+	"store-service/internal/storage"
+)
+
+type MediaService struct {
+	repo           *repository.MediaRepository
+	products       *repository.ProductRepository
+	store          storage.Storage
+	thumbnailSizes []int
+	maxUploadSize  int64
+	maxPixels      int64
+}
+
+func NewMediaService(repo *repository.MediaRepository, products *repository.ProductRepository, store storage.Storage, thumbnailSizes []int, maxUploadSize, maxPixels int64) *MediaService {
+	return &MediaService{
+		repo:           repo,
+		products:       products,
+		store:          store,
+		thumbnailSizes: thumbnailSizes,
+		maxUploadSize:  maxUploadSize,
+		maxPixels:      maxPixels,
+	}
+}
+
+// MaxUploadSize is the largest accepted file in bytes.
+func (s *MediaService) MaxUploadSize() int64 {
+	return s.maxUploadSize
+}
+
+// Upload stores the image with its thumbnails and appends it to the product gallery.
+// Dimensions are checked from the image header before the bitmap is decoded.
+// Stored files are removed again if the row cannot be saved.
+func (s *MediaService) Upload(ctx context.Context, productID uuid.UUID, data []byte, isPrimary bool) (model.ProductMedia, error) {
+	if _, err := s.products.Get(ctx, productID); err != nil {
+		return model.ProductMedia{}, err
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := imageFormats[contentType]
+	if !ok {
+		return model.ProductMedia{}, repository.ErrUnsupportedMedia
+	}
+	cfg, err := decodeImageConfig(data, contentType)
+	if err != nil {
+		return model.ProductMedia{}, repository.ErrUnsupportedMedia
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > s.maxPixels {
+		return model.ProductMedia{}, repository.ErrImageTooLarge
+	}
+	img, err := decodeImage(data, contentType)
+	if err != nil {
+		return model.ProductMedia{}, repository.ErrUnsupportedMedia
+	}
+
+	m := model.ProductMedia{
+		ID:          uuid.New(),
+		ProductID:   productID,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+		IsPrimary:   isPrimary,
+	}
+	prefix := fmt.Sprintf("products/%s/%s/", productID, m.ID)
+	m.Key = prefix + "original." + ext
+
+	var stored []string
+	cleanup := func() {
+		for _, key := range stored {
+			_ = s.store.Delete(context.WithoutCancel(ctx), key)
+		}
+	}
+
+	if err := s.store.Put(ctx, m.Key, bytes.NewReader(data), contentType); err != nil {
+		return model.ProductMedia{}, err
+	}
+	stored = append(stored, m.Key)
+
+	thumbType, thumbExt := thumbnailFormat(contentType)
+	for _, size := range s.thumbnailSizes {
+		thumb, err := encodeImage(makeThumbnail(img, size), thumbType)
+		if err != nil {
+			cleanup()
+			return model.ProductMedia{}, err
+		}
+		key := prefix + strconv.Itoa(size) + "." + thumbExt
+		if err := s.store.Put(ctx, key, bytes.NewReader(thumb), thumbType); err != nil {
+			cleanup()
+			return model.ProductMedia{}, err
+		}
+		stored = append(stored, key)
+		m.Thumbnails = append(m.Thumbnails, model.MediaThumbnail{Size: size, Key: key})
+	}
+
+	if err := s.repo.Create(ctx, &m); err != nil {
+		cleanup()
+		return model.ProductMedia{}, err
+	}
+	return s.withURLs(m), nil
+}
+
+func (s *MediaService) List(ctx context.Context, productID uuid.UUID) ([]model.ProductMedia, error) {
+	list, err := s.repo.List(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	fillMediaURLs(s.store, list)
+	return list, nil
+}
+
+// Reorder sets the gallery order and returns the reordered gallery.
+func (s *MediaService) Reorder(ctx context.Context, productID uuid.UUID, ids []uuid.UUID) ([]model.ProductMedia, error) {
+	if err := s.repo.Reorder(ctx, productID, ids); err != nil {
+		return nil, err
+	}
+	return s.List(ctx, productID)
+}
+
+func (s *MediaService) SetPrimary(ctx context.Context, productID, id uuid.UUID) (model.ProductMedia, error) {
+	if err := s.repo.SetPrimary(ctx, productID, id); err != nil {
+		return model.ProductMedia{}, err
+	}
+	m, err := s.repo.Get(ctx, productID, id)
+	if err != nil {
+		return m, err
+	}
+	return s.withURLs(m), nil
+}
+
+// Delete removes the media row first, then its files; a failed file removal leaves
+// an orphaned file rather than a broken gallery entry.
+func (s *MediaService) Delete(ctx context.Context, productID, id uuid.UUID) error {
+	m, err := s.repo.Delete(ctx, productID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Delete(ctx, m.Key); err != nil {
+		return err
+	}
+	for _, t := range m.Thumbnails {
+		if err := s.store.Delete(ctx, t.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MediaService) withURLs(m model.ProductMedia) model.ProductMedia {
+	list := []model.ProductMedia{m}
+	fillMediaURLs(s.store, list)
+	return list[0]
+}
+
+// mediaLoader attaches galleries to products returned by other services.
+type mediaLoader struct {
+	repo  *repository.MediaRepository
+	store storage.Storage
+}
+
+func (l mediaLoader) attach(ctx context.Context, products ...*model.Product) error {
+	ids := make([]uuid.UUID, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	byProduct, err := l.repo.ListForProducts(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, p := range products {
+		p.Media = byProduct[p.ID]
+		fillMediaURLs(l.store, p.Media)
+	}
+	return nil
+}
+
+func fillMediaURLs(store storage.Storage, list []model.ProductMedia) {
+	for i := range list {
+		list[i].URL = store.URL(list[i].Key)
+		for j := range list[i].Thumbnails {
+			list[i].Thumbnails[j].URL = store.URL(list[i].Thumbnails[j].Key)
+		}
+	}
+}
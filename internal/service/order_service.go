@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -10,14 +11,28 @@ import (
 )
 
 type OrderService struct {
-	repo *repository.OrderRepository
+	repo           *repository.OrderRepository
+	reservations   *repository.ReservationRepository
+	baseCurrency   model.Currency
+	reservationTTL time.Duration
+	allocation     string
 }
 
-func NewOrderService(repo *repository.OrderRepository) *OrderService {
-	return &OrderService{repo: repo}
+func NewOrderService(repo *repository.OrderRepository, reservations *repository.ReservationRepository, baseCurrency model.Currency, reservationTTL time.Duration, allocation string) *OrderService {
+	return &OrderService{repo: repo, reservations: reservations, baseCurrency: baseCurrency, reservationTTL: reservationTTL, allocation: allocation}
 }
 
+// Create stores the order; items added later are priced in its currency, the base one by default.
 func (s *OrderService) Create(ctx context.Context, o *model.Order) error {
+	if o.Currency == "" {
+		o.Currency = s.baseCurrency
+	}
+	if !o.Currency.Valid() {
+		return repository.ErrInvalidCurrency
+	}
+	if !model.ValidCoordinates(o.ShippingLatitude, o.ShippingLongitude) {
+		return repository.ErrInvalidShippingPoint
+	}
 	return s.repo.Create(ctx, o)
 }
 
@@ -25,11 +40,18 @@ func (s *OrderService) Get(ctx context.Context, id uuid.UUID) (model.Order, erro
 	return s.repo.Get(ctx, id)
 }
 
-func (s *OrderService) List(ctx context.Context, limit, offset int) ([]model.Order, error) {
-	return s.repo.List(ctx, limit, offset)
+func (s *OrderService) List(ctx context.Context, p repository.PageRequest) ([]model.Order, repository.Page, error) {
+	return s.repo.List(ctx, p)
+}
+
+func (s *OrderService) Export(ctx context.Context, fn func([]model.Order) error) error {
+	return s.repo.Export(ctx, fn)
 }
 
 func (s *OrderService) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
+	if !model.ValidOrderStatus(status) {
+		return repository.ErrInvalidOrderStatus
+	}
 	return s.repo.UpdateStatus(ctx, id, status)
 }
 
@@ -37,6 +59,15 @@ func (s *OrderService) Delete(ctx context.Context, id uuid.UUID) error {
 	return s.repo.Delete(ctx, id)
 }
 
-func (s *OrderService) AddProductToOrder(ctx context.Context, orderID, productID uuid.UUID, qty int) (model.OrderItem, error) {
-	return s.repo.AddProductToOrder(ctx, orderID, productID, qty)
+// AddProductToOrder adds the product to the order and holds its stock for the reservation TTL in
+// the warehouse chosen by the allocation strategy.
+func (s *OrderService) AddProductToOrder(ctx context.Context, orderID, productID uuid.UUID, variantID *uuid.UUID, qty int) (model.OrderItem, error) {
+	return s.repo.AddProductToOrder(ctx, orderID, productID, variantID, qty, time.Now().Add(s.reservationTTL), s.allocation)
+}
+
+// ExpireReservations marks holds of unpaid orders past their TTL as expired; it is run by the
+// reservation sweeper. Expired holds already stop counting against stock, the sweep keeps the
+// active set small.
+func (s *OrderService) ExpireReservations(ctx context.Context) (int, error) {
+	return s.reservations.ExpireStale(ctx)
 }
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"store-service/internal/model"
+	"store-service/internal/repository"
+)
+
+type PriceService struct {
+	repo     *repository.PriceRepository
+	products *repository.ProductRepository
+}
+
+func NewPriceService(repo *repository.PriceRepository, products *repository.ProductRepository) *PriceService {
+	return &PriceService{repo: repo, products: products}
+}
+
+func (s *PriceService) History(ctx context.Context, productID uuid.UUID) ([]model.ProductPrice, error) {
+	return s.repo.History(ctx, productID)
+}
+
+// Schedule plans a price change; immediate changes go through the product update instead.
+// An empty currency keeps the current product currency.
+func (s *PriceService) Schedule(ctx context.Context, p *model.ProductPrice) error {
+	if !p.EffectiveFrom.After(time.Now()) {
+		return repository.ErrPriceNotInFuture
+	}
+	if p.Currency == "" {
+		product, err := s.products.Get(ctx, p.ProductID)
+		if err != nil {
+			return err
+		}
+		p.Currency = product.Currency
+	}
+	if !p.Currency.Valid() {
+		return repository.ErrInvalidCurrency
+	}
+	p.Price = p.Currency.Round(p.Price)
+	p.EffectiveFrom = p.EffectiveFrom.UTC()
+	return s.repo.Schedule(ctx, p)
+}
+
+func (s *PriceService) CancelScheduled(ctx context.Context, productID, id uuid.UUID) error {
+	return s.repo.CancelScheduled(ctx, productID, id)
+}
+
+// ApplyDue applies scheduled prices that became effective; it is run by the price scheduler.
+func (s *PriceService) ApplyDue(ctx context.Context) (int, error) {
+	return s.repo.ApplyDue(ctx, time.Now().UTC())
+}
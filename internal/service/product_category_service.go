@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"store-service/internal/model"
+	"store-service/internal/repository"
+	"store-service/internal/storage"
+)
+
+type ProductCategoryService struct {
+	repo  *repository.ProductCategoryRepository
+	media mediaLoader
+}
+
+func NewProductCategoryService(repo *repository.ProductCategoryRepository, mediaRepo *repository.MediaRepository, store storage.Storage) *ProductCategoryService {
+	return &ProductCategoryService{repo: repo, media: mediaLoader{repo: mediaRepo, store: store}}
+}
+
+// ReplaceCategories sets the product categories and returns the resulting assignment.
+func (s *ProductCategoryService) ReplaceCategories(ctx context.Context, productID uuid.UUID, categoryIDs []uuid.UUID) ([]model.Category, error) {
+	if err := s.repo.ReplaceForProduct(ctx, productID, categoryIDs); err != nil {
+		return nil, err
+	}
+	return s.repo.ListCategoriesByProduct(ctx, productID)
+}
+
+func (s *ProductCategoryService) ListCategories(ctx context.Context, productID uuid.UUID) ([]model.Category, error) {
+	return s.repo.ListCategoriesByProduct(ctx, productID)
+}
+
+func (s *ProductCategoryService) ListProducts(ctx context.Context, categoryID uuid.UUID, limit, offset int) ([]model.Product, error) {
+	products, err := s.repo.ListProductsByCategory(ctx, categoryID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return products, s.media.attach(ctx, productPointers(products)...)
+}
@@ -7,32 +7,135 @@ import (
 
 	"store-service/internal/model"
 	"store-service/internal/repository"
+	"store-service/internal/storage"
 )
 
 type ProductService struct {
-	repo *repository.ProductRepository
+	repo         *repository.ProductRepository
+	media        mediaLoader
+	baseCurrency model.Currency
 }
 
-func NewProductService(repo *repository.ProductRepository) *ProductService {
-	return &ProductService{repo: repo}
+func NewProductService(repo *repository.ProductRepository, mediaRepo *repository.MediaRepository, store storage.Storage, baseCurrency model.Currency) *ProductService {
+	return &ProductService{repo: repo, media: mediaLoader{repo: mediaRepo, store: store}, baseCurrency: baseCurrency}
 }
 
+// Create stores the product; the price defaults to the base currency and is rounded to its minor unit.
+// Without a slug one is generated from the name.
 func (s *ProductService) Create(ctx context.Context, p *model.Product) error {
+	if p.Currency == "" {
+		p.Currency = s.baseCurrency
+	}
+	if !p.Currency.Valid() {
+		return repository.ErrInvalidCurrency
+	}
+	p.Price = p.Currency.Round(p.Price)
+	slug, err := resolveSlug(p.Slug, p.Name, "product")
+	if err != nil {
+		return err
+	}
+	p.Slug = slug
 	return s.repo.Create(ctx, p)
 }
 
 func (s *ProductService) Get(ctx context.Context, id uuid.UUID) (model.Product, error) {
-	return s.repo.Get(ctx, id)
+	p, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return p, err
+	}
+	return p, s.media.attach(ctx, &p)
 }
 
+// GetBySlug returns the product by slug; moved is set when the slug is an old one and the
+// client should be redirected to the current slug.
+func (s *ProductService) GetBySlug(ctx context.Context, slug string) (model.Product, bool, error) {
+	p, moved, err := s.repo.GetBySlug(ctx, slug)
+	if err != nil {
+		return p, false, err
+	}
+	return p, moved, s.media.attach(ctx, &p)
+}
+
+// Update overwrites the product; an empty currency or slug keeps the current one.
 func (s *ProductService) Update(ctx context.Context, p *model.Product) error {
-	return s.repo.Update(ctx, p)
+	if p.Currency == "" {
+		current, err := s.repo.Get(ctx, p.ID)
+		if err != nil {
+			return err
+		}
+		p.Currency = current.Currency
+	}
+	if !p.Currency.Valid() {
+		return repository.ErrInvalidCurrency
+	}
+	p.Price = p.Currency.Round(p.Price)
+	if p.Slug != "" {
+		slug, err := resolveSlug(p.Slug, "", "")
+		if err != nil {
+			return err
+		}
+		p.Slug = slug
+	}
+	if err := s.repo.Update(ctx, p); err != nil {
+		return err
+	}
+	return s.media.attach(ctx, p)
+}
+
+// Archive replaces deletion: the product disappears from the catalog but stays in order history.
+func (s *ProductService) Archive(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Archive(ctx, id)
+}
+
+func (s *ProductService) Restore(ctx context.Context, id uuid.UUID) (model.Product, error) {
+	p, err := s.repo.Restore(ctx, id)
+	if err != nil {
+		return p, err
+	}
+	return p, s.media.attach(ctx, &p)
+}
+
+func (s *ProductService) List(ctx context.Context, f repository.ProductListFilter) ([]model.Product, repository.Page, error) {
+	f.BaseCurrency = s.baseCurrency
+	products, page, err := s.repo.List(ctx, f)
+	if err != nil {
+		return nil, page, err
+	}
+	return products, page, s.media.attach(ctx, productPointers(products)...)
+}
+
+// Export streams products matching the filter in batches, with their media.
+func (s *ProductService) Export(ctx context.Context, f repository.ProductListFilter, fn func([]model.Product) error) error {
+	f.BaseCurrency = s.baseCurrency
+	return s.repo.Export(ctx, f, func(batch []model.Product) error {
+		if err := s.media.attach(ctx, productPointers(batch)...); err != nil {
+			return err
+		}
+		return fn(batch)
+	})
+}
+
+func (s *ProductService) Search(ctx context.Context, f repository.ProductSearchFilter) ([]model.ProductSearchHit, error) {
+	hits, err := s.repo.Search(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	products := make([]*model.Product, 0, len(hits))
+	for i := range hits {
+		products = append(products, &hits[i].Product)
+	}
+	return hits, s.media.attach(ctx, products...)
 }
 
-func (s *ProductService) Delete(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+func (s *ProductService) Facets(ctx context.Context, f repository.ProductListFilter) ([]model.AttributeFacet, error) {
+	f.BaseCurrency = s.baseCurrency
+	return s.repo.Facets(ctx, f)
 }
 
-func (s *ProductService) List(ctx context.Context, limit, offset int) ([]model.Product, error) {
-	return s.repo.List(ctx, limit, offset)
+func productPointers(list []model.Product) []*model.Product {
+	result := make([]*model.Product, 0, len(list))
+	for i := range list {
+		result = append(result, &list[i])
+	}
+	return result
 }
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"store-service/internal/model"
+	"store-service/internal/repository"
+)
+
+type PurchaseOrderService struct {
+	repo         *repository.PurchaseOrderRepository
+	baseCurrency model.Currency
+}
+
+func NewPurchaseOrderService(repo *repository.PurchaseOrderRepository, baseCurrency model.Currency) *PurchaseOrderService {
+	return &PurchaseOrderService{repo: repo, baseCurrency: baseCurrency}
+}
+
+// Create places a purchase order; without a currency it is priced in the base currency.
+func (s *PurchaseOrderService) Create(ctx context.Context, po *model.PurchaseOrder) error {
+	if po.Currency == "" {
+		po.Currency = s.baseCurrency
+	}
+	if !po.Currency.Valid() {
+		return repository.ErrInvalidCurrency
+	}
+	if len(po.Lines) == 0 {
+		return repository.ErrInvalidPurchaseOrder
+	}
+	for _, l := range po.Lines {
+		if l.QuantityOrdered <= 0 || l.UnitCost.IsNegative() {
+			return repository.ErrInvalidPurchaseOrder
+		}
+	}
+	return s.repo.Create(ctx, po)
+}
+
+func (s *PurchaseOrderService) Get(ctx context.Context, id uuid.UUID) (model.PurchaseOrder, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *PurchaseOrderService) List(ctx context.Context, f repository.PurchaseOrderFilter, p repository.PageRequest) ([]model.PurchaseOrder, repository.Page, error) {
+	switch f.Status {
+	case "", model.PurchaseOrderOpen, model.PurchaseOrderPartiallyReceived, model.PurchaseOrderReceived, model.PurchaseOrderCancelled:
+	default:
+		return nil, repository.Page{}, repository.ErrInvalidPurchaseOrderStatus
+	}
+	return s.repo.List(ctx, f, p)
+}
+
+// Cancel closes the purchase order and returns it.
+func (s *PurchaseOrderService) Cancel(ctx context.Context, id uuid.UUID) (model.PurchaseOrder, error) {
+	if err := s.repo.Cancel(ctx, id); err != nil {
+		return model.PurchaseOrder{}, err
+	}
+	return s.repo.Get(ctx, id)
+}
+
+// Receive books a full or partial delivery against the purchase order.
+func (s *PurchaseOrderService) Receive(ctx context.Context, rc *model.PurchaseReceipt) error {
+	if len(rc.Lines) == 0 || rc.ExtraCost.IsNegative() {
+		return repository.ErrInvalidReceipt
+	}
+	for _, l := range rc.Lines {
+		if l.Quantity <= 0 {
+			return repository.ErrInvalidReceipt
+		}
+	}
+	return s.repo.Receive(ctx, rc)
+}
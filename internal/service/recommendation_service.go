@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"store-service/internal/model"
+	"store-service/internal/repository"
+	"store-service/internal/storage"
+)
+
+type RecommendationService struct {
+	repo      *repository.RecommendationRepository
+	media     mediaLoader
+	minOrders int
+}
+
+func NewRecommendationService(repo *repository.RecommendationRepository, mediaRepo *repository.MediaRepository, store storage.Storage, minOrders int) *RecommendationService {
+	return &RecommendationService{repo: repo, media: mediaLoader{repo: mediaRepo, store: store}, minOrders: minOrders}
+}
+
+// Recompute rebuilds co-purchase scores from order history; it is run by the recommendations worker.
+func (s *RecommendationService) Recompute(ctx context.Context) (int, error) {
+	return s.repo.Recompute(ctx, s.minOrders)
+}
+
+// Related returns up to limit products frequently bought together with the product.
+func (s *RecommendationService) Related(ctx context.Context, productID uuid.UUID, limit int) ([]model.ProductRecommendation, error) {
+	recs, err := s.repo.Related(ctx, productID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return recs, s.media.attach(ctx, recommendedProducts(recs)...)
+}
+
+// Suggestions returns up to limit products to add to the order.
+func (s *RecommendationService) Suggestions(ctx context.Context, orderID uuid.UUID, limit int) ([]model.ProductRecommendation, error) {
+	recs, err := s.repo.Suggestions(ctx, orderID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return recs, s.media.attach(ctx, recommendedProducts(recs)...)
+}
+
+func recommendedProducts(recs []model.ProductRecommendation) []*model.Product {
+	result := make([]*model.Product, 0, len(recs))
+	for i := range recs {
+		result = append(result, &recs[i].Product)
+	}
+	return result
+}
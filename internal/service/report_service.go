@@ -3,19 +3,35 @@ package service
 import (
 	"context"
 
+	"store-service/internal/model"
 	"store-service/internal/repository"
 )
 
 type ReportService struct {
-	repo *repository.ReportRepository
+	repo         *repository.ReportRepository
+	baseCurrency model.Currency
 }
 
-func NewReportService(repo *repository.ReportRepository) *ReportService {
-	return &ReportService{repo: repo}
+func NewReportService(repo *repository.ReportRepository, baseCurrency model.Currency) *ReportService {
+	return &ReportService{repo: repo, baseCurrency: baseCurrency}
 }
 
-func (s *ReportService) CustomerTotals(ctx context.Context) ([]repository.CustomerTotal, error) {
-	return s.repo.CustomerTotals(ctx)
+// CustomerTotals aggregates order amounts in the given currency, the base one when empty.
+func (s *ReportService) CustomerTotals(ctx context.Context, currency model.Currency) ([]repository.CustomerTotal, error) {
+	if currency == "" {
+		currency = s.baseCurrency
+	}
+	if !currency.Valid() {
+		return nil, repository.ErrInvalidCurrency
+	}
+	totals, err := s.repo.CustomerTotals(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	for i := range totals {
+		totals[i].TotalAmount = currency.Round(totals[i].TotalAmount)
+	}
+	return totals, nil
 }
 
 func (s *ReportService) CategoryChildren(ctx context.Context) ([]repository.CategoryChildrenCount, error) {
@@ -25,3 +41,8 @@ func (s *ReportService) CategoryChildren(ctx context.Context) ([]repository.Cate
 func (s *ReportService) TopProductsLastMonth(ctx context.Context) ([]repository.TopProduct, error) {
 	return s.repo.TopProductsLastMonth(ctx)
 }
+
+// LowStock lists products that reached their reorder point.
+func (s *ReportService) LowStock(ctx context.Context) ([]repository.LowStockProduct, error) {
+	return s.repo.LowStock(ctx)
+}
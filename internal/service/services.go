@@ -1,14 +1,33 @@
 package service
 
-import "store-service/internal/repository"
+import (
+	"store-service/internal/config"
+	"store-service/internal/model"
+	"store-service/internal/notify"
+	"store-service/internal/repository"
+	"store-service/internal/storage"
+)
 
 // Services aggregates all domain services for easier wiring.
 type Services struct {
-	Categories *CategoryService
-	Customers  *CustomerService
-	Products   *ProductService
-	Orders     *OrderService
-	Reports    *ReportService
+	Categories        *CategoryService
+	Customers         *CustomerService
+	Products          *ProductService
+	Orders            *OrderService
+	Reports           *ReportService
+	ProductCategories *ProductCategoryService
+	Variants          *VariantService
+	Attributes        *AttributeService
+	Media             *MediaService
+	Prices            *PriceService
+	Currencies        *CurrencyService
+	Imports           *ImportService
+	Recommendations   *RecommendationService
+	Stock             *StockService
+	Warehouses        *WarehouseService
+	Suppliers         *SupplierService
+	PurchaseOrders    *PurchaseOrderService
+	Stocktakes        *StocktakeService
 }
 
 func NewServices(
@@ -17,12 +36,46 @@ func NewServices(
 	productRepo *repository.ProductRepository,
 	orderRepo *repository.OrderRepository,
 	reportRepo *repository.ReportRepository,
+	productCategoryRepo *repository.ProductCategoryRepository,
+	variantRepo *repository.VariantRepository,
+	attributeRepo *repository.AttributeRepository,
+	mediaRepo *repository.MediaRepository,
+	store storage.Storage,
+	mediaCfg config.Media,
+	priceRepo *repository.PriceRepository,
+	exchangeRateRepo *repository.ExchangeRateRepository,
+	baseCurrency model.Currency,
+	importRepo *repository.ImportRepository,
+	importCfg config.Import,
+	recommendationRepo *repository.RecommendationRepository,
+	recommendationCfg config.Recommendations,
+	reservationRepo *repository.ReservationRepository,
+	ordersCfg config.Orders,
+	stockRepo *repository.StockRepository,
+	warehouseRepo *repository.WarehouseRepository,
+	notifier notify.Notifier,
+	supplierRepo *repository.SupplierRepository,
+	purchaseOrderRepo *repository.PurchaseOrderRepository,
+	stocktakeRepo *repository.StocktakeRepository,
 ) *Services {
 	return &Services{
-		Categories: NewCategoryService(categoryRepo),
-		Customers:  NewCustomerService(customerRepo),
-		Products:   NewProductService(productRepo),
-		Orders:     NewOrderService(orderRepo),
-		Reports:    NewReportService(reportRepo),
+		Categories:        NewCategoryService(categoryRepo),
+		Customers:         NewCustomerService(customerRepo),
+		Products:          NewProductService(productRepo, mediaRepo, store, baseCurrency),
+		Orders:            NewOrderService(orderRepo, reservationRepo, baseCurrency, ordersCfg.ReservationTTL, ordersCfg.AllocationStrategy),
+		Reports:           NewReportService(reportRepo, baseCurrency),
+		ProductCategories: NewProductCategoryService(productCategoryRepo, mediaRepo, store),
+		Variants:          NewVariantService(variantRepo),
+		Attributes:        NewAttributeService(attributeRepo, productRepo, mediaRepo, store),
+		Media:             NewMediaService(mediaRepo, productRepo, store, mediaCfg.ThumbnailSizes, mediaCfg.MaxUploadSize, mediaCfg.MaxPixels),
+		Prices:            NewPriceService(priceRepo, productRepo),
+		Currencies:        NewCurrencyService(exchangeRateRepo, baseCurrency),
+		Imports:           NewImportService(importRepo, attributeRepo, baseCurrency, importCfg.SyncMaxRows, importCfg.MaxFileSize),
+		Recommendations:   NewRecommendationService(recommendationRepo, mediaRepo, store, recommendationCfg.MinOrders),
+		Stock:             NewStockService(stockRepo, notifier),
+		Warehouses:        NewWarehouseService(warehouseRepo),
+		Suppliers:         NewSupplierService(supplierRepo),
+		PurchaseOrders:    NewPurchaseOrderService(purchaseOrderRepo, baseCurrency),
+		Stocktakes:        NewStocktakeService(stocktakeRepo),
 	}
 }
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"strings"
+
+	"store-service/internal/repository"
+)
+
+// maxSlugLength keeps generated slugs readable in URLs; suffixes for collisions are added on top.
+const maxSlugLength = 80
+
+// translit maps Cyrillic letters (Russian, Belarusian and Kazakh) to Latin.
+var translit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'і': "i", 'ў': "u",
+	'ә': "a", 'ғ': "g", 'қ': "k", 'ң': "n", 'ө': "o", 'ұ': "u", 'ү': "u", 'һ': "h",
+}
+
+// slugify lowercases s, transliterates Cyrillic and joins the remaining runs of latin letters
+// and digits with single hyphens. The result is empty when s has no letters or digits.
+func slugify(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch t, ok := translit[r]; {
+		case ok:
+			b.WriteString(t)
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+	}
+	slug := strings.Join(strings.FieldsFunc(b.String(), func(r rune) bool { return r == '-' }), "-")
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	return slug
+}
+
+// resolveSlug normalizes an explicit slug or, when it is empty, derives one from the name.
+// fallback is used for names without letters or digits.
+func resolveSlug(explicit, name, fallback string) (string, error) {
+	if strings.TrimSpace(explicit) != "" {
+		slug := slugify(explicit)
+		if slug == "" {
+			return "", repository.ErrInvalidSlug
+		}
+		return slug, nil
+	}
+	if slug := slugify(name); slug != "" {
+		return slug, nil
+	}
+	return fallback, nil
+}
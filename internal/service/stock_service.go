@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"store-service/internal/model"
+	"store-service/internal/notify"
+	"store-service/internal/repository"
+)
+
+// alertBatchSize is how many low-stock alerts one dispatch run delivers at most.
+const alertBatchSize = 100
+
+type StockService struct {
+	repo     *repository.StockRepository
+	notifier notify.Notifier
+}
+
+func NewStockService(repo *repository.StockRepository, notifier notify.Notifier) *StockService {
+	return &StockService{repo: repo, notifier: notifier}
+}
+
+// Movements returns the stock ledger of the product, optionally of one warehouse, variant or reason.
+func (s *StockService) Movements(ctx context.Context, productID uuid.UUID, f repository.StockMovementFilter, p repository.PageRequest) ([]model.StockMovement, repository.Page, error) {
+	switch f.Reason {
+	case "", model.StockSale, model.StockReturn, model.StockAdjustment, model.StockReceipt, model.StockTransfer:
+	default:
+		return nil, repository.Page{}, repository.ErrInvalidStockReason
+	}
+	return s.repo.Movements(ctx, productID, f, p)
+}
+
+// Discrepancies lists products and variants whose cached quantity disagrees with the ledger.
+func (s *StockService) Discrepancies(ctx context.Context) ([]model.StockDiscrepancy, error) {
+	return s.repo.Discrepancies(ctx)
+}
+
+// Reconcile sets cached quantities to the ledger sums, the ledger being the source of truth.
+func (s *StockService) Reconcile(ctx context.Context) ([]model.StockDiscrepancy, error) {
+	return s.repo.Reconcile(ctx)
+}
+
+func (s *StockService) ReorderPoint(ctx context.Context, productID uuid.UUID) (model.ReorderPoint, error) {
+	return s.repo.ReorderPoint(ctx, productID)
+}
+
+// SetProductReorderPoint sets the own reorder point of the product and returns the resulting points.
+func (s *StockService) SetProductReorderPoint(ctx context.Context, productID uuid.UUID, point *int) (model.ReorderPoint, error) {
+	if point != nil && *point < 0 {
+		return model.ReorderPoint{}, repository.ErrInvalidReorderPoint
+	}
+	if err := s.repo.SetProductReorderPoint(ctx, productID, point); err != nil {
+		return model.ReorderPoint{}, err
+	}
+	return s.repo.ReorderPoint(ctx, productID)
+}
+
+// SetCategoryReorderPoint sets the default reorder point of the category.
+func (s *StockService) SetCategoryReorderPoint(ctx context.Context, categoryID uuid.UUID, point *int) error {
+	if point != nil && *point < 0 {
+		return repository.ErrInvalidReorderPoint
+	}
+	return s.repo.SetCategoryReorderPoint(ctx, categoryID, point)
+}
+
+// DispatchAlerts delivers queued low-stock alerts to the notifier; it is run by the stock alert job.
+func (s *StockService) DispatchAlerts(ctx context.Context) (int, error) {
+	return s.repo.DispatchAlerts(ctx, alertBatchSize, func(a model.StockAlert) error {
+		return s.notifier.Notify(ctx, notify.Event{ID: a.ID, Type: model.StockLowEvent, OccurredAt: a.CreatedAt, Data: a})
+	})
+}
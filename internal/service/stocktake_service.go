@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"store-service/internal/model"
+	"store-service/internal/repository"
+)
+
+type StocktakeService struct {
+	repo *repository.StocktakeRepository
+}
+
+func NewStocktakeService(repo *repository.StocktakeRepository) *StocktakeService {
+	return &StocktakeService{repo: repo}
+}
+
+// Create opens a stocktake of the warehouse; without one the default warehouse is counted.
+func (s *StocktakeService) Create(ctx context.Context, st *model.Stocktake) error {
+	return s.repo.Create(ctx, st)
+}
+
+func (s *StocktakeService) Get(ctx context.Context, id uuid.UUID) (model.Stocktake, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *StocktakeService) List(ctx context.Context, f repository.StocktakeFilter, p repository.PageRequest) ([]model.Stocktake, repository.Page, error) {
+	switch f.Status {
+	case "", model.StocktakeOpen, model.StocktakeCommitted, model.StocktakeCancelled:
+	default:
+		return nil, repository.Page{}, repository.ErrInvalidStocktakeStatus
+	}
+	return s.repo.List(ctx, f, p)
+}
+
+// Count records a batch of counted quantities and returns the updated stocktake.
+func (s *StocktakeService) Count(ctx context.Context, id uuid.UUID, counts []model.StocktakeCount) (model.Stocktake, error) {
+	if len(counts) == 0 {
+		return model.Stocktake{}, repository.ErrInvalidStocktakeCount
+	}
+	for _, c := range counts {
+		if c.Quantity < 0 {
+			return model.Stocktake{}, repository.ErrInvalidStocktakeCount
+		}
+	}
+	if err := s.repo.Count(ctx, id, counts); err != nil {
+		return model.Stocktake{}, err
+	}
+	return s.repo.Get(ctx, id)
+}
+
+// Discrepancies previews what committing the stocktake changes, or shows what it changed.
+func (s *StocktakeService) Discrepancies(ctx context.Context, id uuid.UUID) ([]model.StocktakeDiscrepancy, error) {
+	return s.repo.Discrepancies(ctx, id)
+}
+
+// Commit applies the counts to stock and returns the closed stocktake.
+func (s *StocktakeService) Commit(ctx context.Context, id uuid.UUID) (model.Stocktake, error) {
+	if err := s.repo.Commit(ctx, id); err != nil {
+		return model.Stocktake{}, err
+	}
+	return s.repo.Get(ctx, id)
+}
+
+// Cancel closes the stocktake without changing stock and returns it.
+func (s *StocktakeService) Cancel(ctx context.Context, id uuid.UUID) (model.Stocktake, error) {
+	if err := s.repo.Cancel(ctx, id); err != nil {
+		return model.Stocktake{}, err
+	}
+	return s.repo.Get(ctx, id)
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"store-service/internal/model"
+	"store-service/internal/repository"
+)
+
+type SupplierService struct {
+	repo *repository.SupplierRepository
+}
+
+func NewSupplierService(repo *repository.SupplierRepository) *SupplierService {
+	return &SupplierService{repo: repo}
+}
+
+func (s *SupplierService) Create(ctx context.Context, sp *model.Supplier) error {
+	if strings.TrimSpace(sp.Name) == "" {
+		return repository.ErrInvalidSupplier
+	}
+	return s.repo.Create(ctx, sp)
+}
+
+func (s *SupplierService) Get(ctx context.Context, id uuid.UUID) (model.Supplier, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *SupplierService) Update(ctx context.Context, sp *model.Supplier) error {
+	if strings.TrimSpace(sp.Name) == "" {
+		return repository.ErrInvalidSupplier
+	}
+	return s.repo.Update(ctx, sp)
+}
+
+func (s *SupplierService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *SupplierService) List(ctx context.Context, p repository.PageRequest) ([]model.Supplier, repository.Page, error) {
+	return s.repo.List(ctx, p)
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"store-service/internal/model"
+	"store-service/internal/repository"
+)
+
+type VariantService struct {
+	repo *repository.VariantRepository
+}
+
+func NewVariantService(repo *repository.VariantRepository) *VariantService {
+	return &VariantService{repo: repo}
+}
+
+// ReplaceOptions sets option definitions of the product. Existing variants must still match them;
+// they are checked by the repository after the product is locked.
+func (s *VariantService) ReplaceOptions(ctx context.Context, productID uuid.UUID, options []model.ProductOption) ([]model.ProductOption, error) {
+	for _, o := range options {
+		if o.Name == "" || len(o.Values) == 0 || hasDuplicates(o.Values) {
+			return nil, repository.ErrInvalidVariantOptions
+		}
+	}
+
+	err := s.repo.ReplaceOptions(ctx, productID, options, func(values map[string]string) error {
+		if validateVariantOptions(options, values) != nil {
+			return repository.ErrOptionsInUse
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+func (s *VariantService) ListOptions(ctx context.Context, productID uuid.UUID) ([]model.ProductOption, error) {
+	return s.repo.ListOptions(ctx, productID)
+}
+
+func (s *VariantService) Create(ctx context.Context, v *model.ProductVariant) error {
+	return s.repo.Create(ctx, v, s.validate(v))
+}
+
+func (s *VariantService) Get(ctx context.Context, productID, id uuid.UUID) (model.ProductVariant, error) {
+	return s.repo.Get(ctx, productID, id)
+}
+
+func (s *VariantService) Update(ctx context.Context, v *model.ProductVariant) error {
+	return s.repo.Update(ctx, v, s.validate(v))
+}
+
+func (s *VariantService) Delete(ctx context.Context, productID, id uuid.UUID) error {
+	return s.repo.Delete(ctx, productID, id)
+}
+
+func (s *VariantService) List(ctx context.Context, productID uuid.UUID) ([]model.ProductVariant, error) {
+	return s.repo.List(ctx, productID)
+}
+
+// validate returns the check run by the repository under the product lock: it rounds the price
+// override to the product currency and validates the variant options.
+func (s *VariantService) validate(v *model.ProductVariant) func(model.Currency, []model.ProductOption) error {
+	if v.Options == nil {
+		v.Options = map[string]string{}
+	}
+	return func(currency model.Currency, defs []model.ProductOption) error {
+		if v.Price != nil {
+			price := currency.Round(*v.Price)
+			v.Price = &price
+		}
+		return validateVariantOptions(defs, v.Options)
+	}
+}
+
+// validateVariantOptions requires a value for every defined option and nothing else.
+func validateVariantOptions(defs []model.ProductOption, values map[string]string) error {
+	if len(values) != len(defs) {
+		return repository.ErrInvalidVariantOptions
+	}
+	for _, d := range defs {
+		v, ok := values[d.Name]
+		if !ok || !slices.Contains(d.Values, v) {
+			return repository.ErrInvalidVariantOptions
+		}
+	}
+	return nil
+}
+
+func hasDuplicates(values []string) bool {
+	seen := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		if _, ok := seen[v]; ok {
+			return true
+		}
+		seen[v] = struct{}{}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"store-service/internal/model"
+	"store-service/internal/repository"
+)
+
+type WarehouseService struct {
+	repo *repository.WarehouseRepository
+}
+
+func NewWarehouseService(repo *repository.WarehouseRepository) *WarehouseService {
+	return &WarehouseService{repo: repo}
+}
+
+func (s *WarehouseService) Create(ctx context.Context, w *model.Warehouse) error {
+	if !validWarehouse(w) {
+		return repository.ErrInvalidWarehouse
+	}
+	return s.repo.Create(ctx, w)
+}
+
+func (s *WarehouseService) Get(ctx context.Context, id uuid.UUID) (model.Warehouse, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *WarehouseService) List(ctx context.Context) ([]model.Warehouse, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *WarehouseService) Update(ctx context.Context, w *model.Warehouse) error {
+	if !validWarehouse(w) {
+		return repository.ErrInvalidWarehouse
+	}
+	return s.repo.Update(ctx, w)
+}
+
+func (s *WarehouseService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// Stock returns stock levels of the warehouse.
+func (s *WarehouseService) Stock(ctx context.Context, warehouseID uuid.UUID) ([]model.WarehouseStock, error) {
+	return s.repo.Stock(ctx, repository.WarehouseStockFilter{WarehouseID: &warehouseID})
+}
+
+// ProductStock returns stock levels of the product in every warehouse that has held it.
+func (s *WarehouseService) ProductStock(ctx context.Context, productID uuid.UUID) ([]model.WarehouseStock, error) {
+	return s.repo.Stock(ctx, repository.WarehouseStockFilter{ProductID: &productID})
+}
+
+// SetStock sets the counted stock level of a product in the warehouse.
+func (s *WarehouseService) SetStock(ctx context.Context, st *model.WarehouseStock) error {
+	return s.repo.SetStock(ctx, st)
+}
+
+// CreateTransfer moves stock between two warehouses.
+func (s *WarehouseService) CreateTransfer(ctx context.Context, t *model.WarehouseTransfer) error {
+	if t.FromWarehouseID == t.ToWarehouseID || len(t.Lines) == 0 {
+		return repository.ErrInvalidTransfer
+	}
+	for _, l := range t.Lines {
+		if l.Quantity <= 0 {
+			return repository.ErrInvalidTransfer
+		}
+	}
+	return s.repo.CreateTransfer(ctx, t)
+}
+
+func (s *WarehouseService) GetTransfer(ctx context.Context, id uuid.UUID) (model.WarehouseTransfer, error) {
+	return s.repo.GetTransfer(ctx, id)
+}
+
+func (s *WarehouseService) ListTransfers(ctx context.Context, warehouseID *uuid.UUID, p repository.PageRequest) ([]model.WarehouseTransfer, repository.Page, error) {
+	return s.repo.ListTransfers(ctx, warehouseID, p)
+}
+
+func validWarehouse(w *model.Warehouse) bool {
+	return w.Code != "" && w.Name != "" && model.ValidCoordinates(w.Latitude, w.Longitude)
+}
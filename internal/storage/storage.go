@@ -0,0 +1,15 @@
+package storage
+
+import (
+	"context"
+	"io"
+)
+
+// Storage keeps binary objects such as product media under slash-separated keys.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	// Delete removes the object; a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	// URL returns the public address of the object.
+	URL(key string) string
+}
@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"store-service/internal/actor"
+)
+
+// Job is a unit of periodic background work.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Runner runs jobs on their intervals until the context passed to Start is cancelled.
+type Runner struct {
+	log  *zap.Logger
+	jobs []Job
+	wg   sync.WaitGroup
+}
+
+func New(log *zap.Logger) *Runner {
+	return &Runner{log: log}
+}
+
+// Add registers a job; jobs with a non-positive interval are disabled.
+func (r *Runner) Add(job Job) {
+	if job.Interval <= 0 {
+		r.log.Info("background job disabled", zap.String("job", job.Name))
+		return
+	}
+	r.jobs = append(r.jobs, job)
+}
+
+func (r *Runner) Start(ctx context.Context) {
+	for _, job := range r.jobs {
+		r.wg.Add(1)
+		go func(job Job) {
+			defer r.wg.Done()
+			r.loop(ctx, job)
+		}(job)
+	}
+}
+
+// Wait blocks until all jobs have returned after cancellation.
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+func (r *Runner) loop(ctx context.Context, job Job) {
+	ctx = actor.WithContext(ctx, "worker:"+job.Name)
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job.Run(ctx); err != nil && ctx.Err() == nil {
+			r.log.Error("background job failed", zap.String("job", job.Name), zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}